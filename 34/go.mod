@@ -1,7 +1,7 @@
-module main
+module github.com/ysmolsky/cryptopals/ch34
 
 go 1.18
 
-replace tools => ../tools
+replace github.com/ysmolsky/cryptopals/tools => ../tools
 
-require tools v1.0.0
+require github.com/ysmolsky/cryptopals/tools v0.0.0-00010101000000-000000000000
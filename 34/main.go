@@ -2,73 +2,165 @@ package main
 
 import (
 	"crypto/aes"
+	"crypto/sha1"
 	"fmt"
 	"log"
 	"math/big"
-	"tools"
-)
 
-func main() {
-	p, ok := new(big.Int).SetString(`ffffffffffffffffc90fdaa22168c234c4c6628b80dc1cd129024e088a67cc74020bbea63b139b22514a08798e3404ddef9519b3cd3a431b302b0a6df25f14374fe1356d6d51c245e485b576625e7ec6f44c42e9a637ed6b0bff5cb6f406b7edee386bfb5a899fa5ae9f24117c4b1fe649286651ece45b3dc2007cb8a163bf0598da48361c55d39a69163fa8fd24cf5f83655d23dca3ad961c62f356208552bb9ed529077096966d670c354e4abc9804f1746c08ca237327ffffffffffffffff`, 16)
-	if !ok {
-		panic("cannot load p")
-	}
-	g := big.NewInt(2)
-
-	// protocol init DHKE
-	publicA := new(big.Int)
-	privateA := new(big.Int)
-	tools.DHKEGenKeys(publicA, privateA, p, g)
-
-	fmt.Println("A -> M (p, g, publicA)")
-	fmt.Println("M -> B (p, g, p)")
-	forgedPublicA := p
+	"github.com/ysmolsky/cryptopals/tools"
+	"github.com/ysmolsky/cryptopals/tools/wire"
+)
 
-	publicB := new(big.Int)
-	privateB := new(big.Int)
-	tools.DHKEGenKeys(publicB, privateB, p, g)
-	fmt.Println("B -> M (publicB)")
-	fmt.Println("M -> A (p instead of B)")
-	forgedPublicB := p
+// aesKey is SHA1(s)[0:16] for the shared secret s.
+func aesKey(s *big.Int) []byte {
+	h := sha1.Sum(s.Bytes())
+	return h[:16]
+}
 
-	// generated on A side
-	sA := tools.DHKESessionKey(forgedPublicB, privateA, p)
-	fmt.Printf("sA = %x\n", sA)
+func encrypt(s *big.Int, msg []byte) *wire.Ciphertext {
+	block, err := aes.NewCipher(aesKey(s))
+	if err != nil {
+		log.Fatal(err)
+	}
+	iv := tools.RandBytes(block.BlockSize())
+	data := tools.PadPKCS7(msg, block.BlockSize())
+	ct := make([]byte, len(data))
+	tools.CBCEncrypt(block, iv, ct, data)
+	return &wire.Ciphertext{IV: iv, Data: ct}
+}
 
-	// generated on B side
-	sB := tools.DHKESessionKey(forgedPublicA, privateB, p)
-	fmt.Printf("sB = %x\n", sB)
+func decrypt(s *big.Int, m *wire.Ciphertext) ([]byte, error) {
+	block, err := aes.NewCipher(aesKey(s))
+	if err != nil {
+		return nil, err
+	}
+	padded := make([]byte, len(m.Data))
+	if err := tools.CBCDecryptChecked(block, m.IV, padded, m.Data); err != nil {
+		return nil, err
+	}
+	return tools.UnpadPKCS7(padded)
+}
 
-	// generated on M side. Since s is zero, no need to know private a or
-	// b key.
-	sM := tools.DHKESessionKey(forgedPublicA, new(big.Int).SetInt64(13), p)
-	fmt.Printf("sM = %x\n", sM)
+// A sends (p, g) and publicA, receives publicB, sends the encrypted message
+// and returns the echo of B.
+func A(c *wire.Conn, p, g *big.Int, msg []byte) ([]byte, error) {
+	if err := c.Send(&wire.Negotiate{P: p, G: g}); err != nil {
+		return nil, err
+	}
+	if _, err := wire.Receive[*wire.Ack](c); err != nil {
+		return nil, err
+	}
+	publicA, privateA := new(big.Int), new(big.Int)
+	tools.DHKEGenKeys(publicA, privateA, p, g)
+	if err := c.Send(&wire.PublicKey{Key: publicA}); err != nil {
+		return nil, err
+	}
+	publicB, err := wire.Receive[*wire.PublicKey](c)
+	if err != nil {
+		return nil, err
+	}
+	sA := new(big.Int).Exp(publicB.Key, privateA, p)
+	if err := c.Send(encrypt(sA, msg)); err != nil {
+		return nil, err
+	}
+	echo, err := wire.Receive[*wire.Ciphertext](c)
+	if err != nil {
+		return nil, err
+	}
+	return decrypt(sA, echo)
+}
 
-	block, err := aes.NewCipher(sA[:16])
+// B answers with publicB and echoes the message of A.
+func B(c *wire.Conn) error {
+	neg, err := wire.Receive[*wire.Negotiate](c)
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+	if err := c.Send(&wire.Ack{}); err != nil {
+		return err
 	}
-	ivA := tools.RandBytes(block.BlockSize())
-	msgA := []byte("This message is from A to B")
-	dataA := tools.PadPKCS7(msgA, block.BlockSize())
-	ct := make([]byte, len(dataA))
-	tools.CBCEncrypt(block, ivA, ct, dataA)
+	publicA, err := wire.Receive[*wire.PublicKey](c)
+	if err != nil {
+		return err
+	}
+	publicB, privateB := new(big.Int), new(big.Int)
+	tools.DHKEGenKeys(publicB, privateB, neg.P, neg.G)
+	if err := c.Send(&wire.PublicKey{Key: publicB}); err != nil {
+		return err
+	}
+	sB := new(big.Int).Exp(publicA.Key, privateB, neg.P)
+	ct, err := wire.Receive[*wire.Ciphertext](c)
+	if err != nil {
+		return err
+	}
+	msg, err := decrypt(sB, ct)
+	if err != nil {
+		return err
+	}
+	return c.Send(encrypt(sB, msg))
+}
 
-	// here we prove that M can decrypt pt using sM.
-	recovered := MITM(sM, ivA, ct)
-	fmt.Printf("M intercepted this message: %#v\n", string(recovered))
+// MITM relays everything between A and B, but replaces both public keys
+// with p. Since s = p**x % p is zero on both sides, M does not need to know
+// private a or b key to decrypt the messages.
+func MITM(a, b *wire.Conn) error {
+	var p *big.Int
+	// A: Negotiate, B: Ack, A: PublicKey, B: PublicKey, A and B: Ciphertext
+	for i, from := range []*wire.Conn{a, b, a, b, a, b} {
+		to := b
+		if i%2 == 1 {
+			to = a
+		}
+		m, err := from.Recv()
+		if err != nil {
+			return err
+		}
+		switch m := m.(type) {
+		case *wire.Negotiate:
+			p = m.P
+		case *wire.PublicKey:
+			fmt.Printf("M -> %s (p instead of public key)\n", to.Name)
+			m.Key = p
+		case *wire.Ciphertext:
+			pt, err := decrypt(new(big.Int), m)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("M intercepted this message: %#v\n", string(pt))
+		}
+		if err := to.Send(m); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func MITM(sM, iv, ct []byte) []byte {
-	block, err := aes.NewCipher(sM[:16])
-	if err != nil {
-		log.Fatal(err)
+func main() {
+	p, ok := new(big.Int).SetString(`ffffffffffffffffc90fdaa22168c234c4c6628b80dc1cd129024e088a67cc74020bbea63b139b22514a08798e3404ddef9519b3cd3a431b302b0a6df25f14374fe1356d6d51c245e485b576625e7ec6f44c42e9a637ed6b0bff5cb6f406b7edee386bfb5a899fa5ae9f24117c4b1fe649286651ece45b3dc2007cb8a163bf0598da48361c55d39a69163fa8fd24cf5f83655d23dca3ad961c62f356208552bb9ed529077096966d670c354e4abc9804f1746c08ca237327ffffffffffffffff`, 16)
+	if !ok {
+		panic("cannot load p")
 	}
-	padded := make([]byte, len(ct))
-	tools.CBCDecrypt(block, iv, padded, ct)
-	pt, err := tools.UnpadPKCS7(padded)
+	g := big.NewInt(2)
+
+	// A <-> M <-> B over in-memory connections
+	a, ma := wire.Pipe()
+	mb, b := wire.Pipe()
+	ma.Name, mb.Name = "A", "B"
+	go func() {
+		if err := B(b); err != nil {
+			log.Fatal(err)
+		}
+	}()
+	go func() {
+		if err := MITM(ma, mb); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	msg := []byte("This message is from A to B")
+	echo, err := A(a, p, g, msg)
 	if err != nil {
 		log.Fatal(err)
 	}
-	return pt
+	fmt.Printf("A got the echo: %#v\n", string(echo))
 }
@@ -0,0 +1,7 @@
+module github.com/ysmolsky/cryptopals/ch35
+
+go 1.18
+
+replace github.com/ysmolsky/cryptopals/tools => ../tools
+
+require github.com/ysmolsky/cryptopals/tools v0.0.0-00010101000000-000000000000
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/sha1"
+	"fmt"
+	"log"
+	"math/big"
+
+	"github.com/ysmolsky/cryptopals/tools"
+	"github.com/ysmolsky/cryptopals/tools/wire"
+)
+
+// aesKey is SHA1(s)[0:16] for the shared secret s.
+func aesKey(s *big.Int) []byte {
+	h := sha1.Sum(s.Bytes())
+	return h[:16]
+}
+
+func encrypt(s *big.Int, msg []byte) *wire.Ciphertext {
+	block, err := aes.NewCipher(aesKey(s))
+	if err != nil {
+		log.Fatal(err)
+	}
+	iv := tools.RandBytes(block.BlockSize())
+	data := tools.PadPKCS7(msg, block.BlockSize())
+	ct := make([]byte, len(data))
+	tools.CBCEncrypt(block, iv, ct, data)
+	return &wire.Ciphertext{IV: iv, Data: ct}
+}
+
+func decrypt(s *big.Int, m *wire.Ciphertext) ([]byte, error) {
+	block, err := aes.NewCipher(aesKey(s))
+	if err != nil {
+		return nil, err
+	}
+	padded := make([]byte, len(m.Data))
+	if err := tools.CBCDecryptChecked(block, m.IV, padded, m.Data); err != nil {
+		return nil, err
+	}
+	return tools.UnpadPKCS7(padded)
+}
+
+// A sends (p, g) and publicA, receives publicB, sends the encrypted message
+// and returns the echo of B.
+func A(c *wire.Conn, p, g *big.Int, msg []byte) ([]byte, error) {
+	if err := c.Send(&wire.Negotiate{P: p, G: g}); err != nil {
+		return nil, err
+	}
+	if _, err := wire.Receive[*wire.Ack](c); err != nil {
+		return nil, err
+	}
+	publicA, privateA := new(big.Int), new(big.Int)
+	tools.DHKEGenKeys(publicA, privateA, p, g)
+	if err := c.Send(&wire.PublicKey{Key: publicA}); err != nil {
+		return nil, err
+	}
+	publicB, err := wire.Receive[*wire.PublicKey](c)
+	if err != nil {
+		return nil, err
+	}
+	sA := new(big.Int).Exp(publicB.Key, privateA, p)
+	if err := c.Send(encrypt(sA, msg)); err != nil {
+		return nil, err
+	}
+	echo, err := wire.Receive[*wire.Ciphertext](c)
+	if err != nil {
+		return nil, err
+	}
+	return decrypt(sA, echo)
+}
+
+// B answers with publicB and echoes the message of A.
+func B(c *wire.Conn) error {
+	neg, err := wire.Receive[*wire.Negotiate](c)
+	if err != nil {
+		return err
+	}
+	if err := c.Send(&wire.Ack{}); err != nil {
+		return err
+	}
+	publicA, err := wire.Receive[*wire.PublicKey](c)
+	if err != nil {
+		return err
+	}
+	publicB, privateB := new(big.Int), new(big.Int)
+	tools.DHKEGenKeys(publicB, privateB, neg.P, neg.G)
+	if err := c.Send(&wire.PublicKey{Key: publicB}); err != nil {
+		return err
+	}
+	sB := new(big.Int).Exp(publicA.Key, privateB, neg.P)
+	ct, err := wire.Receive[*wire.Ciphertext](c)
+	if err != nil {
+		return err
+	}
+	msg, err := decrypt(sB, ct)
+	if err != nil {
+		return err
+	}
+	return c.Send(encrypt(sB, msg))
+}
+
+// MITM relays everything between A and B, but sends B the group with a
+// forged g and replaces the public keys with key, so that both sides
+// compute s = key**x % p without M knowing private a or b key. See
+// solution.txt for each g.
+func MITM(a, b *wire.Conn, g func(p *big.Int) *big.Int, key int64) error {
+	var p *big.Int
+	// A: Negotiate, B: Ack, A: PublicKey, B: PublicKey, A and B: Ciphertext
+	for i, from := range []*wire.Conn{a, b, a, b, a, b} {
+		to := b
+		if i%2 == 1 {
+			to = a
+		}
+		m, err := from.Recv()
+		if err != nil {
+			return err
+		}
+		switch m := m.(type) {
+		case *wire.Negotiate:
+			p = m.P
+			m.G = g(p)
+			fmt.Println("M -> B (p, forged g)")
+		case *wire.PublicKey:
+			fmt.Printf("M -> %s (%d instead of public key)\n", to.Name, key)
+			m.Key = big.NewInt(key)
+		case *wire.Ciphertext:
+			pt, err := decrypt(big.NewInt(key), m)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("M intercepted this message: %#v\n", string(pt))
+		}
+		if err := to.Send(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func main() {
+	p, ok := new(big.Int).SetString(`ffffffffffffffffc90fdaa22168c234c4c6628b80dc1cd129024e088a67cc74020bbea63b139b22514a08798e3404ddef9519b3cd3a431b302b0a6df25f14374fe1356d6d51c245e485b576625e7ec6f44c42e9a637ed6b0bff5cb6f406b7edee386bfb5a899fa5ae9f24117c4b1fe649286651ece45b3dc2007cb8a163bf0598da48361c55d39a69163fa8fd24cf5f83655d23dca3ad961c62f356208552bb9ed529077096966d670c354e4abc9804f1746c08ca237327ffffffffffffffff`, 16)
+	if !ok {
+		panic("cannot load p")
+	}
+	g := big.NewInt(2)
+
+	cases := []struct {
+		name string
+		g    func(p *big.Int) *big.Int
+		// B's public key with the forged g is 1, 0 and 1 or p-1, and A's
+		// is replaced with the same
+		key int64
+	}{
+		{"g = 1", func(*big.Int) *big.Int { return big.NewInt(1) }, 1},
+		{"g = p", func(p *big.Int) *big.Int { return p }, 0},
+		{"g = p-1", func(p *big.Int) *big.Int { return new(big.Int).Sub(p, big.NewInt(1)) }, 1},
+	}
+	for _, tc := range cases {
+		fmt.Println(tc.name)
+		// A <-> M <-> B over in-memory connections
+		a, ma := wire.Pipe()
+		mb, b := wire.Pipe()
+		ma.Name, mb.Name = "A", "B"
+		go func() {
+			if err := B(b); err != nil {
+				log.Fatal(err)
+			}
+		}()
+		done := make(chan error, 1)
+		go func() {
+			done <- MITM(ma, mb, tc.g, tc.key)
+		}()
+
+		msg := []byte("This message is from A to B")
+		echo, err := A(a, p, g, msg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := <-done; err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("A got the echo: %#v\n", string(echo))
+		for _, c := range []*wire.Conn{a, ma, mb, b} {
+			c.Close()
+		}
+	}
+}
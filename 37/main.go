@@ -1,103 +1,41 @@
 package main
 
 import (
-	"bufio"
-	"crypto/hmac"
-	"crypto/rand"
-	"crypto/sha256"
-	"encoding/hex"
 	"fmt"
 	"log"
 	"math/big"
 	"net"
 	"os"
-	"strings"
-	"tools"
-)
 
-var N = new(big.Int)
-var G = big.NewInt(2)
-var K = big.NewInt(3)
+	"github.com/ysmolsky/cryptopals/tools/srp"
+	"github.com/ysmolsky/cryptopals/tools/wire"
+)
 
 const (
 	EndPoint = "127.0.0.1:9999"
 )
 
+// params are the NIST prime with g = 2. The server of the challenge does
+// not check A, hence Insecure.
+var params = &srp.Params{Insecure: true}
+
 func init() {
-	var ok bool
-	N, ok = new(big.Int).SetString(`ffffffffffffffffc90fdaa22168c234c4c6628b80dc1cd129024e088a67cc74020bbea63b139b22514a08798e3404ddef9519b3cd3a431b302b0a6df25f14374fe1356d6d51c245e485b576625e7ec6f44c42e9a637ed6b0bff5cb6f406b7edee386bfb5a899fa5ae9f24117c4b1fe649286651ece45b3dc2007cb8a163bf0598da48361c55d39a69163fa8fd24cf5f83655d23dca3ad961c62f356208552bb9ed529077096966d670c354e4abc9804f1746c08ca237327ffffffffffffffff`, 16)
+	N, ok := new(big.Int).SetString(`ffffffffffffffffc90fdaa22168c234c4c6628b80dc1cd129024e088a67cc74020bbea63b139b22514a08798e3404ddef9519b3cd3a431b302b0a6df25f14374fe1356d6d51c245e485b576625e7ec6f44c42e9a637ed6b0bff5cb6f406b7edee386bfb5a899fa5ae9f24117c4b1fe649286651ece45b3dc2007cb8a163bf0598da48361c55d39a69163fa8fd24cf5f83655d23dca3ad961c62f356208552bb9ed529077096966d670c354e4abc9804f1746c08ca237327ffffffffffffffff`, 16)
 	if !ok {
 		panic("cannot load p")
 	}
-}
-
-type Server struct {
-	N, G, K *big.Int
-	// email to UserRec
-	users map[string]UserRec
-}
-
-type UserRec struct {
-	salt, v *big.Int
-}
-
-func SaltedPass(salt *big.Int, password string) *big.Int {
-	hash := sha256.New()
-	hash.Write(salt.Bytes())
-	hash.Write([]byte(password))
-	hex := hash.Sum(nil)
-	x := new(big.Int).SetBytes(hex)
-	return x
-}
-
-func NewServer(n, g, k *big.Int) *Server {
-	return &Server{N: n, G: g, K: k, users: make(map[string]UserRec)}
-}
-
-func (s *Server) AddUser(email, password string) {
-	salt, err := rand.Int(rand.Reader, s.N)
-	if err != nil {
-		log.Fatal(err)
-	}
-	x := SaltedPass(salt, password)
-	v := new(big.Int)
-	v.Exp(s.G, x, s.N)
-	s.users[email] = UserRec{salt, v}
-	// fmt.Println(salt, v)
-}
-
-func (s *Server) StartUserSession(email string) (user UserRec, public, private *big.Int) {
-	user = s.users[email]
-	var err error
-	private, err = rand.Int(rand.Reader, s.N)
-	if err != nil {
-		log.Fatal(err)
-	}
-	// B=kv + g**b % N
-	public = new(big.Int)
-	public.Exp(s.G, private, s.N)
-	kv := new(big.Int)
-	kv.Mul(s.K, user.v)
-	public.Add(kv, public)
-	public.Mod(public, s.N)
-	return
-}
-
-func SumPublicKeys(a, b *big.Int) *big.Int {
-	hash := sha256.New()
-	hash.Write(a.Bytes())
-	hash.Write(b.Bytes())
-	uhex := hash.Sum(nil)
-	u := new(big.Int).SetBytes(uhex)
-	return u
+	params.Group = &srp.Group{N: N, G: big.NewInt(2)}
 }
 
 func runServer() {
 	// create server with one user
-	s := NewServer(N, G, K)
 	email := "foo@bar.com"
 	pass := "easy"
-	s.AddUser(email, pass)
+	ver, err := params.NewVerifier(email, pass)
+	if err != nil {
+		log.Fatal(err)
+	}
+	store := srp.MapStore{email: ver}
 
 	ln, err := net.Listen("tcp", EndPoint)
 	if err != nil {
@@ -110,172 +48,113 @@ func runServer() {
 			log.Printf("connection error: %v", err)
 			continue
 		}
-		go s.serveClient(conn)
-	}
-}
-
-func makeLineReader(conn net.Conn) func() string {
-	bufReader := bufio.NewReader(conn)
-	readLine := func() string {
-		line, err := bufReader.ReadString('\n')
-		if err != nil {
-			log.Printf("err = %+v\n", err)
-		}
-		return strings.TrimSpace(line)
+		c := wire.NewConn(conn)
+		c.Name = conn.RemoteAddr().String()
+		go serveClient(c, store)
 	}
-	return readLine
 }
 
-func (s *Server) serveClient(conn net.Conn) {
-	log.Println(conn.RemoteAddr(), "connected")
-	defer log.Println(conn.RemoteAddr(), "disconnected")
-	readLine := makeLineReader(conn)
-
-	var err error
+func serveClient(c *wire.Conn, store srp.Store) {
+	defer c.Close()
+	log.Println(c.Name, "connected")
+	defer log.Println(c.Name, "disconnected")
 
 	// Receive email and A
-	email := readLine()
-	log.Printf("email = %+v\n", email)
-	str := readLine()
-	A, ok := new(big.Int).SetString(str, 10)
-	if !ok {
-		fmt.Fprintf(conn, "bad format\n")
-		log.Printf("bad number A = %+v\n", str)
+	login, err := wire.Receive[*wire.Login](c)
+	if err != nil {
+		log.Printf("err = %+v\n", err)
 		return
 	}
-	log.Printf("A = %+v\n", A)
+	log.Printf("email = %+v\n", login.Username)
+	log.Printf("A = %+v\n", login.Key)
 
 	// Send salt, B=kv + g**b % N
-	userRec, B, b := s.StartUserSession(email)
-	salt := userRec.salt
-	_, err = fmt.Fprintln(conn, salt.String())
-	if err != nil {
-		log.Fatal(err)
-	}
-	_, err = fmt.Fprintln(conn, B.String())
+	s, err := srp.NewServer(params, store, login.Username, login.Key)
 	if err != nil {
-		log.Fatal(err)
+		c.Send(&wire.Error{Reason: err.Error()})
+		return
 	}
-
-	// Generate S = (A * v**u) ** b % N
-	// Generate K = SHA256(S)
-	sServer := new(big.Int)
-	{
-		u := SumPublicKeys(A, B)
-		i := new(big.Int)
-		i.Exp(userRec.v, u, s.N)
-		i.Mul(A, i)
-		sServer.Exp(i, b, s.N)
+	if err := c.Send(&wire.SaltedKey{Salt: s.Salt(), Key: s.Public()}); err != nil {
+		log.Printf("err = %+v\n", err)
+		return
 	}
-	keyServer := sha256.Sum256(sServer.Bytes())
-	log.Printf("keyServer = %x\n", string(keyServer[:]))
 
-	hm := hmac.New(sha256.New, keyServer[:])
-	hm.Write(salt.Bytes())
-	ourSign := hm.Sum(nil)
-	log.Printf("ourSign = %+x\n", ourSign)
-	str = readLine()
-	clientSign, err := hex.DecodeString(str)
+	// Receive M1, send M2 if it validates
+	m1, err := wire.Receive[*wire.Proof](c)
 	if err != nil {
-		log.Fatal(err)
+		log.Printf("err = %+v\n", err)
+		return
 	}
-	log.Printf("cliSign = %+x\n", clientSign)
-
-	if hmac.Equal(ourSign, clientSign) {
-		_, err = fmt.Fprintln(conn, "OK")
+	log.Printf("cliSign = %+x\n", m1.MAC)
+	m2, err := s.Verify(m1.MAC)
+	if err != nil {
+		err = c.Send(&wire.Error{Reason: "INVALID"})
 	} else {
-		_, err = fmt.Fprintln(conn, "INVALID")
+		log.Printf("keyServer = %x\n", s.Key())
+		err = c.Send(&wire.Proof{MAC: m2})
 	}
 	if err != nil {
-		log.Fatal(err)
+		log.Printf("err = %+v\n", err)
 	}
 }
 
 func loginClient(spoof bool, spoofedA *big.Int) bool {
-	conn, err := net.Dial("tcp", EndPoint)
+	c, err := wire.Dial(EndPoint)
 	if err != nil {
 		log.Fatal(err)
 	}
-	readLine := makeLineReader(conn)
+	defer c.Close()
 
 	// Don't ask user for email and password interactively
 	email := "foo@bar.com"
 	pass := ""
 
 	// Send I, A=g**a % N (a la Diffie Hellman)
-	A := new(big.Int)
-	a := new(big.Int)
-	tools.DHKEGenKeys(A, a, N, G)
-	// Attack #1
-	if spoof {
-		A.Set(spoofedA)
-	}
-	// Attack #2
-	// By setting A to zero or multiple of N,
-	// we make S calculated on the server side equal to 0.
-	// A.Set(A.Mul(N, big.NewInt(2)))
-	_, err = fmt.Fprintln(conn, email)
+	client, err := srp.NewClient(params, email, pass)
 	if err != nil {
 		log.Fatal(err)
 	}
-	_, err = fmt.Fprintln(conn, A.String())
-	if err != nil {
+	A := client.Public()
+	// Attack: by setting A to zero or multiple of N, we make S calculated
+	// on the server side equal to 0.
+	if spoof {
+		A = spoofedA
+	}
+	if err := c.Send(&wire.Login{Username: email, Key: A}); err != nil {
 		log.Fatal(err)
 	}
 
 	// Receive Salt, B
-	str := readLine()
-	salt, ok := new(big.Int).SetString(str, 10)
-	if !ok {
-		fmt.Fprintf(conn, "bad format\n")
-		log.Printf("bad number salt = %+v\n", str)
-		return false
-	}
-	log.Printf("salt = %+v\n", salt)
-	str = readLine()
-	B, ok := new(big.Int).SetString(str, 10)
-	if !ok {
-		fmt.Fprintf(conn, "bad format\n")
-		log.Printf("bad number B = %+v\n", str)
+	sk, err := wire.Receive[*wire.SaltedKey](c)
+	if err != nil {
+		log.Printf("err = %+v\n", err)
 		return false
 	}
-	log.Printf("B = %+v\n", B)
+	log.Printf("salt = %x\n", sk.Salt)
+	log.Printf("B = %+v\n", sk.Key)
 
-	// Generate string xH=SHA256(salt|password)
-	// Convert xH to integer x somehow (put 0x on hexdigest)
-	// Generate S = (B - k * g**x)**(a + u * x) % N
-	// Generate K = SHA256(S)
-	sClient := new(big.Int)
-	{
-		u := SumPublicKeys(A, B)
-		x := SaltedPass(salt, pass)
-		i := new(big.Int)
-		i.Exp(G, x, N)
-		i.Mul(K, i)
-		i.Sub(B, i)
-		j := new(big.Int)
-		j.Mul(u, x)
-		j.Add(a, j)
-		sClient.Exp(i, j, N)
-	}
-	// Attack
+	// Send M1, the proof of K = H(S)
+	var m1 []byte
 	if spoof {
-		sClient.SetInt64(0)
+		key := params.SessionKey(new(big.Int))
+		m1 = params.ClientProof(email, sk.Salt, A, sk.Key, key)
+	} else if m1, err = client.Proof(sk.Salt, sk.Key); err != nil {
+		log.Printf("err = %+v\n", err)
+		return false
 	}
-	keyClient := sha256.Sum256(sClient.Bytes())
-	log.Printf("keyClient = %x\n", string(keyClient[:]))
-
-	// Send HMAC-SHA256(K, salt)
-	hm := hmac.New(sha256.New, keyClient[:])
-	hm.Write(salt.Bytes())
-	signedSalt := hm.Sum(nil)
-	_, err = fmt.Fprintln(conn, hex.EncodeToString(signedSalt))
-	if err != nil {
+	if err := c.Send(&wire.Proof{MAC: m1}); err != nil {
 		log.Fatal(err)
 	}
-	resp := readLine()
-	log.Printf("resp = %+v\n", resp)
-	return resp == "OK"
+	resp, err := wire.Receive[*wire.Proof](c)
+	if err != nil {
+		log.Printf("resp = %+v\n", err)
+		return false
+	}
+	log.Printf("resp = %x\n", resp.MAC)
+	if !spoof {
+		return client.Verify(resp.MAC) == nil
+	}
+	return true
 }
 
 func main() {
@@ -289,8 +168,8 @@ func main() {
 	}{
 		{false, nil},
 		{true, big.NewInt(0)},
-		{true, new(big.Int).Mul(N, big.NewInt(1))},
-		{true, new(big.Int).Mul(N, big.NewInt(2))},
+		{true, new(big.Int).Mul(params.Group.N, big.NewInt(1))},
+		{true, new(big.Int).Mul(params.Group.N, big.NewInt(2))},
 	}
 	for _, t := range cases {
 		valid := loginClient(t.spoof, t.A)
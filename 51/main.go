@@ -37,7 +37,6 @@ import (
 	"bytes"
 	"compress/zlib"
 	"crypto/aes"
-	"crypto/cipher"
 	"crypto/rc4"
 	"encoding/base64"
 	"fmt"
@@ -77,7 +76,7 @@ func oracleCBCCipher(request []byte) int {
 	if err != nil {
 		log.Fatal(err)
 	}
-	mode := cipher.NewCBCEncrypter(block, tools.RandBytes(16))
+	mode := tools.NewCBCEncrypter(block, tools.RandBytes(16))
 	pt := tools.PadPKCS7(b.Bytes(), 16)
 	// fmt.Printf("pt = %+q\n", string(pt))
 	ct := make([]byte, len(pt))
@@ -3,27 +3,13 @@
 package main
 
 import (
-	"bytes"
-	"crypto/hmac"
-	"crypto/rand"
-	"crypto/sha256"
 	"fmt"
 	"log"
 	"math/big"
-	"tools"
-)
 
-func pow(a, b int) int {
-	p := 1
-	for b > 0 {
-		if b&1 != 0 {
-			p *= a
-		}
-		b >>= 1
-		a *= a
-	}
-	return p
-}
+	"github.com/ysmolsky/cryptopals/tools"
+	"github.com/ysmolsky/cryptopals/tools/dlog"
+)
 
 var (
 	p, g, q, j, x *big.Int
@@ -43,79 +29,24 @@ func init() {
 	x.Mod(x, q)
 }
 
-func genPrimes(n int64) []int64 {
-	p := make([]int64, 0)
-	sieve := make(map[int64]bool)
-	for i := int64(2); i < n; i++ {
-		sieve[i] = true
-	}
-	for i := int64(2); i < n; i++ {
-		if sieve[i] {
-			p = append(p, i)
-			for j := i + i; j < n; j = j + i {
-				sieve[j] = false
-			}
-		}
-	}
-	return p
-}
-
-func MAC(key, msg []byte) []byte {
-	h := hmac.New(sha256.New, key)
-	h.Write(msg)
-	return h.Sum(nil)
-}
+// bob raises whatever he receives to his secret x and MACs a message with
+// the result.
+type bob struct{}
 
-func msgFromBob(h *big.Int) (msg, mac []byte) {
+func (bob) Query(h *big.Int) (msg, mac []byte, err error) {
 	k := new(big.Int).Exp(h, x, p)
 	msg = []byte("crazy flamboyant for the rap enjoyment")
-	mac = MAC(k.Bytes(), msg)
-	return msg, mac
+	mac = dlog.MAC(k.Bytes(), msg)
+	return msg, mac, nil
 }
 
 func main() {
-
-	primes := genPrimes(0x10000)
-	// fmt.Println(primes)
-	divs := make([]int64, 0)
-	for _, p := range primes {
-		rem := new(big.Int).Rem(j, big.NewInt(p))
-		if rem.Sign() == 0 {
-			divs = append(divs, p)
-		}
+	attack := &dlog.SubgroupAttack{P: p, G: g, Q: q, J: j, Oracle: bob{}}
+	res, err := attack.Run()
+	if err != nil {
+		log.Fatal(err)
 	}
-
-	fmt.Println(divs)
-
-	pMinus1 := new(big.Int).Sub(p, big.NewInt(1))
-	one := big.NewInt(1)
-	for _, r := range divs {
-		// generate h of order r
-		var h *big.Int
-		for {
-			num, err := rand.Int(rand.Reader, p)
-			if err != nil {
-				log.Fatal(err)
-			}
-			exp := new(big.Int).Div(pMinus1, big.NewInt(r))
-			h = new(big.Int).Exp(num, exp, p)
-			if h.Cmp(one) != 0 {
-				break
-			}
-		}
-		fmt.Printf("r = %d\n", r)
-		// send h to BOB and get signed msg from him
-		m, t := msgFromBob(h)
-		// find x mod r
-		for x := int64(0); x < r; x++ {
-			k := new(big.Int).Exp(h, big.NewInt(x), p)
-			macGuess := MAC(k.Bytes(), m)
-			if bytes.Equal(t, macGuess) {
-				fmt.Printf("x = %+v\n", x)
-				break
-			}
-		}
-		fmt.Println("")
-	}
-
+	fmt.Println("recovered", res)
+	fmt.Println("bob's x  =", x)
+	fmt.Println(res.A.Cmp(x) == 0)
 }
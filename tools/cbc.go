@@ -2,52 +2,138 @@ package tools
 
 import "crypto/cipher"
 
-func CBCEncrypt(block cipher.Block, IV, dst, src []byte) {
-	sz := block.BlockSize()
+type cbc struct {
+	b  cipher.Block
+	sz int
+	iv []byte
+}
+
+func newCBC(b cipher.Block, IV []byte) *cbc {
+	sz := b.BlockSize()
 	if sz != len(IV) {
-		panic("IV length should be equal to block size")
+		panic(ErrIVSize)
 	}
-	if len(dst) != len(src) {
-		panic("length of dst and src should be equal")
+	iv := make([]byte, sz)
+	copy(iv, IV)
+	return &cbc{b: b, sz: sz, iv: iv}
+}
+
+func (x *cbc) check(dst, src []byte) {
+	if err := checkBlocks(x.sz, dst, src); err != nil {
+		panic(err)
 	}
-	if len(dst)%sz != 0 {
-		panic("dst should be padded to the block size")
+}
+
+// checkBlocks verifies that dst and src can be processed by a block mode with
+// block size sz.
+func checkBlocks(sz int, dst, src []byte) error {
+	if len(dst) != len(src) {
+		return ErrLengthMismatch
 	}
 	if len(src)%sz != 0 {
-		panic("src should be padded to the block size")
+		return ErrNotBlockAligned
 	}
-	prev := IV
-	for i := 0; i < len(src)/sz; i++ {
-		x := sz * i
-		y := sz * (i + 1)
-		copy(dst[x:y], src[x:y])
-		XorBytesInplace(dst[x:y], prev)
-		block.Encrypt(dst[x:y], dst[x:y])
-		prev = dst[x:y]
+	return nil
+}
+
+// checkCBC verifies the arguments of CBCEncrypt and CBCDecrypt.
+func checkCBC(block cipher.Block, IV, dst, src []byte) error {
+	if block.BlockSize() != len(IV) {
+		return ErrIVSize
 	}
+	return checkBlocks(block.BlockSize(), dst, src)
 }
 
-func CBCDecrypt(block cipher.Block, IV, dst, src []byte) {
-	sz := block.BlockSize()
-	if sz != len(IV) {
-		panic("IV length should be equal to block size")
+type cbcEncrypter cbc
+
+// NewCBCEncrypter returns a cipher.BlockMode which encrypts in CBC mode using
+// the given block. The IV is copied and then chained across calls to
+// CryptBlocks, so a stream can be encrypted in several pieces.
+func NewCBCEncrypter(block cipher.Block, IV []byte) cipher.BlockMode {
+	return (*cbcEncrypter)(newCBC(block, IV))
+}
+
+func (x *cbcEncrypter) BlockSize() int { return x.sz }
+
+func (x *cbcEncrypter) CryptBlocks(dst, src []byte) {
+	(*cbc)(x).check(dst, src)
+	prev := x.iv
+	for i := 0; i < len(src)/x.sz; i++ {
+		from := x.sz * i
+		to := x.sz * (i + 1)
+		copy(dst[from:to], src[from:to])
+		XorBytesInplace(dst[from:to], prev)
+		x.b.Encrypt(dst[from:to], dst[from:to])
+		prev = dst[from:to]
 	}
-	if len(dst) != len(src) {
-		panic("length of dst and src should be equal")
+	copy(x.iv, prev)
+}
+
+type cbcDecrypter cbc
+
+// NewCBCDecrypter returns a cipher.BlockMode which decrypts in CBC mode using
+// the given block. The IV is copied and then chained across calls to
+// CryptBlocks.
+func NewCBCDecrypter(block cipher.Block, IV []byte) cipher.BlockMode {
+	return (*cbcDecrypter)(newCBC(block, IV))
+}
+
+func (x *cbcDecrypter) BlockSize() int { return x.sz }
+
+func (x *cbcDecrypter) CryptBlocks(dst, src []byte) {
+	(*cbc)(x).check(dst, src)
+	if len(src) == 0 {
+		return
 	}
-	if len(dst)%sz != 0 {
-		panic("dst should be padded to the block size")
+	// Decrypt from the last block backwards, so that src and dst may overlap
+	// and every ciphertext block is still available when it is needed as
+	// the chaining value for the next one.
+	n := len(src) / x.sz
+	last := make([]byte, x.sz)
+	copy(last, src[(n-1)*x.sz:])
+	for i := n - 1; i >= 0; i-- {
+		from := x.sz * i
+		to := x.sz * (i + 1)
+		prev := x.iv
+		if i > 0 {
+			prev = src[from-x.sz : from]
+		}
+		x.b.Decrypt(dst[from:to], src[from:to])
+		XorBytesInplace(dst[from:to], prev)
 	}
-	if len(src)%sz != 0 {
-		panic("src should be padded to the block size")
-	}
-	prev := IV
-	for i := 0; i < len(src)/sz; i++ {
-		x := sz * i
-		y := sz * (i + 1)
-		copy(dst[x:y], src[x:y])
-		block.Decrypt(dst[x:y], dst[x:y])
-		XorBytesInplace(dst[x:y], prev)
-		prev = src[x:y]
+	copy(x.iv, last)
+}
+
+// CBCEncryptChecked encrypts src into dst in CBC mode. It returns ErrIVSize,
+// ErrLengthMismatch or ErrNotBlockAligned instead of panicking.
+func CBCEncryptChecked(block cipher.Block, IV, dst, src []byte) error {
+	if err := checkCBC(block, IV, dst, src); err != nil {
+		return err
+	}
+	NewCBCEncrypter(block, IV).CryptBlocks(dst, src)
+	return nil
+}
+
+// CBCDecryptChecked decrypts src into dst in CBC mode. It returns ErrIVSize,
+// ErrLengthMismatch or ErrNotBlockAligned instead of panicking.
+func CBCDecryptChecked(block cipher.Block, IV, dst, src []byte) error {
+	if err := checkCBC(block, IV, dst, src); err != nil {
+		return err
+	}
+	NewCBCDecrypter(block, IV).CryptBlocks(dst, src)
+	return nil
+}
+
+// CBCEncrypt is like CBCEncryptChecked but panics on invalid arguments.
+func CBCEncrypt(block cipher.Block, IV, dst, src []byte) {
+	if err := CBCEncryptChecked(block, IV, dst, src); err != nil {
+		panic(err)
+	}
+}
+
+// CBCDecrypt is like CBCDecryptChecked but panics on invalid arguments.
+func CBCDecrypt(block cipher.Block, IV, dst, src []byte) {
+	if err := CBCDecryptChecked(block, IV, dst, src); err != nil {
+		panic(err)
 	}
 }
@@ -0,0 +1,104 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"os"
+	"testing"
+)
+
+// challengeInputs are the data files shipped with the challenges of sets 2-4.
+var challengeInputs = []string{
+	"../10/10.txt",
+	"../12/12.txt",
+	"../14/12.txt",
+	"../19/19.txt",
+	"../20/20.txt",
+	"../25-set4/25.txt",
+}
+
+// readChallengeInputs returns the raw contents of every challenge input along
+// with every line of it that decodes as base64.
+func readChallengeInputs(t *testing.T) [][]byte {
+	var res [][]byte
+	for _, name := range challengeInputs {
+		raw, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res = append(res, raw)
+		scanner := bufio.NewScanner(bytes.NewReader(raw))
+		for scanner.Scan() {
+			line, err := base64.StdEncoding.DecodeString(scanner.Text())
+			if err == nil && len(line) > 0 {
+				res = append(res, line)
+			}
+		}
+	}
+	return res
+}
+
+func TestCBCMatchesStdlib(t *testing.T) {
+	block, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	IV := []byte("0123456789abcdef")
+	for _, in := range readChallengeInputs(t) {
+		pt := PadPKCS7(in, aes.BlockSize)
+		exp := make([]byte, len(pt))
+		cipher.NewCBCEncrypter(block, IV).CryptBlocks(exp, pt)
+
+		got := make([]byte, len(pt))
+		NewCBCEncrypter(block, IV).CryptBlocks(got, pt)
+		if !bytes.Equal(got, exp) {
+			t.Fatalf("NewCBCEncrypter(%.16q) differs from crypto/cipher", in)
+		}
+		got2 := make([]byte, len(pt))
+		CBCEncrypt(block, IV, got2, pt)
+		if !bytes.Equal(got2, exp) {
+			t.Fatalf("CBCEncrypt(%.16q) differs from crypto/cipher", in)
+		}
+
+		dec := make([]byte, len(exp))
+		cipher.NewCBCDecrypter(block, IV).CryptBlocks(dec, exp)
+		got3 := make([]byte, len(exp))
+		NewCBCDecrypter(block, IV).CryptBlocks(got3, exp)
+		if !bytes.Equal(got3, dec) || !bytes.Equal(got3, pt) {
+			t.Fatalf("NewCBCDecrypter(%.16q) differs from crypto/cipher", in)
+		}
+	}
+}
+
+func TestCBCChaining(t *testing.T) {
+	block, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	IV := make([]byte, aes.BlockSize)
+	for _, in := range readChallengeInputs(t) {
+		pt := PadPKCS7(in, aes.BlockSize)
+		exp := make([]byte, len(pt))
+		cipher.NewCBCEncrypter(block, IV).CryptBlocks(exp, pt)
+
+		// Feed the modes one block at a time, the IV must be carried over.
+		enc := NewCBCEncrypter(block, IV)
+		dec := NewCBCDecrypter(block, IV)
+		got := make([]byte, len(pt))
+		for i := 0; i < len(pt); i += aes.BlockSize {
+			enc.CryptBlocks(got[i:i+aes.BlockSize], pt[i:i+aes.BlockSize])
+		}
+		if !bytes.Equal(got, exp) {
+			t.Fatalf("chained encryption of %.16q differs from crypto/cipher", in)
+		}
+		for i := 0; i < len(got); i += aes.BlockSize {
+			dec.CryptBlocks(got[i:i+aes.BlockSize], got[i:i+aes.BlockSize])
+		}
+		if !bytes.Equal(got, pt) {
+			t.Fatalf("chained in-place decryption of %.16q failed", in)
+		}
+	}
+}
@@ -4,66 +4,171 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"encoding/binary"
-	"log"
 )
 
-func CTREncrypt(block cipher.Block, nonce uint64, dst, src []byte) {
+// CTRConfig describes the layout of the CTR counter block: a fixed Nonce
+// followed by a counter field of CounterSize bytes, which together have to
+// fill exactly one cipher block. The counter wraps around within its field
+// and never carries into the nonce.
+type CTRConfig struct {
+	Nonce       []byte
+	CounterSize int
+	// BigEndian selects the byte order of the counter field, the counter is
+	// little-endian otherwise.
+	BigEndian bool
+	// Initial is the initial content of the counter field exactly as it
+	// appears in the counter block. A nil Initial starts counting from zero.
+	Initial []byte
+}
+
+// Challenge18CTR returns the layout used by challenge 18: a 64 bit
+// little-endian nonce followed by a 64 bit little-endian block counter.
+func Challenge18CTR(nonce uint64) CTRConfig {
+	c := CTRConfig{Nonce: make([]byte, 8), CounterSize: 8}
+	binary.LittleEndian.PutUint64(c.Nonce, nonce)
+	return c
+}
+
+// NISTCTR returns the layout of NIST SP 800-38A, where the whole block is a
+// single big-endian counter starting at the initial counter block iv.
+func NISTCTR(iv []byte) CTRConfig {
+	return CTRConfig{CounterSize: len(iv), BigEndian: true, Initial: iv}
+}
+
+// GCMCTR returns the layout used by GCM: a 96 bit nonce followed by a 32 bit
+// big-endian counter starting at initial.
+func GCMCTR(nonce []byte, initial uint32) CTRConfig {
+	c := CTRConfig{Nonce: nonce, CounterSize: 4, BigEndian: true, Initial: make([]byte, 4)}
+	binary.BigEndian.PutUint32(c.Initial, initial)
+	return c
+}
+
+// CTRStream is a cipher.Stream producing the CTR keystream for a CTRConfig.
+// It can be positioned at an arbitrary byte offset with Seek.
+type CTRStream struct {
+	block     cipher.Block
+	cfg       CTRConfig
+	ctr       []byte
+	keystream []byte
+	used      int
+}
+
+// NewCTRStream returns a CTR stream for block laid out according to cfg. It
+// returns ErrBlockSize if the nonce and the counter do not fill a block.
+func NewCTRStream(block cipher.Block, cfg CTRConfig) (*CTRStream, error) {
 	sz := block.BlockSize()
-	if sz != 16 {
-		panic("CTR mode supports only 128bit keys")
+	if cfg.CounterSize <= 0 || len(cfg.Nonce)+cfg.CounterSize != sz {
+		return nil, ErrBlockSize
 	}
-	if len(dst) != len(src) {
-		panic("length of dst and src should be equal")
+	if cfg.Initial != nil && len(cfg.Initial) != cfg.CounterSize {
+		return nil, ErrBlockSize
+	}
+	s := &CTRStream{
+		block:     block,
+		cfg:       cfg,
+		ctr:       make([]byte, sz),
+		keystream: make([]byte, sz),
 	}
-	buf := make([]byte, sz)
-	binary.PutUvarint(buf[:8], nonce)
-	var counter uint64
-	key := make([]byte, sz)
-	for i := 0; i < len(src); i += sz {
-		// derive a key
-		binary.PutUvarint(buf[8:sz], counter)
-		block.Encrypt(key, buf)
-		// fmt.Printf("i = %d counter = %d key = %#x\n", i, counter, key)
+	s.Seek(0)
+	return s, nil
+}
 
-		h := i + sz
-		if h > len(src) {
-			h = len(src)
+// Seek positions the keystream at byte offset from the initial counter.
+func (s *CTRStream) Seek(offset uint64) {
+	sz := uint64(len(s.ctr))
+	copy(s.ctr, s.cfg.Nonce)
+	field := s.ctr[len(s.cfg.Nonce):]
+	if s.cfg.Initial != nil {
+		copy(field, s.cfg.Initial)
+	} else {
+		for i := range field {
+			field[i] = 0
 		}
-		copy(dst[i:h], src[i:h])
-		XorBytesInplace(dst[i:h], key)
-		counter++
 	}
-	// erase key buffer
-	copy(key, buf)
+	s.add(offset / sz)
+	s.block.Encrypt(s.keystream, s.ctr)
+	s.used = int(offset % sz)
 }
 
-func CTREdit(ct, key []byte, nonce, offset uint64, newtext []byte) {
+// add adds v to the counter field modulo 2^(8*CounterSize).
+func (s *CTRStream) add(v uint64) {
+	field := s.ctr[len(s.cfg.Nonce):]
+	for i := 0; i < len(field) && v != 0; i++ {
+		pos := i
+		if s.cfg.BigEndian {
+			pos = len(field) - 1 - i
+		}
+		sum := uint64(field[pos]) + v&0xff
+		field[pos] = byte(sum)
+		v = v>>8 + sum>>8
+	}
+}
+
+// XORKeyStream xors each byte in src with a byte from the keystream and puts
+// the result into dst. dst and src may overlap entirely.
+func (s *CTRStream) XORKeyStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic(ErrLengthMismatch)
+	}
+	for i := range src {
+		if s.used == len(s.keystream) {
+			s.add(1)
+			s.block.Encrypt(s.keystream, s.ctr)
+			s.used = 0
+		}
+		dst[i] = src[i] ^ s.keystream[s.used]
+		s.used++
+	}
+}
+
+// CTREncryptChecked xors src with the CTR keystream of block in the layout of
+// Challenge18CTR and puts the result into dst. It returns ErrBlockSize or
+// ErrLengthMismatch instead of panicking.
+func CTREncryptChecked(block cipher.Block, nonce uint64, dst, src []byte) error {
+	if block.BlockSize() != 16 {
+		return ErrBlockSize
+	}
+	if len(dst) != len(src) {
+		return ErrLengthMismatch
+	}
+	s, err := NewCTRStream(block, Challenge18CTR(nonce))
+	if err != nil {
+		return err
+	}
+	s.XORKeyStream(dst, src)
+	return nil
+}
+
+// CTREncrypt is like CTREncryptChecked but panics on invalid arguments.
+func CTREncrypt(block cipher.Block, nonce uint64, dst, src []byte) {
+	if err := CTREncryptChecked(block, nonce, dst, src); err != nil {
+		panic(err)
+	}
+}
+
+// CTREditChecked replaces the plaintext under ct starting at offset with
+// newtext. It returns the error of aes.NewCipher for a bad key and
+// ErrOutOfRange if newtext does not fit into ct.
+func CTREditChecked(ct, key []byte, nonce, offset uint64, newtext []byte) error {
 	block, err := aes.NewCipher(key)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	sz := block.BlockSize()
-	pt2 := make([]byte, len(ct))
-	copy(pt2[offset:], newtext)
-	buf := make([]byte, sz)
-	binary.PutUvarint(buf[:8], nonce)
-	keystream := make([]byte, sz)
-	// fmt.Println(string(pt2))
-	counter := offset / uint64(sz)
-	end := offset + uint64(len(newtext))
-	for i := counter * uint64(sz); i < end; i += uint64(sz) {
-		// derive a key
-		binary.PutUvarint(buf[8:sz], counter)
-		block.Encrypt(keystream, buf)
-		// fmt.Printf("i = %d counter = %d key = %#x\n", i, counter, keystream)
-		h := int(i) + sz
-		if h > len(pt2) {
-			h = len(pt2)
-		}
-		// fmt.Println("i=", i, "h=", h)
-		XorBytesInplace(pt2[i:h], keystream)
-		counter++
+	if offset > uint64(len(ct)) || uint64(len(newtext)) > uint64(len(ct))-offset {
+		return ErrOutOfRange
+	}
+	s, err := NewCTRStream(block, Challenge18CTR(nonce))
+	if err != nil {
+		return err
+	}
+	s.Seek(offset)
+	s.XORKeyStream(ct[offset:], newtext)
+	return nil
+}
+
+// CTREdit is like CTREditChecked but panics on invalid arguments.
+func CTREdit(ct, key []byte, nonce, offset uint64, newtext []byte) {
+	if err := CTREditChecked(ct, key, nonce, offset, newtext); err != nil {
+		panic(err)
 	}
-	// fmt.Println(string(pt2))
-	copy(ct[offset:end], pt2[offset:end])
 }
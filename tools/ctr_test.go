@@ -3,7 +3,9 @@ package tools
 import (
 	"bytes"
 	"crypto/aes"
+	"crypto/cipher"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"log"
 	"testing"
@@ -53,3 +55,198 @@ func TestCTRValue(t *testing.T) {
 		t.Errorf("expected %#v, got %#v", exp, string(dst))
 	}
 }
+
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// NIST SP 800-38A, F.5 CTR example vectors.
+var nistCTRTests = []struct {
+	name string
+	key  string
+	ct   string
+}{
+	{
+		"F.5.1 CTR-AES128",
+		"2b7e151628aed2a6abf7158809cf4f3c",
+		"874d6191b620e3261bef6864990db6ce" +
+			"9806f66b7970fdff8617187bb9fffdff" +
+			"5ae4df3edbd5d35e5b4f09020db03eab" +
+			"1e031dda2fbe03d1792170a0f3009cee",
+	},
+	{
+		"F.5.3 CTR-AES192",
+		"8e73b0f7da0e6452c810f32b809079e562f8ead2522c6b7b",
+		"1abc932417521ca24f2b0459fe7e6e0b" +
+			"090339ec0aa6faefd5ccc2c6f4ce8e94" +
+			"1e36b26bd1ebc670d1bd1d665620abf7" +
+			"4f78a7f6d29809585a97daec58c6b050",
+	},
+	{
+		"F.5.5 CTR-AES256",
+		"603deb1015ca71be2b73aef0857d77811f352c073b6108d72d9810a30914dff4",
+		"601ec313775789a5b7a7f504bbf3d228" +
+			"f443e3ca4d62b59aca84e990cacaf5c5" +
+			"2b0930daa23de94ce87017ba2d84988d" +
+			"dfc9c58db67aada613c2dd08457941a6",
+	},
+}
+
+func TestCTRStreamNIST(t *testing.T) {
+	iv := unhex("f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	pt := unhex("6bc1bee22e409f96e93d7e117393172a" +
+		"ae2d8a571e03ac9c9eb76fac45af8e51" +
+		"30c81c46a35ce411e5fbc1191a0a52ef" +
+		"f69f2445df4f9b17ad2b417be66c3710")
+	for _, test := range nistCTRTests {
+		block, err := aes.NewCipher(unhex(test.key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := NewCTRStream(block, NISTCTR(iv))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]byte, len(pt))
+		s.XORKeyStream(got, pt)
+		if exp := unhex(test.ct); !bytes.Equal(got, exp) {
+			t.Errorf("%s: got %x; want %x", test.name, got, exp)
+		}
+	}
+}
+
+func TestCTRStreamMatchesStdlib(t *testing.T) {
+	block, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	// the 128 bit counter has to carry over all of its bytes
+	iv := unhex("00ffffffffffffffffffffffffffffff")
+	src := RandBytes(1000)
+	exp := make([]byte, len(src))
+	cipher.NewCTR(block, iv).XORKeyStream(exp, src)
+	s, err := NewCTRStream(block, NISTCTR(iv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]byte, len(src))
+	s.XORKeyStream(got[:7], src[:7])
+	s.XORKeyStream(got[7:], src[7:])
+	if !bytes.Equal(got, exp) {
+		t.Errorf("128 bit counter differs from cipher.NewCTR")
+	}
+}
+
+func TestCTRStreamWraps32(t *testing.T) {
+	block, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	nonce := unhex("000102030405060708090a0b")
+	s, err := NewCTRStream(block, GCMCTR(nonce, 0xffffffff))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]byte, 32)
+	s.XORKeyStream(got, got)
+
+	exp := make([]byte, 32)
+	block.Encrypt(exp[:16], append(append([]byte{}, nonce...), 0xff, 0xff, 0xff, 0xff))
+	block.Encrypt(exp[16:], append(append([]byte{}, nonce...), 0, 0, 0, 0))
+	if !bytes.Equal(got, exp) {
+		t.Errorf("32 bit counter did not wrap without touching the nonce")
+	}
+}
+
+func TestCTRStreamLittleEndian(t *testing.T) {
+	block, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	// The challenge 18 layout must keep counting past one byte of counter.
+	s, err := NewCTRStream(block, Challenge18CTR(0x0102))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Seek(300 * 16)
+	got := make([]byte, 16)
+	s.XORKeyStream(got, got)
+	exp := make([]byte, 16)
+	block.Encrypt(exp, unhex("0201000000000000"+"2c01000000000000"))
+	if !bytes.Equal(got, exp) {
+		t.Errorf("keystream block 300 = %x; want %x", got, exp)
+	}
+}
+
+func TestCTRStreamSeek(t *testing.T) {
+	block, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	configs := []CTRConfig{
+		Challenge18CTR(7),
+		NISTCTR(unhex("fffffffffffffffffffffffffffffff0")),
+		GCMCTR(make([]byte, 12), 0xfffffff0),
+		{Nonce: []byte("abcd"), CounterSize: 12, Initial: unhex("fdffffffffffffffffffffff")},
+	}
+	for _, cfg := range configs {
+		s, err := NewCTRStream(block, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		full := make([]byte, 1024)
+		s.XORKeyStream(full, full)
+		for _, off := range []int{0, 1, 15, 16, 17, 255, 256, 600, 1023} {
+			s.Seek(uint64(off))
+			got := make([]byte, len(full)-off)
+			s.XORKeyStream(got, got)
+			if !bytes.Equal(got, full[off:]) {
+				t.Errorf("%+v: keystream after Seek(%d) differs", cfg, off)
+			}
+		}
+	}
+}
+
+func TestCTRStreamLayoutErrors(t *testing.T) {
+	block, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	bad := []CTRConfig{
+		{Nonce: make([]byte, 8), CounterSize: 4},
+		{Nonce: make([]byte, 16)},
+		{CounterSize: 16, Initial: make([]byte, 4)},
+	}
+	for _, cfg := range bad {
+		if _, err := NewCTRStream(block, cfg); err != ErrBlockSize {
+			t.Errorf("NewCTRStream(%+v) = %v; want %v", cfg, err, ErrBlockSize)
+		}
+	}
+}
+
+func TestCTREdit(t *testing.T) {
+	key := []byte("YELLOW SUBMARINE")
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		log.Fatal(err)
+	}
+	pt := RandBytes(200)
+	ct := make([]byte, len(pt))
+	CTREncrypt(block, 42, ct, pt)
+	newtext := []byte("the quick brown fox jumps over the lazy dog")
+	for _, off := range []uint64{0, 5, 16, 31, 157} {
+		edited := append([]byte{}, ct...)
+		CTREdit(edited, key, 42, off, newtext)
+		got := make([]byte, len(edited))
+		CTREncrypt(block, 42, got, edited)
+		exp := append([]byte{}, pt...)
+		copy(exp[off:], newtext)
+		if !bytes.Equal(got, exp) {
+			t.Errorf("CTREdit at offset %d did not replace the plaintext", off)
+		}
+	}
+}
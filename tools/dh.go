@@ -3,17 +3,26 @@ package tools
 import (
 	"crypto/rand"
 	"crypto/sha256"
-	"log"
 	"math/big"
 )
 
-func DHKEGenKeys(public, private, p, g *big.Int) {
+// DHKEGenKeysChecked picks a random private key below p and sets public to
+// g^private mod p.
+func DHKEGenKeysChecked(public, private, p, g *big.Int) error {
 	n, err := rand.Int(rand.Reader, p)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	private.Set(n)
 	public.Exp(g, private, p)
+	return nil
+}
+
+// DHKEGenKeys is like DHKEGenKeysChecked but panics on failure.
+func DHKEGenKeys(public, private, p, g *big.Int) {
+	if err := DHKEGenKeysChecked(public, private, p, g); err != nil {
+		panic(err)
+	}
 }
 
 func DHKESessionKey(public, private, p *big.Int) []byte {
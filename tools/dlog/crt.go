@@ -0,0 +1,45 @@
+package dlog
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// ErrInconsistent is returned by CRT when the congruences have no common
+// solution.
+var ErrInconsistent = errors.New("dlog: congruences are inconsistent")
+
+// Congruence is the relation x = A mod M.
+type Congruence struct {
+	A, M *big.Int
+}
+
+func (c Congruence) String() string {
+	return fmt.Sprintf("x = %v mod %v", c.A, c.M)
+}
+
+// CRT combines congruences into a single one modulo the least common
+// multiple of their moduli using the Chinese Remainder Theorem. The moduli
+// do not have to be coprime as long as the congruences agree.
+func CRT(cs []Congruence) (Congruence, error) {
+	res := Congruence{big.NewInt(0), big.NewInt(1)}
+	for _, c := range cs {
+		// x = res.A + res.M * t, solve res.M * t = c.A - res.A mod c.M
+		g, u := new(big.Int), new(big.Int)
+		g.GCD(u, nil, res.M, c.M)
+		diff := new(big.Int).Sub(c.A, res.A)
+		rem := new(big.Int)
+		diff.QuoRem(diff, g, rem)
+		if rem.Sign() != 0 {
+			return Congruence{}, ErrInconsistent
+		}
+		m := new(big.Int).Quo(c.M, g)
+		t := diff.Mul(diff, u)
+		t.Mod(t, m)
+		res.A.Add(res.A, t.Mul(t, res.M))
+		res.M.Mul(res.M, m)
+		res.A.Mod(res.A, res.M)
+	}
+	return res, nil
+}
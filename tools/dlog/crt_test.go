@@ -0,0 +1,38 @@
+package dlog
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestCRT(t *testing.T) {
+	tests := []struct {
+		cs   [][2]int64
+		a, m int64
+	}{
+		{[][2]int64{{2, 3}, {3, 5}, {2, 7}}, 23, 105},
+		{[][2]int64{{0, 4}, {3, 6}}, 0, 0}, // inconsistent
+		{[][2]int64{{1, 4}, {3, 6}}, 9, 12},
+		{[][2]int64{{5, 11}}, 5, 11},
+		{nil, 0, 1},
+	}
+	for _, test := range tests {
+		var cs []Congruence
+		for _, c := range test.cs {
+			cs = append(cs, Congruence{big.NewInt(c[0]), big.NewInt(c[1])})
+		}
+		got, err := CRT(cs)
+		if test.m == 0 {
+			if err != ErrInconsistent {
+				t.Errorf("CRT(%v) = %v; want %v", test.cs, err, ErrInconsistent)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.A.Int64() != test.a || got.M.Int64() != test.m {
+			t.Errorf("CRT(%v) = %v; want x = %d mod %d", test.cs, got, test.a, test.m)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Package dlog collects the discrete logarithm tools of set 8: a group
+// abstraction covering integers mod p and elliptic curves, Pollard's
+// kangaroo for logarithms in an interval, Pohlig-Hellman for groups of
+// smooth order and the Chinese Remainder Theorem.
+package dlog
+
+import (
+	"math/big"
+
+	"github.com/ysmolsky/cryptopals/tools/ec"
+)
+
+// Group is a cyclic group with elements of type E. Mul is the group
+// operation and Exp its repetition, which is exponentiation for integers mod
+// p and scalar multiplication for elliptic curves.
+type Group[E any] interface {
+	Mul(a, b E) E
+	Exp(base E, k *big.Int) E
+	Equal(a, b E) bool
+	// Index maps an element to an integer, it is used to derive pseudorandom
+	// jumps from the element.
+	Index(e E) uint64
+}
+
+// ModP is the multiplicative group of integers mod P.
+type ModP struct {
+	P *big.Int
+}
+
+func (g ModP) Mul(a, b *big.Int) *big.Int {
+	r := new(big.Int).Mul(a, b)
+	return r.Mod(r, g.P)
+}
+
+func (g ModP) Exp(base *big.Int, k *big.Int) *big.Int {
+	return new(big.Int).Exp(base, k, g.P)
+}
+
+func (g ModP) Equal(a, b *big.Int) bool {
+	return a.Cmp(b) == 0
+}
+
+func (g ModP) Index(e *big.Int) uint64 {
+	words := e.Bits()
+	if len(words) == 0 {
+		return 0
+	}
+	return uint64(words[0])
+}
+
+// Curve is the group of points of an elliptic curve.
+type Curve struct {
+	C *ec.Curve
+}
+
+func (g Curve) Mul(a, b *ec.Point) *ec.Point {
+	return g.C.Add(a, b)
+}
+
+func (g Curve) Exp(base *ec.Point, k *big.Int) *ec.Point {
+	return g.C.ScalarMult(base, k)
+}
+
+func (g Curve) Equal(a, b *ec.Point) bool {
+	return a.Equal(b)
+}
+
+func (g Curve) Index(e *ec.Point) uint64 {
+	if e.IsInfinity() {
+		return 0
+	}
+	return ModP{}.Index(e.X)
+}
@@ -0,0 +1,147 @@
+package dlog
+
+import (
+	"errors"
+	"math/big"
+)
+
+// ErrNotFound is returned when the wild kangaroo jumps past the tame one
+// without a collision on every attempt, or when it only collides outside
+// the interval.
+var ErrNotFound = errors.New("dlog: kangaroo did not catch the index")
+
+// KangarooOptions tune Pollard's kangaroo. The zero value picks everything
+// from the size of the interval.
+type KangarooOptions[E any] struct {
+	// Jump maps an element to the distance it jumps, which must be
+	// positive. By default e jumps by Jumps[(Index(e) + attempt) mod
+	// len(Jumps)]. A custom Jump does not change between attempts, so the
+	// search is only run once.
+	Jump func(e E) *big.Int
+	// Jumps are the jump distances of the default Jump. The default is
+	// 2^0 ... 2^(k-1) with the mean jump close to sqrt(b - a) / 2.
+	Jumps []*big.Int
+	// Leaps is the number of leaps the tame kangaroo takes, 4 times the
+	// mean of Jumps by default.
+	Leaps *big.Int
+	// Attempts is the number of times the search is repeated with shifted
+	// jumps when the wild kangaroo escapes, 4 by default.
+	Attempts int
+	// Progress is called every ProgressEvery leaps of either kangaroo with
+	// the total number of leaps so far.
+	Progress      func(leaps uint64)
+	ProgressEvery uint64
+}
+
+// DefaultJumps returns the jumps 2^0 ... 2^(k-1) for an interval of the
+// given width, with k chosen so that the mean jump is about sqrt(width)/2.
+func DefaultJumps(width *big.Int) []*big.Int {
+	k := width.BitLen()/2 + 4
+	jumps := make([]*big.Int, k)
+	for i := range jumps {
+		jumps[i] = new(big.Int).Lsh(big.NewInt(1), uint(i))
+	}
+	return jumps
+}
+
+// Kangaroo returns x in [a, b] such that g^x = y mod p.
+func Kangaroo(g, y, p, a, b *big.Int) (*big.Int, error) {
+	return KangarooGroup[*big.Int](ModP{p}, g, y, a, b, nil)
+}
+
+// KangarooGroup returns x in [a, b] such that g^x = y in grp using Pollard's
+// kangaroo (lambda) method. It takes about sqrt(b - a) group operations and
+// constant memory.
+func KangarooGroup[E any](grp Group[E], g, y E, a, b *big.Int, opts *KangarooOptions[E]) (*big.Int, error) {
+	if opts == nil {
+		opts = &KangarooOptions[E]{}
+	}
+	width := new(big.Int).Sub(b, a)
+	jumps := opts.Jumps
+	if jumps == nil {
+		jumps = DefaultJumps(width)
+	}
+	k := uint64(len(jumps))
+	// precompute g^jump so that every leap is a single multiplication
+	steps := make([]E, k)
+	mean := new(big.Int)
+	for i, j := range jumps {
+		steps[i] = grp.Exp(g, j)
+		mean.Add(mean, j)
+	}
+	mean.Div(mean, big.NewInt(int64(k)))
+	leaps := opts.Leaps
+	if leaps == nil {
+		leaps = new(big.Int).Mul(mean, big.NewInt(4))
+	}
+	attempts := opts.Attempts
+	if attempts <= 0 {
+		attempts = 4
+	}
+	// g^jump of a custom Jump, computed once per distinct jump
+	var cache map[string]E
+	if opts.Jump != nil {
+		attempts = 1
+		cache = make(map[string]E)
+	}
+	every := opts.ProgressEvery
+	if every == 0 {
+		every = 1 << 16
+	}
+
+	var total uint64
+	leap := func() {
+		total++
+		if opts.Progress != nil && total%every == 0 {
+			opts.Progress(total)
+		}
+	}
+	for attempt := uint64(0); attempt < uint64(attempts); attempt++ {
+		jump := func(e E) (*big.Int, E) {
+			if opts.Jump == nil {
+				j := (grp.Index(e) + attempt) % k
+				return jumps[j], steps[j]
+			}
+			d := opts.Jump(e)
+			key := string(d.Bytes())
+			step, ok := cache[key]
+			if !ok {
+				step = grp.Exp(g, d)
+				cache[key] = step
+			}
+			return d, step
+		}
+		// the tame kangaroo starts at g^b and leaves a trap at g^(b + xT)
+		xT := new(big.Int)
+		yT := grp.Exp(g, b)
+		for i := new(big.Int); i.Cmp(leaps) < 0; i.Add(i, one) {
+			d, step := jump(yT)
+			xT.Add(xT, d)
+			yT = grp.Mul(yT, step)
+			leap()
+		}
+		// the wild kangaroo starts at y and either falls into the trap or
+		// jumps past it. It lands on the trap too soon when the log of y is
+		// above b, which is not an answer.
+		limit := new(big.Int).Add(width, xT)
+		xW := new(big.Int)
+		yW := y
+		for xW.Cmp(limit) <= 0 {
+			if grp.Equal(yW, yT) {
+				x := new(big.Int).Add(b, xT)
+				x.Sub(x, xW)
+				if x.Cmp(a) >= 0 && x.Cmp(b) <= 0 {
+					return x, nil
+				}
+				break
+			}
+			d, step := jump(yW)
+			xW.Add(xW, d)
+			yW = grp.Mul(yW, step)
+			leap()
+		}
+	}
+	return nil, ErrNotFound
+}
+
+var one = big.NewInt(1)
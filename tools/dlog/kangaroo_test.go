@@ -0,0 +1,144 @@
+package dlog
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/ysmolsky/cryptopals/tools/ec"
+)
+
+func mustInt(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("bad integer " + s)
+	}
+	return n
+}
+
+// group parameters from challenge 58
+var (
+	p58 = mustInt("11470374874925275658116663507232161402086650258453896274534991676898999262641581519101074740642369848233294239851519212341844337347119899874391456329785623")
+	g58 = mustInt("622952335333961296978159266084741085889881358738459939978290179936063635566740258555167783009058567397963466103140082647486611657350811560630587013183357")
+)
+
+func TestKangaroo20(t *testing.T) {
+	y := mustInt("7760073848032689505395005705677365876654629189298052775754597607446617558600394076764814236081991643094239886772481052254010323780165093955236429914607119")
+	b := new(big.Int).Lsh(one, 20)
+	x, err := Kangaroo(g58, y, p58, new(big.Int), b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if new(big.Int).Exp(g58, x, p58).Cmp(y) != 0 {
+		t.Errorf("g^%v != y", x)
+	}
+}
+
+func TestKangaroo40(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the 2^40 interval in short mode")
+	}
+	y := mustInt("9388897478013399550694114614498790691034187453089355259602614074132918843899833277397448144245883225611726912025846772975325932794909655215329941809013733")
+	b := new(big.Int).Lsh(one, 40)
+	var progress uint64
+	opts := &KangarooOptions[*big.Int]{
+		Progress:      func(leaps uint64) { progress = leaps },
+		ProgressEvery: 1 << 20,
+	}
+	x, err := KangarooGroup[*big.Int](ModP{p58}, g58, y, new(big.Int), b, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if new(big.Int).Exp(g58, x, p58).Cmp(y) != 0 {
+		t.Errorf("g^%v != y", x)
+	}
+	if progress == 0 {
+		t.Errorf("progress callback was never called")
+	}
+}
+
+func TestKangarooInterval(t *testing.T) {
+	// a secret in [a, b] far away from zero, found with custom jumps
+	a := new(big.Int).Lsh(one, 100)
+	width := new(big.Int).Lsh(one, 24)
+	b := new(big.Int).Add(a, width)
+	for i := 0; i < 3; i++ {
+		off, _ := rand.Int(rand.Reader, width)
+		x := new(big.Int).Add(a, off)
+		y := new(big.Int).Exp(g58, x, p58)
+		jumps := DefaultJumps(width)
+		jumps = append(jumps, big.NewInt(3), big.NewInt(5))
+		got, err := KangarooGroup[*big.Int](ModP{p58}, g58, y, a, b, &KangarooOptions[*big.Int]{Jumps: jumps})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Cmp(x) != 0 {
+			t.Errorf("Kangaroo = %v; want %v", got, x)
+		}
+	}
+}
+
+func TestKangarooCurve(t *testing.T) {
+	c := ec.Challenge59()
+	width := new(big.Int).Lsh(one, 22)
+	x, _ := rand.Int(rand.Reader, width)
+	y := c.ScalarBaseMult(x)
+	got, err := KangarooGroup[*ec.Point](Curve{c}, c.G, y, new(big.Int), width, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Cmp(x) != 0 {
+		t.Errorf("Kangaroo on the curve = %v; want %v", got, x)
+	}
+}
+
+func TestKangarooNotFound(t *testing.T) {
+	// the index lies outside of the interval
+	y := new(big.Int).Exp(g58, big.NewInt(1<<30), p58)
+	opts := &KangarooOptions[*big.Int]{Attempts: 1}
+	_, err := KangarooGroup[*big.Int](ModP{p58}, g58, y, new(big.Int), big.NewInt(1<<10), opts)
+	if err != ErrNotFound {
+		t.Errorf("Kangaroo = %v; want %v", err, ErrNotFound)
+	}
+}
+
+func TestKangarooJump(t *testing.T) {
+	width := new(big.Int).Lsh(one, 20)
+	jumps := DefaultJumps(width)
+	k := big.NewInt(int64(len(jumps)))
+	var calls int
+	opts := &KangarooOptions[*big.Int]{
+		// odd jumps picked by the residue mod k instead of Index
+		Jump: func(e *big.Int) *big.Int {
+			calls++
+			j := jumps[new(big.Int).Mod(e, k).Int64()]
+			return new(big.Int).Add(j, one)
+		},
+		Leaps: big.NewInt(1 << 14),
+	}
+	for _, x := range []int64{0, 12345, 1 << 19, 1<<20 - 1} {
+		y := new(big.Int).Exp(g58, big.NewInt(x), p58)
+		got, err := KangarooGroup[*big.Int](ModP{p58}, g58, y, new(big.Int), width, opts)
+		if err != nil {
+			t.Fatalf("x = %d: %v", x, err)
+		}
+		if got.Int64() != x {
+			t.Errorf("Kangaroo with a custom jump = %v; want %d", got, x)
+		}
+	}
+	if calls == 0 {
+		t.Errorf("custom jump was never called")
+	}
+}
+
+func TestKangarooAboveInterval(t *testing.T) {
+	// the wild kangaroo starts just past the tame one and lands in its trap,
+	// but the index is not in [0, b]
+	b := big.NewInt(1 << 16)
+	for i := int64(1); i <= 8; i++ {
+		y := new(big.Int).Exp(g58, big.NewInt(1<<16+7*i), p58)
+		if x, err := Kangaroo(g58, y, p58, new(big.Int), b); err != ErrNotFound {
+			t.Errorf("Kangaroo of g^(b + %d) = %v, %v; want %v", 7*i, x, err, ErrNotFound)
+		}
+	}
+}
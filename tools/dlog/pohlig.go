@@ -0,0 +1,50 @@
+package dlog
+
+import (
+	"errors"
+	"math/big"
+)
+
+// ErrNoLog is returned by PohligHellman when h is not a power of g.
+var ErrNoLog = errors.New("dlog: element is not a power of the generator")
+
+// PohligHellman returns x with g^x = h in grp, given the distinct prime
+// factors of a squarefree multiple n of the order of g. For every prime r it
+// finds x mod r by brute force in the subgroup of order r, between
+// g^(n/r) and h^(n/r), so the primes have to be small. The residues are
+// combined with CRT into x modulo the order of g. It returns ErrNoLog if h is
+// not in the group generated by g. Elements are compared with grp.Equal, so
+// with ModP g and h have to be reduced mod P.
+func PohligHellman[E any](grp Group[E], g, h E, primes []*big.Int) (Congruence, error) {
+	n := big.NewInt(1)
+	for _, r := range primes {
+		n.Mul(n, r)
+	}
+	identity := grp.Exp(g, new(big.Int))
+	var residues []Congruence
+	e := new(big.Int)
+	for _, r := range primes {
+		e.Quo(n, r)
+		gr, hr := grp.Exp(g, e), grp.Exp(h, e)
+		if grp.Equal(gr, identity) {
+			// r does not divide the order of g
+			if !grp.Equal(hr, identity) {
+				return Congruence{}, ErrNoLog
+			}
+			continue
+		}
+		x, ok := BruteForce(grp, gr, r, func(k E) bool { return grp.Equal(k, hr) })
+		if !ok {
+			return Congruence{}, ErrNoLog
+		}
+		residues = append(residues, Congruence{x, r})
+	}
+	res, err := CRT(residues)
+	if err != nil {
+		return Congruence{}, err
+	}
+	if !grp.Equal(grp.Exp(g, res.A), h) {
+		return Congruence{}, ErrNoLog
+	}
+	return res, nil
+}
@@ -0,0 +1,64 @@
+package dlog
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/ysmolsky/cryptopals/tools/primes"
+)
+
+// smoothPrime returns a prime p with p - 1 = 2 * 3 * ... * 29 * r for a prime
+// r and the factors of p - 1.
+func smoothPrime(t *testing.T) (*big.Int, []*big.Int) {
+	var factors []*big.Int
+	n := big.NewInt(1)
+	for _, r := range primes.Sieve(30) {
+		factors = append(factors, big.NewInt(r))
+		n.Mul(n, big.NewInt(r))
+	}
+	for _, r := range primes.Sieve(1000)[10:] {
+		p := new(big.Int).Mul(n, big.NewInt(r))
+		p.Add(p, one)
+		if p.ProbablyPrime(20) {
+			return p, append(factors, big.NewInt(r))
+		}
+	}
+	t.Fatal("no smooth prime found")
+	return nil, nil
+}
+
+func TestPohligHellman(t *testing.T) {
+	p, primes := smoothPrime(t)
+	grp := ModP{p}
+	for i := 0; i < 10; i++ {
+		g, err := rand.Int(rand.Reader, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g.Cmp(one) <= 0 {
+			continue
+		}
+		x, err := rand.Int(rand.Reader, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h := new(big.Int).Exp(g, x, p)
+		c, err := PohligHellman[*big.Int](grp, g, h, primes)
+		if err != nil {
+			t.Fatalf("g = %v: %v", g, err)
+		}
+		// c.M is the order of g, so x = c.A mod c.M
+		if new(big.Int).Exp(g, c.M, p).Cmp(one) != 0 || new(big.Int).Mod(x, c.M).Cmp(c.A) != 0 {
+			t.Errorf("g = %v, x = %v: got %v", g, x, c)
+		}
+	}
+
+	g, err := ElementOfOrder(rand.Reader, p, big.NewInt(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := PohligHellman[*big.Int](grp, g, big.NewInt(2), primes); err != ErrNoLog {
+		t.Errorf("log of an element outside the subgroup = %v; want %v", err, ErrNoLog)
+	}
+}
@@ -0,0 +1,127 @@
+package dlog
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/ysmolsky/cryptopals/tools/primes"
+)
+
+// ErrIncomplete is returned by the subgroup attack when the small factors of
+// the cofactor are not enough to recover the whole secret. The partial
+// result is still returned.
+var ErrIncomplete = errors.New("dlog: small subgroups do not cover the secret")
+
+// Oracle is the victim of the subgroup-confinement attack: it raises h to
+// its secret x and returns a message with its MAC keyed by the result, like
+// Bob does in challenge 57.
+type Oracle interface {
+	Query(h *big.Int) (msg, mac []byte, err error)
+}
+
+// MAC is the HMAC-SHA256 used by Bob in challenge 57.
+func MAC(key, msg []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(msg)
+	return h.Sum(nil)
+}
+
+// ElementOfOrder returns a random element of order r mod p, where r is a
+// prime dividing p - 1.
+func ElementOfOrder(rnd io.Reader, p, r *big.Int) (*big.Int, error) {
+	e := new(big.Int).Sub(p, one)
+	e.Div(e, r)
+	for {
+		h, err := rand.Int(rnd, p)
+		if err != nil {
+			return nil, err
+		}
+		h.Exp(h, e, p)
+		if h.Cmp(one) > 0 {
+			return h, nil
+		}
+	}
+}
+
+// BruteForce returns the k in [0, r) for which check(h^k) holds.
+func BruteForce[E any](grp Group[E], h E, r *big.Int, check func(E) bool) (*big.Int, bool) {
+	k := grp.Exp(h, new(big.Int))
+	for i := new(big.Int); i.Cmp(r) < 0; i.Add(i, one) {
+		if check(k) {
+			return i, true
+		}
+		k = grp.Mul(k, h)
+	}
+	return nil, false
+}
+
+// SubgroupAttack is the Pohlig-Hellman style attack of challenge 57 on
+// Diffie-Hellman mod P with a generator G of prime order Q, where
+// P - 1 = Q * J and J has small factors.
+type SubgroupAttack struct {
+	P, G, Q, J *big.Int
+	Oracle     Oracle
+	// MAC is used to check guesses against the oracle, MAC by default.
+	MAC func(key, msg []byte) []byte
+	// Bound limits the factors of J which are used, 2^16 by default.
+	Bound int
+	// Rand is the source for the elements sent to the oracle,
+	// crypto/rand.Reader by default.
+	Rand io.Reader
+}
+
+// Run queries the oracle with elements of small order r for every prime r
+// dividing J, recovers x mod r by brute force and combines the residues with
+// CRT. It stops as soon as the product of the moduli exceeds Q, at which
+// point the congruence determines x. If the factors run out before that, the
+// partial congruence is returned with ErrIncomplete, the rest can be found
+// with Kangaroo.
+func (a *SubgroupAttack) Run() (Congruence, error) {
+	mac, bound, rnd := a.MAC, a.Bound, a.Rand
+	if mac == nil {
+		mac = MAC
+	}
+	if bound == 0 {
+		bound = 1 << 16
+	}
+	if rnd == nil {
+		rnd = rand.Reader
+	}
+	grp := ModP{a.P}
+	var residues []Congruence
+	prod := big.NewInt(1)
+	for _, r := range primes.SmallFactors(a.J, bound) {
+		h, err := ElementOfOrder(rnd, a.P, r)
+		if err != nil {
+			return Congruence{}, err
+		}
+		msg, tag, err := a.Oracle.Query(h)
+		if err != nil {
+			return Congruence{}, err
+		}
+		x, ok := BruteForce[*big.Int](grp, h, r, func(k *big.Int) bool {
+			return bytes.Equal(mac(k.Bytes(), msg), tag)
+		})
+		if !ok {
+			continue
+		}
+		residues = append(residues, Congruence{x, r})
+		prod.Mul(prod, r)
+		if prod.Cmp(a.Q) > 0 {
+			break
+		}
+	}
+	res, err := CRT(residues)
+	if err != nil {
+		return Congruence{}, err
+	}
+	if prod.Cmp(a.Q) <= 0 {
+		return res, ErrIncomplete
+	}
+	return res, nil
+}
@@ -0,0 +1,67 @@
+package dlog
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/ysmolsky/cryptopals/tools/primes"
+)
+
+// group parameters from challenge 57
+var (
+	p57 = mustInt("7199773997391911030609999317773941274322764333428698921736339643928346453700085358802973900485592910475480089726140708102474957429903531369589969318716771")
+	g57 = mustInt("4565356397095740655436854503483826832136106141639563487732438195343690437606117828318042418238184896212352329118608100083187535033402010599512641674644143")
+	q57 = mustInt("236234353446506858198510045061214171961")
+	j57 = mustInt("30477252323177606811760882179058908038824640750610513771646768011063128035873508507547741559514324673960576895059570")
+)
+
+// bob is the oracle of challenge 57.
+type bob struct {
+	p, x    *big.Int
+	queries int
+}
+
+func (b *bob) Query(h *big.Int) ([]byte, []byte, error) {
+	b.queries++
+	k := new(big.Int).Exp(h, b.x, b.p)
+	msg := []byte("crazy flamboyant for the rap enjoyment")
+	return msg, MAC(k.Bytes(), msg), nil
+}
+
+func TestSubgroupAttack(t *testing.T) {
+	x, err := rand.Int(rand.Reader, q57)
+	if err != nil {
+		t.Fatal(err)
+	}
+	victim := &bob{p: p57, x: x}
+	attack := &SubgroupAttack{P: p57, G: g57, Q: q57, J: j57, Oracle: victim}
+	got, err := attack.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.M.Cmp(q57) <= 0 {
+		t.Errorf("modulus %v does not exceed q", got.M)
+	}
+	if got.A.Cmp(x) != 0 {
+		t.Errorf("recovered x = %v; want %v", got.A, x)
+	}
+	if victim.queries >= len(primes.SmallFactors(j57, 1<<16)) {
+		t.Errorf("the attack did not stop early, used %d queries", victim.queries)
+	}
+}
+
+func TestSubgroupAttackIncomplete(t *testing.T) {
+	x, err := rand.Int(rand.Reader, q57)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attack := &SubgroupAttack{P: p57, G: g57, Q: q57, J: j57, Oracle: &bob{p: p57, x: x}, Bound: 1000}
+	got, err := attack.Run()
+	if err != ErrIncomplete {
+		t.Fatalf("Run with a low bound = %v; want %v", err, ErrIncomplete)
+	}
+	if new(big.Int).Mod(x, got.M).Cmp(got.A) != 0 {
+		t.Errorf("partial result %v does not hold for x = %v", got, x)
+	}
+}
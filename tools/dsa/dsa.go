@@ -0,0 +1,218 @@
+// Package dsa is the DSA of challenges 43 to 45: FIPS 186-4 domain parameter
+// generation and validation, key generation, signatures with random or RFC
+// 6979 deterministic nonces and a verifier whose range checks can be turned
+// off for the parameter tampering of challenge 45. Keys and parameters can be
+// read and written in the encodings of OpenSSL and crypto/x509.
+package dsa
+
+import (
+	"crypto"
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+)
+
+var (
+	// ErrParameters is returned for domain parameters which are not valid.
+	ErrParameters = errors.New("dsa: invalid domain parameters")
+	// ErrParameterSizes is returned for sizes not allowed by FIPS 186-4.
+	ErrParameterSizes = errors.New("dsa: unsupported parameter sizes")
+	// ErrNonce is returned when a nonce is not invertible modulo Q or does
+	// not belong to a signature.
+	ErrNonce = errors.New("dsa: invalid nonce")
+)
+
+var one = big.NewInt(1)
+
+// Parameters are the domain parameters of DSA: the primes P and Q with Q
+// dividing P-1 and the generator G of the subgroup of order Q.
+type Parameters struct {
+	P, Q, G *big.Int
+}
+
+// PublicKey is a DSA public key, Y = G^X mod P.
+type PublicKey struct {
+	Parameters
+	Y *big.Int
+}
+
+// PrivateKey is a DSA private key.
+type PrivateKey struct {
+	PublicKey
+	X *big.Int // private exponent
+}
+
+// PregenParameters returns the 1024-bit parameters given in challenge 43.
+func PregenParameters() *Parameters {
+	p := new(Parameters)
+	p.P, _ = new(big.Int).SetString("800000000000000089e1855218a0e7dac38136ffafa72eda7859f2171e25e65eac698c1702578b07dc2a1076da241c76c62d374d8389ea5aeffd3226a0530cc565f3bf6b50929139ebeac04f48c3c84afb796d61e5a4f9a8fda812ab59494232c7d2b4deb50aa18ee9e132bfa85ac4374d7f9091abc3d015efc871a584471bb1", 16)
+	p.Q, _ = new(big.Int).SetString("f4f47f05794b256174bba6e9b396a7707e563c5b", 16)
+	p.G, _ = new(big.Int).SetString("5958c9d3898b224b12672c0b98e06c60df923cb8bc999d119458fef538b8fa4046c8db53039db620c094c9fa077ef389b5322a559946a71903f990f1f7e0e025e2d7f7cf494aff1a0470f5b64c36b625a097f1651fe775323556fe00b3608c887892878480e99041be601a62166ca6894bdd41a7054ec89f756ba9fc95302291", 16)
+	return p
+}
+
+// randomScalar returns a uniform integer in [1, q-1].
+func randomScalar(rnd io.Reader, q *big.Int) (*big.Int, error) {
+	if rnd == nil {
+		rnd = rand.Reader
+	}
+	k, err := rand.Int(rnd, new(big.Int).Sub(q, one))
+	if err != nil {
+		return nil, err
+	}
+	return k.Add(k, one), nil
+}
+
+// GenerateKey generates a key for params with X uniform in [1, Q-1]. G is
+// not checked, so keys for the broken parameters of challenge 45 can be
+// made. rnd defaults to crypto/rand.Reader when nil.
+func GenerateKey(rnd io.Reader, params *Parameters) (*PrivateKey, error) {
+	if params.P == nil || params.Q == nil || params.G == nil || params.Q.Cmp(one) <= 0 {
+		return nil, ErrParameters
+	}
+	x, err := randomScalar(rnd, params.Q)
+	if err != nil {
+		return nil, err
+	}
+	priv := &PrivateKey{
+		PublicKey: PublicKey{
+			Parameters: *params,
+			Y:          new(big.Int).Exp(params.G, x, params.P),
+		},
+		X: x,
+	}
+	return priv, nil
+}
+
+// hashToInt converts a hash to an integer as FIPS 186-4 and RFC 6979 do: the
+// leftmost Q.BitLen() bits of hashed.
+func hashToInt(hashed []byte, q *big.Int) *big.Int {
+	z := new(big.Int).SetBytes(hashed)
+	if excess := len(hashed)*8 - q.BitLen(); excess > 0 {
+		z.Rsh(z, uint(excess))
+	}
+	return z
+}
+
+// SignWithNonce signs hashed with the nonce k:
+// r = (G^k mod P) mod Q, s = k^-1 (z + X r) mod Q.
+// Unlike Sign it does not reject r = 0 or s = 0, which makes it the signer
+// for attacks on nonces and parameters. It returns ErrNonce if k is not
+// invertible modulo Q.
+func SignWithNonce(priv *PrivateKey, hashed []byte, k *big.Int) (r, s *big.Int, err error) {
+	q := priv.Q
+	kInv := new(big.Int).ModInverse(k, q)
+	if kInv == nil {
+		return nil, nil, ErrNonce
+	}
+	r = new(big.Int).Exp(priv.G, k, priv.P)
+	r.Mod(r, q)
+	s = new(big.Int).Mul(priv.X, r)
+	s.Add(s, hashToInt(hashed, q))
+	s.Mul(s, kInv)
+	s.Mod(s, q)
+	return r, s, nil
+}
+
+// usable reports whether G can produce a signature with r != 0.
+func (params *Parameters) usable() bool {
+	return params.P.Sign() > 0 && params.Q.Cmp(one) > 0 &&
+		params.G.Cmp(one) > 0 && params.G.Cmp(params.P) < 0
+}
+
+// Sign signs hashed with a random nonce. It returns ErrParameters if G is
+// not in (1, P), since no nonce could then give a valid signature. rnd
+// defaults to crypto/rand.Reader when nil.
+func Sign(rnd io.Reader, priv *PrivateKey, hashed []byte) (r, s *big.Int, err error) {
+	if !priv.usable() {
+		return nil, nil, ErrParameters
+	}
+	for {
+		k, err := randomScalar(rnd, priv.Q)
+		if err != nil {
+			return nil, nil, err
+		}
+		r, s, err = SignWithNonce(priv, hashed, k)
+		if err == nil && r.Sign() != 0 && s.Sign() != 0 {
+			return r, s, nil
+		}
+	}
+}
+
+// SignDeterministic signs hashed, the output of h, with the nonce derived
+// from the key and the hash by RFC 6979.
+func SignDeterministic(priv *PrivateKey, h crypto.Hash, hashed []byte) (r, s *big.Int, err error) {
+	if !priv.usable() {
+		return nil, nil, ErrParameters
+	}
+	nonces := RFC6979(priv.Q, priv.X, h, hashed)
+	for {
+		r, s, err = SignWithNonce(priv, hashed, nonces())
+		if err == nil && r.Sign() != 0 && s.Sign() != 0 {
+			return r, s, nil
+		}
+	}
+}
+
+// VerifyOptions change the checks done by VerifyWithOptions.
+type VerifyOptions struct {
+	// SkipRangeChecks turns off the checks 0 < r < Q and 0 < s < Q. With
+	// G = 0, r = 0 then verifies for any message, the first attack of
+	// challenge 45.
+	SkipRangeChecks bool
+}
+
+// Verify reports whether (r, s) is a valid signature of hashed. Like FIPS
+// 186-4 it does not check the domain parameters, so G = P+1 still admits
+// the magic signature of challenge 45.
+func Verify(pub *PublicKey, hashed []byte, r, s *big.Int) bool {
+	return VerifyWithOptions(pub, hashed, r, s, nil)
+}
+
+// VerifyWithOptions is Verify with the checks selected by opts. A nil opts
+// does all of them.
+func VerifyWithOptions(pub *PublicKey, hashed []byte, r, s *big.Int, opts *VerifyOptions) bool {
+	q := pub.Q
+	if pub.P.Sign() <= 0 || q.Sign() <= 0 {
+		return false
+	}
+	if opts == nil || !opts.SkipRangeChecks {
+		if r.Sign() <= 0 || r.Cmp(q) >= 0 || s.Sign() <= 0 || s.Cmp(q) >= 0 {
+			return false
+		}
+	}
+	w := new(big.Int).ModInverse(s, q)
+	if w == nil {
+		return false
+	}
+	// u1 = z w mod Q, u2 = r w mod Q, v = (G^u1 Y^u2 mod P) mod Q
+	u1 := new(big.Int).Mul(hashToInt(hashed, q), w)
+	u1.Mod(u1, q)
+	u2 := w.Mul(r, w)
+	u2.Mod(u2, q)
+	v := new(big.Int).Exp(pub.G, u1, pub.P)
+	v.Mul(v, new(big.Int).Exp(pub.Y, u2, pub.P))
+	v.Mod(v, pub.P)
+	v.Mod(v, q)
+	return v.Cmp(r) == 0
+}
+
+// KeyFromNonce recovers the private key from a signature and its nonce k:
+// X = (s k - z) r^-1 mod Q. It returns ErrNonce if r is not invertible or
+// the result does not match pub.Y.
+func KeyFromNonce(pub *PublicKey, hashed []byte, r, s, k *big.Int) (*PrivateKey, error) {
+	q := pub.Q
+	rInv := new(big.Int).ModInverse(r, q)
+	if rInv == nil {
+		return nil, ErrNonce
+	}
+	x := new(big.Int).Mul(s, k)
+	x.Sub(x, hashToInt(hashed, q))
+	x.Mul(x, rInv)
+	x.Mod(x, q)
+	if new(big.Int).Exp(pub.G, x, pub.P).Cmp(pub.Y) != 0 {
+		return nil, ErrNonce
+	}
+	return &PrivateKey{PublicKey: *pub, X: x}, nil
+}
@@ -0,0 +1,221 @@
+package dsa
+
+import (
+	"crypto"
+	stddsa "crypto/dsa"
+	"crypto/rand"
+	_ "crypto/sha1"
+	"crypto/sha256"
+	_ "crypto/sha512"
+	"math/big"
+	"testing"
+)
+
+func fromHex(s string) *big.Int {
+	v, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("bad hex in test: " + s)
+	}
+	return v
+}
+
+func digest(h crypto.Hash, msg string) []byte {
+	hh := h.New()
+	hh.Write([]byte(msg))
+	return hh.Sum(nil)
+}
+
+// rfc6979Key is the 1024-bit key of RFC 6979, appendix A.2.1.
+var rfc6979Key = &PrivateKey{
+	PublicKey: PublicKey{
+		Parameters: Parameters{
+			P: fromHex("86F5CA03DCFEB225063FF830A0C769B9DD9D6153AD91D7CE27F787C43278B447" +
+				"E6533B86B18BED6E8A48B784A14C252C5BE0DBF60B86D6385BD2F12FB763ED88" +
+				"73ABFD3F5BA2E0A8C0A59082EAC056935E529DAF7C610467899C77ADEDFC846C" +
+				"881870B7B19B2B58F9BE0521A17002E3BDD6B86685EE90B3D9A1B02B782B1779"),
+			Q: fromHex("996F967F6C8E388D9E28D01E205FBA957A5698B1"),
+			G: fromHex("07B0F92546150B62514BB771E2A0C0CE387F03BDA6C56B505209FF25FD3C133D" +
+				"89BBCD97E904E09114D9A7DEFDEADFC9078EA544D2E401AEECC40BB9FBBF78FD" +
+				"87995A10A1C27CB7789B594BA7EFB5C4326A9FE59A070E136DB77175464ADCA4" +
+				"17BE5DCE2F40D10A46A3A3943F26AB7FD9C0398FF8C76EE0A56826A8A88F1DBD"),
+		},
+		Y: fromHex("5DF5E01DED31D0297E274E1691C192FE5868FEF9E19A84776454B100CF16F653" +
+			"92195A38B90523E2542EE61871C0440CB87C322FC4B4D2EC5E1E7EC766E1BE8D" +
+			"4CE935437DC11C3C8FD426338933EBFE739CB3465F4D3668C5E473508253B1E6" +
+			"82F65CBDC4FAE93C2EA212390E54905A86E2223170B44EAA7DA5DD9FFCFB7F3B"),
+	},
+	X: fromHex("411602CB19A6CCC34494D79D98EF1E7ED5AF25F7"),
+}
+
+func TestRFC6979(t *testing.T) {
+	tests := []struct {
+		hash    crypto.Hash
+		msg     string
+		k, r, s string
+	}{
+		{crypto.SHA1, "sample", "7BDB6B0FF756E1BB5D53583EF979082F9AD5BD5B", "2E1A0C2562B2912CAAF89186FB0F42001585DA55", "29EFB6B0AFF2D7A68EB70CA313022253B9A88DF5"},
+		{crypto.SHA224, "sample", "562097C06782D60C3037BA7BE104774344687649", "4BC3B686AEA70145856814A6F1BB53346F02101E", "410697B92295D994D21EDD2F4ADA85566F6F94C1"},
+		{crypto.SHA256, "sample", "519BA0546D0C39202A7D34D7DFA5E760B318BCFB", "81F2F5850BE5BC123C43F71A3033E9384611C545", "4CDD914B65EB6C66A8AAAD27299BEE6B035F5E89"},
+		{crypto.SHA384, "sample", "95897CD7BBB944AA932DBC579C1C09EB6FCFC595", "07F2108557EE0E3921BC1774F1CA9B410B4CE65A", "54DF70456C86FAC10FAB47C1949AB83F2C6F7595"},
+		{crypto.SHA512, "sample", "09ECE7CA27D0F5A4DD4E556C9DF1D21D28104F8B", "16C3491F9B8C3FBBDD5E7A7B667057F0D8EE8E1B", "02C36A127A7B89EDBB72E4FFBC71DABC7D4FC69C"},
+		{crypto.SHA1, "test", "5C842DF4F9E344EE09F056838B42C7A17F4A6433", "42AB2052FD43E123F0607F115052A67DCD9C5C77", "183916B0230D45B9931491D4C6B0BD2FB4AAF088"},
+		{crypto.SHA224, "test", "4598B8EFC1A53BC8AECD58D1ABBB0C0C71E67297", "6868E9964E36C1689F6037F91F28D5F2C30610F2", "49CEC3ACDC83018C5BD2674ECAAD35B8CD22940F"},
+		{crypto.SHA256, "test", "5A67592E8128E03A417B0484410FB72C0B630E1A", "22518C127299B0F6FDC9872B282B9E70D0790812", "6837EC18F150D55DE95B5E29BE7AF5D01E4FE160"},
+		{crypto.SHA384, "test", "220156B761F6CA5E6C9F1B9CF9C24BE25F98CD89", "854CF929B58D73C3CBFDC421E8D5430CD6DB5E66", "91D0E0F53E22F898D158380676A871A157CDA622"},
+		{crypto.SHA512, "test", "65D2C2EEB175E370F28C75BFCDC028D22C7DBE9C", "8EA47E475BA8AC6F2D821DA3BD212D11A3DEB9A0", "7C670C7AD72B6C050C109E1790008097125433E8"},
+	}
+	priv := rfc6979Key
+	for _, test := range tests {
+		hashed := digest(test.hash, test.msg)
+		if k := RFC6979(priv.Q, priv.X, test.hash, hashed)(); k.Cmp(fromHex(test.k)) != 0 {
+			t.Errorf("%v %q: k = %X; want %s", test.hash, test.msg, k, test.k)
+		}
+		r, s, err := SignDeterministic(priv, test.hash, hashed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Cmp(fromHex(test.r)) != 0 || s.Cmp(fromHex(test.s)) != 0 {
+			t.Errorf("%v %q: signature = (%X, %X); want (%s, %s)", test.hash, test.msg, r, s, test.r, test.s)
+		}
+		if !Verify(&priv.PublicKey, hashed, r, s) {
+			t.Errorf("%v %q: signature does not verify", test.hash, test.msg)
+		}
+	}
+}
+
+func TestRFC6979Retry(t *testing.T) {
+	// later nonces are distinct and in [1, q-1]
+	q := big.NewInt(1<<13 + 5)
+	nonces := RFC6979(q, big.NewInt(1234), crypto.SHA256, digest(crypto.SHA256, "retry"))
+	seen := make(map[int64]bool)
+	for i := 0; i < 20; i++ {
+		k := nonces()
+		if k.Sign() <= 0 || k.Cmp(q) >= 0 {
+			t.Fatalf("nonce %v out of range", k)
+		}
+		seen[k.Int64()] = true
+	}
+	if len(seen) < 15 {
+		t.Errorf("only %d distinct nonces out of 20", len(seen))
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	priv, err := GenerateKey(nil, PregenParameters())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := &priv.PublicKey
+	hashed := digest(crypto.SHA1, "im here")
+	r, s, err := Sign(nil, priv, hashed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Verify(pub, hashed, r, s) {
+		t.Fatal("signature does not verify")
+	}
+	if Verify(pub, digest(crypto.SHA1, "im not here"), r, s) {
+		t.Error("signature verifies for another message")
+	}
+	if Verify(pub, hashed, new(big.Int).Add(r, pub.Q), s) {
+		t.Error("r + q verifies")
+	}
+	if !VerifyWithOptions(pub, hashed, r, s, &VerifyOptions{SkipRangeChecks: true}) {
+		t.Error("signature does not verify without range checks")
+	}
+
+	// crypto/dsa agrees in both directions
+	std := &stddsa.PrivateKey{
+		PublicKey: stddsa.PublicKey{
+			Parameters: stddsa.Parameters{P: priv.P, Q: priv.Q, G: priv.G},
+			Y:          priv.Y,
+		},
+		X: priv.X,
+	}
+	if !stddsa.Verify(&std.PublicKey, hashed, r, s) {
+		t.Error("crypto/dsa rejects the signature")
+	}
+	r, s, err = stddsa.Sign(rand.Reader, std, hashed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Verify(pub, hashed, r, s) {
+		t.Error("crypto/dsa signature does not verify")
+	}
+
+	// a hash longer than Q is cut to its leftmost bits
+	sum := sha256.Sum256([]byte("truncated"))
+	r, s, err = SignDeterministic(priv, crypto.SHA256, sum[:])
+	if err != nil || !Verify(pub, sum[:20], r, s) {
+		t.Errorf("SHA-256 signature does not verify with the truncated hash: %v", err)
+	}
+}
+
+func TestKeyFromNonce(t *testing.T) {
+	priv, err := GenerateKey(nil, PregenParameters())
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashed := digest(crypto.SHA1, "nonce")
+	k := big.NewInt(31337)
+	r, s, err := SignWithNonce(priv, hashed, k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := KeyFromNonce(&priv.PublicKey, hashed, r, s, k)
+	if err != nil || got.X.Cmp(priv.X) != 0 {
+		t.Errorf("KeyFromNonce = %v, %v", got, err)
+	}
+	if _, err := KeyFromNonce(&priv.PublicKey, hashed, r, s, k.Add(k, one)); err != ErrNonce {
+		t.Errorf("KeyFromNonce with a wrong nonce = %v; want %v", err, ErrNonce)
+	}
+	if _, _, err := SignWithNonce(priv, hashed, priv.Q); err != ErrNonce {
+		t.Errorf("SignWithNonce with k = q: %v; want %v", err, ErrNonce)
+	}
+}
+
+// The two parameter substitutions of challenge 45.
+func TestChallenge45(t *testing.T) {
+	hello := digest(crypto.SHA1, "Hello, world")
+	bye := digest(crypto.SHA1, "Goodbye, world")
+	skip := &VerifyOptions{SkipRangeChecks: true}
+
+	params := PregenParameters()
+	params.G = big.NewInt(0)
+	priv, err := GenerateKey(nil, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := Sign(nil, priv, hello); err != ErrParameters {
+		t.Errorf("Sign with G = 0: %v; want %v", err, ErrParameters)
+	}
+	r, s, err := SignWithNonce(priv, hello, big.NewInt(12345))
+	if err != nil || r.Sign() != 0 {
+		t.Fatalf("SignWithNonce with G = 0 = %v, %v, %v", r, s, err)
+	}
+	if Verify(&priv.PublicKey, bye, r, s) {
+		t.Error("r = 0 passes the range checks")
+	}
+	if !VerifyWithOptions(&priv.PublicKey, bye, r, s, skip) {
+		t.Error("G = 0 signature is rejected without range checks")
+	}
+
+	// with G = P+1, r = (y^z mod p) mod q and s = r / z verify for anything
+	priv, err = GenerateKey(nil, PregenParameters())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := &priv.PublicKey
+	pub.G = new(big.Int).Add(pub.P, one)
+	z := big.NewInt(666)
+	r = new(big.Int).Exp(pub.Y, z, pub.P)
+	r.Mod(r, pub.Q)
+	s = new(big.Int).ModInverse(z, pub.Q)
+	s.Mul(s, r)
+	s.Mod(s, pub.Q)
+	for _, hashed := range [][]byte{hello, bye} {
+		if !Verify(pub, hashed, r, s) {
+			t.Errorf("magic signature does not verify %x", hashed)
+		}
+	}
+}
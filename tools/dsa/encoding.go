@@ -0,0 +1,259 @@
+package dsa
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"math/big"
+)
+
+var (
+	// ErrEncoding is returned for malformed DER or PEM encodings.
+	ErrEncoding = errors.New("dsa: malformed encoding")
+	// ErrAlgorithm is returned for PKCS #8 and SubjectPublicKeyInfo
+	// encodings of keys other than DSA.
+	ErrAlgorithm = errors.New("dsa: not a DSA key")
+)
+
+// oidDSA is id-dsa from RFC 3279.
+var oidDSA = asn1.ObjectIdentifier{1, 2, 840, 10040, 4, 1}
+
+// dssParms is Dss-Parms of RFC 3279.
+type dssParms struct {
+	P, Q, G *big.Int
+}
+
+// opensslPrivateKey is the traditional OpenSSL "DSA PRIVATE KEY".
+type opensslPrivateKey struct {
+	Version       int
+	P, Q, G, Y, X *big.Int
+}
+
+type pkcs8 struct {
+	Version    int
+	Algo       pkix.AlgorithmIdentifier
+	PrivateKey []byte
+	// attributes are ignored
+	Attributes []asn1.RawValue `asn1:"optional,tag:0"`
+}
+
+type spki struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// unmarshal parses der into v and rejects trailing data.
+func unmarshal(der []byte, v interface{}) error {
+	rest, err := asn1.Unmarshal(der, v)
+	if err != nil || len(rest) != 0 {
+		return ErrEncoding
+	}
+	return nil
+}
+
+func positive(vs ...*big.Int) bool {
+	for _, v := range vs {
+		if v == nil || v.Sign() <= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// MarshalParameters returns the Dss-Parms DER encoding of params.
+func MarshalParameters(params *Parameters) []byte {
+	der, _ := asn1.Marshal(dssParms{params.P, params.Q, params.G})
+	return der
+}
+
+// ParseParameters parses Dss-Parms.
+func ParseParameters(der []byte) (*Parameters, error) {
+	var p dssParms
+	if err := unmarshal(der, &p); err != nil {
+		return nil, err
+	}
+	if !positive(p.P, p.Q, p.G) {
+		return nil, ErrEncoding
+	}
+	return &Parameters{P: p.P, Q: p.Q, G: p.G}, nil
+}
+
+// algorithm returns the AlgorithmIdentifier of DSA keys with the domain
+// parameters.
+func algorithm(params *Parameters) pkix.AlgorithmIdentifier {
+	return pkix.AlgorithmIdentifier{
+		Algorithm:  oidDSA,
+		Parameters: asn1.RawValue{FullBytes: MarshalParameters(params)},
+	}
+}
+
+// parseAlgorithm returns the domain parameters of a DSA AlgorithmIdentifier.
+func parseAlgorithm(algo pkix.AlgorithmIdentifier) (*Parameters, error) {
+	if !algo.Algorithm.Equal(oidDSA) {
+		return nil, ErrAlgorithm
+	}
+	return ParseParameters(algo.Parameters.FullBytes)
+}
+
+// MarshalPKIXPublicKey returns the SubjectPublicKeyInfo DER encoding of pub,
+// the format of "PUBLIC KEY" PEM blocks.
+func MarshalPKIXPublicKey(pub *PublicKey) []byte {
+	y, _ := asn1.Marshal(pub.Y)
+	der, _ := asn1.Marshal(spki{
+		Algorithm: algorithm(&pub.Parameters),
+		PublicKey: asn1.BitString{Bytes: y, BitLength: 8 * len(y)},
+	})
+	return der
+}
+
+// ParsePKIXPublicKey parses a SubjectPublicKeyInfo holding a DSA key with
+// its domain parameters. It returns ErrAlgorithm for other kinds of keys.
+func ParsePKIXPublicKey(der []byte) (*PublicKey, error) {
+	var k spki
+	if err := unmarshal(der, &k); err != nil {
+		return nil, err
+	}
+	params, err := parseAlgorithm(k.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	y := new(big.Int)
+	if err := unmarshal(k.PublicKey.RightAlign(), &y); err != nil {
+		return nil, err
+	}
+	if !positive(y) {
+		return nil, ErrEncoding
+	}
+	return &PublicKey{Parameters: *params, Y: y}, nil
+}
+
+// MarshalPrivateKey returns the traditional OpenSSL DER encoding of priv,
+// a sequence of 0, P, Q, G, Y and X.
+func MarshalPrivateKey(priv *PrivateKey) []byte {
+	der, _ := asn1.Marshal(opensslPrivateKey{
+		P: priv.P, Q: priv.Q, G: priv.G, Y: priv.Y, X: priv.X,
+	})
+	return der
+}
+
+// ParsePrivateKey parses a traditional OpenSSL DSA private key.
+func ParsePrivateKey(der []byte) (*PrivateKey, error) {
+	var k opensslPrivateKey
+	if err := unmarshal(der, &k); err != nil {
+		return nil, err
+	}
+	if k.Version != 0 || !positive(k.P, k.Q, k.G, k.Y, k.X) {
+		return nil, ErrEncoding
+	}
+	return &PrivateKey{
+		PublicKey: PublicKey{Parameters: Parameters{P: k.P, Q: k.Q, G: k.G}, Y: k.Y},
+		X:         k.X,
+	}, nil
+}
+
+// MarshalPKCS8PrivateKey returns the PKCS #8 PrivateKeyInfo DER encoding of
+// priv, which holds the domain parameters and X but not Y.
+func MarshalPKCS8PrivateKey(priv *PrivateKey) []byte {
+	x, _ := asn1.Marshal(priv.X)
+	der, _ := asn1.Marshal(pkcs8{Algo: algorithm(&priv.Parameters), PrivateKey: x})
+	return der
+}
+
+// ParsePKCS8PrivateKey parses an unencrypted PKCS #8 PrivateKeyInfo holding
+// a DSA key. Y is recomputed from X. It returns ErrAlgorithm for other kinds
+// of keys.
+func ParsePKCS8PrivateKey(der []byte) (*PrivateKey, error) {
+	var k pkcs8
+	if err := unmarshal(der, &k); err != nil {
+		return nil, err
+	}
+	params, err := parseAlgorithm(k.Algo)
+	if err != nil {
+		return nil, err
+	}
+	x := new(big.Int)
+	if err := unmarshal(k.PrivateKey, &x); err != nil {
+		return nil, err
+	}
+	if !positive(x) {
+		return nil, ErrEncoding
+	}
+	y := new(big.Int).Exp(params.G, x, params.P)
+	return &PrivateKey{PublicKey: PublicKey{Parameters: *params, Y: y}, X: x}, nil
+}
+
+// PEM block types.
+const (
+	pemParameters = "DSA PARAMETERS"
+	pemPrivate    = "DSA PRIVATE KEY"
+	pemPKCS8      = "PRIVATE KEY"
+	pemPKIX       = "PUBLIC KEY"
+)
+
+// EncodeParametersPEM returns params as a "DSA PARAMETERS" PEM block, the
+// output of openssl dsaparam.
+func EncodeParametersPEM(params *Parameters) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: pemParameters, Bytes: MarshalParameters(params)})
+}
+
+// EncodePrivateKeyPEM returns priv as a traditional "DSA PRIVATE KEY" PEM
+// block.
+func EncodePrivateKeyPEM(priv *PrivateKey) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: pemPrivate, Bytes: MarshalPrivateKey(priv)})
+}
+
+// EncodePublicKeyPEM returns pub as a SubjectPublicKeyInfo "PUBLIC KEY" PEM
+// block.
+func EncodePublicKeyPEM(pub *PublicKey) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: pemPKIX, Bytes: MarshalPKIXPublicKey(pub)})
+}
+
+// decodePEM returns the first PEM block of data.
+func decodePEM(data []byte) (*pem.Block, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrEncoding
+	}
+	return block, nil
+}
+
+// ParseParametersPEM parses a "DSA PARAMETERS" PEM block.
+func ParseParametersPEM(data []byte) (*Parameters, error) {
+	block, err := decodePEM(data)
+	if err != nil {
+		return nil, err
+	}
+	if block.Type != pemParameters {
+		return nil, ErrEncoding
+	}
+	return ParseParameters(block.Bytes)
+}
+
+// ParsePrivateKeyPEM parses the first PEM block of data, which is either a
+// traditional "DSA PRIVATE KEY" or a PKCS #8 "PRIVATE KEY".
+func ParsePrivateKeyPEM(data []byte) (*PrivateKey, error) {
+	block, err := decodePEM(data)
+	if err != nil {
+		return nil, err
+	}
+	switch block.Type {
+	case pemPrivate:
+		return ParsePrivateKey(block.Bytes)
+	case pemPKCS8:
+		return ParsePKCS8PrivateKey(block.Bytes)
+	}
+	return nil, ErrEncoding
+}
+
+// ParsePublicKeyPEM parses a SubjectPublicKeyInfo "PUBLIC KEY" PEM block.
+func ParsePublicKeyPEM(data []byte) (*PublicKey, error) {
+	block, err := decodePEM(data)
+	if err != nil {
+		return nil, err
+	}
+	if block.Type != pemPKIX {
+		return nil, ErrEncoding
+	}
+	return ParsePKIXPublicKey(block.Bytes)
+}
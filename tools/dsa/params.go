@@ -0,0 +1,186 @@
+package dsa
+
+import (
+	"crypto"
+	"crypto/rand"
+	_ "crypto/sha256" // SHA-256 generates the parameters
+	"io"
+	"math/big"
+)
+
+// ParameterSizes are the lengths L of P and N of Q allowed by FIPS 186-4.
+type ParameterSizes int
+
+const (
+	L1024N160 ParameterSizes = iota
+	L2048N224
+	L2048N256
+	L3072N256
+)
+
+func (sizes ParameterSizes) bits() (L, N int, err error) {
+	switch sizes {
+	case L1024N160:
+		return 1024, 160, nil
+	case L2048N224:
+		return 2048, 224, nil
+	case L2048N256:
+		return 2048, 256, nil
+	case L3072N256:
+		return 3072, 256, nil
+	}
+	return 0, 0, ErrParameterSizes
+}
+
+// sizesOf returns the ParameterSizes of params.
+func sizesOf(params *Parameters) (ParameterSizes, error) {
+	L, N := params.P.BitLen(), params.Q.BitLen()
+	for sizes := L1024N160; sizes <= L3072N256; sizes++ {
+		if l, n, _ := sizes.bits(); l == L && n == N {
+			return sizes, nil
+		}
+	}
+	return 0, ErrParameterSizes
+}
+
+// Seed is the evidence that P and Q were generated from a seed with the
+// hash function Hash as in FIPS 186-4 appendix A.1.1.2, which lets anyone
+// check that the primes were not chosen with a hidden structure.
+type Seed struct {
+	Hash    crypto.Hash
+	Value   []byte // domain_parameter_seed
+	Counter int
+}
+
+// primeRounds is the number of Miller-Rabin rounds on top of the
+// Baillie-PSW test of ProbablyPrime, more than table C.1 asks for.
+const primeRounds = 64
+
+// GenerateParameters generates domain parameters of the given sizes: P and Q
+// with the probable prime construction of FIPS 186-4 appendix A.1.1.2 from a
+// random seed and SHA-256, and G with the unverifiable construction of
+// appendix A.2.1. rnd defaults to crypto/rand.Reader when nil.
+func GenerateParameters(rnd io.Reader, sizes ParameterSizes) (*Parameters, *Seed, error) {
+	if rnd == nil {
+		rnd = rand.Reader
+	}
+	_, N, err := sizes.bits()
+	if err != nil {
+		return nil, nil, err
+	}
+	for {
+		seed := &Seed{Hash: crypto.SHA256, Value: make([]byte, N/8)}
+		if _, err := io.ReadFull(rnd, seed.Value); err != nil {
+			return nil, nil, err
+		}
+		p, q, counter := primesFromSeed(sizes, seed.Hash, seed.Value)
+		if p == nil {
+			continue
+		}
+		seed.Counter = counter
+		params := &Parameters{P: p, Q: q, G: generator(p, q)}
+		return params, seed, nil
+	}
+}
+
+// primesFromSeed runs steps 6 to 11 of appendix A.1.1.2 for one seed. It
+// returns nil if q is not prime or no p is found for it.
+func primesFromSeed(sizes ParameterSizes, h crypto.Hash, seed []byte) (p, q *big.Int, counter int) {
+	L, N, _ := sizes.bits()
+	outlen := h.Size() * 8
+	n := (L+outlen-1)/outlen - 1
+	b := L - 1 - n*outlen
+	hash := func(x *big.Int) *big.Int {
+		hh := h.New()
+		hh.Write(x.FillBytes(make([]byte, len(seed))))
+		return new(big.Int).SetBytes(hh.Sum(nil))
+	}
+	seedInt := new(big.Int).SetBytes(seed)
+	mod := new(big.Int).Lsh(one, uint(len(seed)*8))
+
+	// U = Hash(seed) mod 2^(N-1), q = 2^(N-1) + U + 1 - (U mod 2)
+	top := new(big.Int).Lsh(one, uint(N-1))
+	q = new(big.Int).Mod(hash(seedInt), top)
+	q.Add(q, top)
+	q.SetBit(q, 0, 1)
+	if !q.ProbablyPrime(primeRounds) {
+		return nil, nil, 0
+	}
+
+	twoQ := new(big.Int).Lsh(q, 1)
+	pTop := new(big.Int).Lsh(one, uint(L-1))
+	bMask := new(big.Int).Sub(new(big.Int).Lsh(one, uint(b)), one)
+	offset := 1
+	x, c, v := new(big.Int), new(big.Int), new(big.Int)
+	for counter = 0; counter < 4*L; counter++ {
+		// W = V_0 + V_1 2^outlen + ... + (V_n mod 2^b) 2^(n outlen)
+		w := new(big.Int)
+		for j := 0; j <= n; j++ {
+			v.Add(seedInt, big.NewInt(int64(offset+j)))
+			v = hash(v.Mod(v, mod))
+			if j == n {
+				v.And(v, bMask)
+			}
+			w.Add(w, v.Lsh(v, uint(j*outlen)))
+		}
+		// X = W + 2^(L-1), p = X - (X mod 2q - 1)
+		x.Add(w, pTop)
+		c.Mod(x, twoQ)
+		p = new(big.Int).Sub(x, c.Sub(c, one))
+		if p.Cmp(pTop) >= 0 && p.ProbablyPrime(primeRounds) {
+			return p, q, counter
+		}
+		offset += n + 1
+	}
+	return nil, nil, 0
+}
+
+// generator returns G = h^((P-1)/Q) mod P for the first h = 2, 3, ... with
+// G != 1, appendix A.2.1.
+func generator(p, q *big.Int) *big.Int {
+	e := new(big.Int).Sub(p, one)
+	e.Div(e, q)
+	g := new(big.Int)
+	for h := big.NewInt(2); ; h.Add(h, one) {
+		if g.Exp(h, e, p).Cmp(one) != 0 {
+			return g
+		}
+	}
+}
+
+// ValidateParameters checks domain parameters as FIPS 186-4 does: the sizes
+// are allowed, P and Q are prime, Q divides P-1 and G generates the subgroup
+// of order Q (appendix A.2.2). With a seed it also regenerates P and Q from
+// it (appendix A.1.1.3). It returns ErrParameterSizes or ErrParameters.
+func ValidateParameters(params *Parameters, seed *Seed) error {
+	p, q, g := params.P, params.Q, params.G
+	if p == nil || q == nil || g == nil {
+		return ErrParameters
+	}
+	sizes, err := sizesOf(params)
+	if err != nil {
+		return err
+	}
+	if !q.ProbablyPrime(primeRounds) || !p.ProbablyPrime(primeRounds) {
+		return ErrParameters
+	}
+	if new(big.Int).Mod(new(big.Int).Sub(p, one), q).Sign() != 0 {
+		return ErrParameters
+	}
+	// 2 <= G <= P-1 and G^Q = 1 mod P
+	if g.Cmp(one) <= 0 || g.Cmp(p) >= 0 || new(big.Int).Exp(g, q, p).Cmp(one) != 0 {
+		return ErrParameters
+	}
+	if seed == nil {
+		return nil
+	}
+	_, N, _ := sizes.bits()
+	if !seed.Hash.Available() || seed.Hash.Size()*8 < N || len(seed.Value)*8 < N {
+		return ErrParameters
+	}
+	p2, q2, counter := primesFromSeed(sizes, seed.Hash, seed.Value)
+	if p2 == nil || counter != seed.Counter || p2.Cmp(p) != 0 || q2.Cmp(q) != 0 {
+		return ErrParameters
+	}
+	return nil
+}
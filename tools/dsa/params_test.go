@@ -0,0 +1,80 @@
+package dsa
+
+import (
+	"crypto"
+	"math/big"
+	"testing"
+)
+
+func TestGenerateParameters(t *testing.T) {
+	sizes := []ParameterSizes{L1024N160, L2048N224}
+	if testing.Short() {
+		sizes = sizes[:1]
+	}
+	for _, size := range sizes {
+		params, seed, err := GenerateParameters(nil, size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		L, N, _ := size.bits()
+		if params.P.BitLen() != L || params.Q.BitLen() != N {
+			t.Fatalf("%d/%d: got %d and %d bits", L, N, params.P.BitLen(), params.Q.BitLen())
+		}
+		if err := ValidateParameters(params, seed); err != nil {
+			t.Fatalf("%d/%d: %v", L, N, err)
+		}
+		bad := *seed
+		bad.Counter++
+		if err := ValidateParameters(params, &bad); err != ErrParameters {
+			t.Errorf("%d/%d: wrong counter: %v; want %v", L, N, err, ErrParameters)
+		}
+		bad = *seed
+		bad.Value = append([]byte{}, seed.Value...)
+		bad.Value[0] ^= 1
+		if err := ValidateParameters(params, &bad); err != ErrParameters {
+			t.Errorf("%d/%d: wrong seed: %v; want %v", L, N, err, ErrParameters)
+		}
+
+		priv, err := GenerateKey(nil, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashed := digest(crypto.SHA256, "generated")
+		r, s, err := SignDeterministic(priv, crypto.SHA256, hashed)
+		if err != nil || !Verify(&priv.PublicKey, hashed, r, s) {
+			t.Errorf("%d/%d: signature does not verify: %v", L, N, err)
+		}
+	}
+}
+
+func TestValidateParameters(t *testing.T) {
+	if err := ValidateParameters(PregenParameters(), nil); err != nil {
+		t.Errorf("challenge 43 parameters: %v", err)
+	}
+	if err := ValidateParameters(&rfc6979Key.Parameters, nil); err != nil {
+		t.Errorf("RFC 6979 parameters: %v", err)
+	}
+	for name, tamper := range map[string]func(p *Parameters){
+		"G = 0":     func(p *Parameters) { p.G = big.NewInt(0) },
+		"G = 1":     func(p *Parameters) { p.G = big.NewInt(1) },
+		"G = P+1":   func(p *Parameters) { p.G = new(big.Int).Add(p.P, one) },
+		"G = 2":     func(p *Parameters) { p.G = big.NewInt(2) },
+		"P + 2":     func(p *Parameters) { p.P = new(big.Int).Add(p.P, big.NewInt(2)) },
+		"Q - 2":     func(p *Parameters) { p.Q = new(big.Int).Sub(p.Q, big.NewInt(2)) },
+		"missing G": func(p *Parameters) { p.G = nil },
+	} {
+		params := PregenParameters()
+		tamper(params)
+		if err := ValidateParameters(params, nil); err != ErrParameters {
+			t.Errorf("%s: %v; want %v", name, err, ErrParameters)
+		}
+	}
+	params := PregenParameters()
+	params.Q = new(big.Int).Lsh(params.Q, 1)
+	if err := ValidateParameters(params, nil); err != ErrParameterSizes {
+		t.Errorf("161-bit Q: %v; want %v", err, ErrParameterSizes)
+	}
+	if _, _, err := GenerateParameters(nil, ParameterSizes(7)); err != ErrParameterSizes {
+		t.Errorf("GenerateParameters with unknown sizes: %v; want %v", err, ErrParameterSizes)
+	}
+}
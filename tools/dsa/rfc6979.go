@@ -0,0 +1,58 @@
+package dsa
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"math/big"
+)
+
+// RFC6979 returns the deterministic nonces of RFC 6979, section 3.2, for the
+// private key x modulo q and hashed, the output of h. The first nonce is the
+// one to sign with, the following ones replace it if it gives r = 0 or
+// s = 0. With q the order of the base point the nonces are those of ECDSA.
+// The hash function h has to be linked into the binary.
+func RFC6979(q, x *big.Int, h crypto.Hash, hashed []byte) func() *big.Int {
+	rolen := (q.BitLen() + 7) / 8
+	mac := func(key []byte, data ...[]byte) []byte {
+		m := hmac.New(h.New, key)
+		for _, d := range data {
+			m.Write(d)
+		}
+		return m.Sum(nil)
+	}
+
+	// bits2octets(h1) is the hash reduced modulo q
+	z := hashToInt(hashed, q)
+	if z.Cmp(q) >= 0 {
+		z.Sub(z, q)
+	}
+	seed := append(x.FillBytes(make([]byte, rolen)), z.FillBytes(make([]byte, rolen))...)
+
+	v := make([]byte, h.Size())
+	for i := range v {
+		v[i] = 0x01
+	}
+	k := make([]byte, h.Size())
+	k = mac(k, v, []byte{0x00}, seed)
+	v = mac(k, v)
+	k = mac(k, v, []byte{0x01}, seed)
+	v = mac(k, v)
+
+	return func() *big.Int {
+		for {
+			var t []byte
+			for len(t) < rolen {
+				v = mac(k, v)
+				t = append(t, v...)
+			}
+			nonce := hashToInt(t[:rolen], q)
+			// update the state for the next nonce or the next try
+			valid := nonce.Sign() > 0 && nonce.Cmp(q) < 0
+			k = mac(k, v, []byte{0x00})
+			v = mac(k, v)
+			if valid {
+				return nonce
+			}
+		}
+	}
+}
@@ -0,0 +1,296 @@
+// Package ec implements affine arithmetic on short Weierstrass curves
+//
+//	y^2 = x^3 + a*x + b mod p
+//
+// over prime fields. It is written for clarity, not speed, and makes no
+// attempt to be constant time.
+package ec
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+var (
+	zero  = big.NewInt(0)
+	one   = big.NewInt(1)
+	two   = big.NewInt(2)
+	three = big.NewInt(3)
+)
+
+// ErrNoPoint is returned when no point of the requested order exists.
+var ErrNoPoint = errors.New("ec: no point of such order")
+
+// Point is an affine point. The point at infinity O, the identity of the
+// group, is the Point with nil coordinates.
+type Point struct {
+	X, Y *big.Int
+}
+
+// Infinity returns the point at infinity.
+func Infinity() *Point {
+	return &Point{}
+}
+
+// NewPoint returns the point (x, y).
+func NewPoint(x, y *big.Int) *Point {
+	return &Point{new(big.Int).Set(x), new(big.Int).Set(y)}
+}
+
+// IsInfinity reports whether p is the point at infinity.
+func (p *Point) IsInfinity() bool {
+	return p.X == nil
+}
+
+// Equal reports whether p and q are the same point.
+func (p *Point) Equal(q *Point) bool {
+	if p.IsInfinity() || q.IsInfinity() {
+		return p.IsInfinity() == q.IsInfinity()
+	}
+	return p.X.Cmp(q.X) == 0 && p.Y.Cmp(q.Y) == 0
+}
+
+func (p *Point) String() string {
+	if p.IsInfinity() {
+		return "O"
+	}
+	return fmt.Sprintf("(%v, %v)", p.X, p.Y)
+}
+
+// Curve is the curve y^2 = x^3 + A*x + B over GF(P) with an optional base
+// point G of order N. Order is the number of points on the curve, nil when
+// it is unknown.
+type Curve struct {
+	A, B, P *big.Int
+	G       *Point
+	N       *big.Int
+	Order   *big.Int
+}
+
+func mustInt(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("ec: bad integer " + s)
+	}
+	return n
+}
+
+// Challenge59 returns the curve y^2 = x^3 - 95051*x + 11279326 from
+// challenge 59 with its base point of prime order.
+func Challenge59() *Curve {
+	return &Curve{
+		A:     big.NewInt(-95051),
+		B:     big.NewInt(11279326),
+		P:     mustInt("233970423115425145524320034830162017933"),
+		G:     NewPoint(big.NewInt(182), mustInt("85518893674295321206118380980485522083")),
+		N:     mustInt("29246302889428143187362802287225875743"),
+		Order: mustInt("233970423115425145498902418297807005944"),
+	}
+}
+
+// WithB returns a copy of c with a different B and the given group order,
+// without a base point. Since B does not take part in the group law, the
+// points of the new curve can be fed to code that only knows c.
+func (c *Curve) WithB(b, order *big.Int) *Curve {
+	return &Curve{A: c.A, B: b, P: c.P, Order: order}
+}
+
+func (c *Curve) mod(x *big.Int) *big.Int {
+	return x.Mod(x, c.P)
+}
+
+// rhs returns x^3 + A*x + B mod P.
+func (c *Curve) rhs(x *big.Int) *big.Int {
+	r := new(big.Int).Exp(x, three, c.P)
+	ax := new(big.Int).Mul(c.A, x)
+	r.Add(r, ax)
+	r.Add(r, c.B)
+	return c.mod(r)
+}
+
+// IsOnCurve reports whether p satisfies the curve equation. The point at
+// infinity is on every curve.
+func (c *Curve) IsOnCurve(p *Point) bool {
+	if p.IsInfinity() {
+		return true
+	}
+	if p.X.Sign() < 0 || p.X.Cmp(c.P) >= 0 || p.Y.Sign() < 0 || p.Y.Cmp(c.P) >= 0 {
+		return false
+	}
+	y2 := new(big.Int).Mul(p.Y, p.Y)
+	return c.mod(y2).Cmp(c.rhs(p.X)) == 0
+}
+
+// Neg returns -p = (x, -y).
+func (c *Curve) Neg(p *Point) *Point {
+	if p.IsInfinity() {
+		return Infinity()
+	}
+	y := new(big.Int).Neg(p.Y)
+	return &Point{new(big.Int).Set(p.X), c.mod(y)}
+}
+
+// Add returns p1 + p2. Like the challenge describes, B is never used, so
+// points which are not on c are happily added on another curve.
+func (c *Curve) Add(p1, p2 *Point) *Point {
+	if p1.IsInfinity() {
+		return p2
+	}
+	if p2.IsInfinity() {
+		return p1
+	}
+	if p1.Equal(c.Neg(p2)) {
+		return Infinity()
+	}
+	m := new(big.Int)
+	if p1.Equal(p2) {
+		// m = (3*x1^2 + a) / 2*y1
+		m.Mul(p1.X, p1.X)
+		m.Mul(m, three)
+		m.Add(m, c.A)
+		d := new(big.Int).Mul(two, p1.Y)
+		m.Mul(m, d.ModInverse(c.mod(d), c.P))
+	} else {
+		// m = (y2 - y1) / (x2 - x1)
+		m.Sub(p2.Y, p1.Y)
+		d := new(big.Int).Sub(p2.X, p1.X)
+		m.Mul(m, d.ModInverse(c.mod(d), c.P))
+	}
+	c.mod(m)
+	x3 := new(big.Int).Mul(m, m)
+	x3.Sub(x3, p1.X)
+	x3.Sub(x3, p2.X)
+	c.mod(x3)
+	y3 := new(big.Int).Sub(p1.X, x3)
+	y3.Mul(y3, m)
+	y3.Sub(y3, p1.Y)
+	c.mod(y3)
+	return &Point{x3, y3}
+}
+
+// Double returns 2*p.
+func (c *Curve) Double(p *Point) *Point {
+	return c.Add(p, p)
+}
+
+// ScalarMult returns k*p using double-and-add. Negative k multiply -p.
+func (c *Curve) ScalarMult(p *Point, k *big.Int) *Point {
+	if k.Sign() < 0 {
+		return c.ScalarMult(c.Neg(p), new(big.Int).Neg(k))
+	}
+	res := Infinity()
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		res = c.Double(res)
+		if k.Bit(i) == 1 {
+			res = c.Add(res, p)
+		}
+	}
+	return res
+}
+
+// ScalarBaseMult returns k*G.
+func (c *Curve) ScalarBaseMult(k *big.Int) *Point {
+	return c.ScalarMult(c.G, k)
+}
+
+// GenerateKey returns a random secret in [1, N) and the matching public
+// point.
+func (c *Curve) GenerateKey(rnd io.Reader) (*big.Int, *Point, error) {
+	max := new(big.Int).Sub(c.N, one)
+	d, err := rand.Int(rnd, max)
+	if err != nil {
+		return nil, nil, err
+	}
+	d.Add(d, one)
+	return d, c.ScalarBaseMult(d), nil
+}
+
+// RandomPoint returns a random point on c other than infinity by picking
+// random x until x^3 + A*x + B has a square root.
+func (c *Curve) RandomPoint(rnd io.Reader) (*Point, error) {
+	for {
+		x, err := rand.Int(rnd, c.P)
+		if err != nil {
+			return nil, err
+		}
+		y := new(big.Int).ModSqrt(c.rhs(x), c.P)
+		if y == nil {
+			continue
+		}
+		return &Point{x, y}, nil
+	}
+}
+
+// PointOfOrder returns a random point of prime order r, which should divide
+// c.Order. It picks random points, multiplies them by the part of Order
+// coprime to r and then by r until one more multiplication would give
+// infinity.
+func (c *Curve) PointOfOrder(rnd io.Reader, r *big.Int) (*Point, error) {
+	cof, rem := new(big.Int), new(big.Int)
+	cof.QuoRem(c.Order, r, rem)
+	if rem.Sign() != 0 {
+		return nil, ErrNoPoint
+	}
+	q := new(big.Int)
+	for {
+		q.QuoRem(cof, r, rem)
+		if rem.Sign() != 0 {
+			break
+		}
+		cof.Set(q)
+	}
+	for i := 0; i < 100; i++ {
+		p, err := c.RandomPoint(rnd)
+		if err != nil {
+			return nil, err
+		}
+		p = c.ScalarMult(p, cof)
+		if p.IsInfinity() {
+			continue
+		}
+		for {
+			next := c.ScalarMult(p, r)
+			if next.IsInfinity() {
+				return p, nil
+			}
+			p = next
+		}
+	}
+	return nil, ErrNoPoint
+}
+
+// PointOrder returns the order of p given a multiple n of it, such as the
+// group order, and the prime factors of n. The part of n not covered by
+// primes is treated as one more prime, so passing the small factors of n is
+// enough when its remaining cofactor is prime.
+func (c *Curve) PointOrder(p *Point, n *big.Int, primes []*big.Int) *big.Int {
+	rest := new(big.Int).Set(n)
+	q, m := new(big.Int), new(big.Int)
+	for _, r := range primes {
+		for {
+			q.QuoRem(rest, r, m)
+			if m.Sign() != 0 {
+				break
+			}
+			rest.Set(q)
+		}
+	}
+	if rest.Cmp(one) > 0 {
+		primes = append(primes[:len(primes):len(primes)], rest)
+	}
+
+	order := new(big.Int).Set(n)
+	for _, r := range primes {
+		for {
+			q.QuoRem(order, r, m)
+			if m.Sign() != 0 || !c.ScalarMult(p, q).IsInfinity() {
+				break
+			}
+			order.Set(q)
+		}
+	}
+	return order
+}
@@ -0,0 +1,198 @@
+package ec
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/ysmolsky/cryptopals/tools/primes"
+)
+
+func TestChallenge59Base(t *testing.T) {
+	c := Challenge59()
+	if !c.IsOnCurve(c.G) {
+		t.Fatalf("G = %v is not on the curve", c.G)
+	}
+	if p := c.ScalarBaseMult(c.N); !p.IsInfinity() {
+		t.Errorf("N*G = %v; want O", p)
+	}
+	nm1 := new(big.Int).Sub(c.N, one)
+	if p := c.ScalarBaseMult(nm1); !p.Equal(c.Neg(c.G)) {
+		t.Errorf("(N-1)*G = %v; want -G", p)
+	}
+	if o := c.PointOrder(c.G, c.Order, primes.SmallFactors(c.Order, 1000)); o.Cmp(c.N) != 0 {
+		t.Errorf("order of G = %v; want %v", o, c.N)
+	}
+	p, err := c.RandomPoint(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.IsOnCurve(p) {
+		t.Fatalf("random point %v is not on the curve", p)
+	}
+	if q := c.ScalarMult(p, c.Order); !q.IsInfinity() {
+		t.Errorf("Order*P = %v; want O", q)
+	}
+}
+
+func TestGroupLaw(t *testing.T) {
+	c := Challenge59()
+	a, _ := rand.Int(rand.Reader, c.N)
+	b, _ := rand.Int(rand.Reader, c.N)
+	P, Q := c.ScalarBaseMult(a), c.ScalarBaseMult(b)
+	R, _ := c.RandomPoint(rand.Reader)
+
+	if !c.Add(P, Q).Equal(c.Add(Q, P)) {
+		t.Errorf("addition is not commutative")
+	}
+	if !c.Add(c.Add(P, Q), R).Equal(c.Add(P, c.Add(Q, R))) {
+		t.Errorf("addition is not associative")
+	}
+	if !c.Add(P, Infinity()).Equal(P) || !c.Add(Infinity(), P).Equal(P) {
+		t.Errorf("O is not the identity")
+	}
+	if !c.Add(P, c.Neg(P)).IsInfinity() {
+		t.Errorf("P + (-P) != O")
+	}
+	if !c.Double(P).Equal(c.Add(P, P)) || !c.IsOnCurve(c.Double(P)) {
+		t.Errorf("Double(P) != P + P")
+	}
+	sum := new(big.Int).Add(a, b)
+	if !c.ScalarBaseMult(sum).Equal(c.Add(P, Q)) {
+		t.Errorf("(a+b)*G != a*G + b*G")
+	}
+	if !c.ScalarMult(P, big.NewInt(-1)).Equal(c.Neg(P)) {
+		t.Errorf("-1*P != -P")
+	}
+}
+
+func TestECDH(t *testing.T) {
+	c := Challenge59()
+	a, A, err := c.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, B, err := c.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.ScalarMult(B, a).Equal(c.ScalarMult(A, b)) {
+		t.Errorf("ECDH shared secrets differ")
+	}
+}
+
+func TestInvalidCurvePoints(t *testing.T) {
+	c := Challenge59()
+	curves := []*Curve{
+		c.WithB(big.NewInt(210), mustInt("233970423115425145550826547352470124412")),
+		c.WithB(big.NewInt(504), mustInt("233970423115425145544350131142039591210")),
+		c.WithB(big.NewInt(727), mustInt("233970423115425145545378039958152057148")),
+	}
+	for _, e := range curves {
+		factors := primes.SmallFactors(e.Order, 1<<12)
+		if len(factors) == 0 {
+			t.Fatalf("no small factors for b = %v", e.B)
+		}
+		for _, r := range factors {
+			p, err := e.PointOfOrder(rand.Reader, r)
+			if err != nil {
+				t.Fatalf("b = %v, r = %v: %v", e.B, r, err)
+			}
+			if !e.IsOnCurve(p) || c.IsOnCurve(p) {
+				t.Errorf("point %v should be on the curve with b = %v only", p, e.B)
+			}
+			if o := e.PointOrder(p, e.Order, factors); o.Cmp(r) != 0 {
+				t.Errorf("b = %v: order of %v = %v; want %v", e.B, p, o, r)
+			}
+			// the original curve computes the same multiples, B is unused
+			if !c.ScalarMult(p, r).IsInfinity() {
+				t.Errorf("r*P on the original curve is not O")
+			}
+		}
+	}
+	if _, err := c.PointOfOrder(rand.Reader, big.NewInt(7)); err != ErrNoPoint {
+		t.Errorf("PointOfOrder(7) = %v; want %v", err, ErrNoPoint)
+	}
+}
+
+func TestPointOfOrderWithB(t *testing.T) {
+	c := Challenge59()
+	for _, r := range []int64{2, 3, 5, 7, 11, 37} {
+		e, p, err := c.PointOfOrderWithB(rand.Reader, r)
+		if err != nil {
+			t.Fatalf("r = %d: %v", r, err)
+		}
+		if e.A.Cmp(c.A) != 0 || e.P.Cmp(c.P) != 0 || e.singular() {
+			t.Errorf("r = %d: curve A = %v, B = %v", r, e.A, e.B)
+		}
+		if !e.IsOnCurve(p) || p.IsInfinity() {
+			t.Errorf("r = %d: %v is not a finite point of the curve with B = %v", r, p, e.B)
+		}
+		if q := e.ScalarMult(p, big.NewInt(r)); !q.IsInfinity() {
+			t.Errorf("r = %d: r*%v = %v; want O", r, p, q)
+		}
+	}
+	// the x of a point of odd order r on a known curve is a root of f_r at B
+	e := c.WithB(big.NewInt(210), mustInt("233970423115425145550826547352470124412"))
+	for _, r := range primes.SmallFactors(e.Order, 1<<8)[1:] {
+		p, err := e.PointOfOrder(rand.Reader, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f := newDivisionPolys(c, p.X)
+		if v := polyEval(f.f, f.get(int(r.Int64())), e.B); v.Sign() != 0 {
+			t.Errorf("f_%v(%v) at B = 210 is %v; want 0", r, p.X, v)
+		}
+	}
+}
+
+func polyEval(f field, a fpoly, x *big.Int) *big.Int {
+	res := new(big.Int)
+	for i := len(a) - 1; i >= 0; i-- {
+		res.Mul(res, x)
+		res.Add(res, a[i])
+		res.Mod(res, f.p)
+	}
+	return res
+}
+
+func TestPolyRoots(t *testing.T) {
+	f := field{big.NewInt(1000003)}
+	// (y - 1)(y - 5)(y - 999999)(y^2 + 1), and y^2 + 1 has no roots as
+	// 1000003 = 3 mod 4
+	a := fpoly{big.NewInt(1)}
+	for _, r := range []int64{1, 5, 999999} {
+		a = f.mul(a, fpoly{big.NewInt(1000003 - r), big.NewInt(1)})
+	}
+	a = f.mul(a, fpoly{big.NewInt(1), big.NewInt(0), big.NewInt(1)})
+	// and a large power of y - 7, which takes the Barrett path
+	b := fpoly{big.NewInt(1)}
+	for i := 0; i < 40; i++ {
+		b = f.mul(b, fpoly{big.NewInt(1000003 - 7), big.NewInt(1)})
+	}
+	for _, tc := range []struct {
+		a    fpoly
+		want []int64
+	}{
+		{a, []int64{1, 5, 999999}},
+		{f.mul(a, b), []int64{1, 5, 7, 999999}},
+	} {
+		roots, err := f.roots(rand.Reader, tc.a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make(map[int64]bool)
+		for _, r := range roots {
+			got[r.Int64()] = true
+		}
+		if len(roots) != len(tc.want) || len(got) != len(tc.want) {
+			t.Errorf("roots %v; want %v", roots, tc.want)
+			continue
+		}
+		for _, r := range tc.want {
+			if !got[r] {
+				t.Errorf("roots %v; want %v", roots, tc.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package ec
+
+import (
+	"crypto/rand"
+	"io"
+	"math/big"
+	"math/bits"
+)
+
+// fpoly is a polynomial over GF(p) with its coefficients in [0, p), lowest
+// degree first and without leading zeros. The zero polynomial is empty.
+type fpoly []*big.Int
+
+// field does the polynomial arithmetic of the division polynomials mod p.
+// Products use Kronecker substitution, packing the coefficients into one
+// big.Int, so that they run at the speed of big.Int multiplication instead
+// of one multiplication per pair of coefficients.
+type field struct {
+	p *big.Int
+}
+
+func (f field) trim(a fpoly) fpoly {
+	for len(a) > 0 && a[len(a)-1].Sign() == 0 {
+		a = a[:len(a)-1]
+	}
+	return a
+}
+
+func (f field) constant(c *big.Int) fpoly {
+	return f.trim(fpoly{new(big.Int).Mod(c, f.p)})
+}
+
+func (f field) add(a, b fpoly) fpoly {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	res := make(fpoly, len(a))
+	for i := range a {
+		res[i] = new(big.Int).Set(a[i])
+		if i < len(b) {
+			res[i].Add(res[i], b[i])
+			if res[i].Cmp(f.p) >= 0 {
+				res[i].Sub(res[i], f.p)
+			}
+		}
+	}
+	return f.trim(res)
+}
+
+func (f field) neg(a fpoly) fpoly {
+	res := make(fpoly, len(a))
+	for i, c := range a {
+		res[i] = new(big.Int)
+		if c.Sign() != 0 {
+			res[i].Sub(f.p, c)
+		}
+	}
+	return res
+}
+
+func (f field) sub(a, b fpoly) fpoly {
+	return f.add(a, f.neg(b))
+}
+
+func (f field) scale(a fpoly, c *big.Int) fpoly {
+	res := make(fpoly, len(a))
+	for i := range a {
+		res[i] = new(big.Int).Mul(a[i], c)
+		res[i].Mod(res[i], f.p)
+	}
+	return f.trim(res)
+}
+
+// mul returns a*b, evaluating both at 2^(8*size) for a slot size which
+// holds every coefficient of the integer product.
+func (f field) mul(a, b fpoly) fpoly {
+	if len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	size := (2*f.p.BitLen() + bits.Len(uint(n)) + 7) / 8
+	pack := func(a fpoly) *big.Int {
+		buf := make([]byte, len(a)*size)
+		for i, c := range a {
+			c.FillBytes(buf[(len(a)-1-i)*size : (len(a)-i)*size])
+		}
+		return new(big.Int).SetBytes(buf)
+	}
+	prod := pack(a)
+	prod.Mul(prod, pack(b))
+	m := len(a) + len(b) - 1
+	buf := prod.FillBytes(make([]byte, m*size))
+	res := make(fpoly, m)
+	for i := range res {
+		res[i] = new(big.Int).SetBytes(buf[(m-1-i)*size : (m-i)*size])
+		res[i].Mod(res[i], f.p)
+	}
+	return f.trim(res)
+}
+
+// inv returns the inverse of the nonzero c mod p.
+func (f field) inv(c *big.Int) *big.Int {
+	return new(big.Int).ModInverse(c, f.p)
+}
+
+func (f field) monic(a fpoly) fpoly {
+	if len(a) == 0 || a[len(a)-1].Cmp(one) == 0 {
+		return a
+	}
+	return f.scale(a, f.inv(a[len(a)-1]))
+}
+
+// divMod returns the quotient and the remainder of a / b for b != 0 by
+// schoolbook long division, which is fast for small b and for quotients of
+// low degree.
+func (f field) divMod(a, b fpoly) (q, r fpoly) {
+	r = append(fpoly(nil), a...)
+	if len(r) < len(b) {
+		return nil, r
+	}
+	for i := range r {
+		r[i] = new(big.Int).Set(r[i])
+	}
+	q = make(fpoly, len(r)-len(b)+1)
+	linv := f.inv(b[len(b)-1])
+	t := new(big.Int)
+	for d := len(r) - len(b); d >= 0; d-- {
+		c := new(big.Int).Mul(r[d+len(b)-1], linv)
+		q[d] = c.Mod(c, f.p)
+		if c.Sign() == 0 {
+			continue
+		}
+		for i, bi := range b {
+			x := r[d+i]
+			x.Sub(x, t.Mul(c, bi))
+			x.Mod(x, f.p)
+		}
+	}
+	return f.trim(q), f.trim(r[:len(b)-1])
+}
+
+func (f field) gcd(a, b fpoly) fpoly {
+	for len(b) > 0 {
+		_, r := f.divMod(a, f.monic(b))
+		a, b = b, r
+	}
+	return f.monic(a)
+}
+
+// truncate returns a mod y^n.
+func (f field) truncate(a fpoly, n int) fpoly {
+	if len(a) > n {
+		a = a[:n]
+	}
+	return f.trim(a)
+}
+
+// reverse returns y^(n-1) a(1/y) for a of degree below n.
+func reverse(a fpoly, n int) fpoly {
+	res := make(fpoly, n)
+	for i := range res {
+		if n-1-i < len(a) {
+			res[i] = a[n-1-i]
+		} else {
+			res[i] = new(big.Int)
+		}
+	}
+	return res
+}
+
+// reducer computes remainders mod a monic m of degree d with Barrett
+// reduction: the quotient of a product of degree below 2d comes from the
+// reversal of m inverted as a power series, which costs two products
+// instead of a long division.
+type reducer struct {
+	f   field
+	m   fpoly
+	inv fpoly // reverse(m)^-1 mod y^(d-1)
+}
+
+func (f field) newReducer(m fpoly) *reducer {
+	d := len(m) - 1
+	h := f.trim(reverse(m, d+1))
+	// Newton iteration g = g (2 - h g), doubling the precision each time
+	g := fpoly{big.NewInt(1)}
+	for prec := 1; prec < d-1; {
+		prec *= 2
+		if prec > d-1 {
+			prec = d - 1
+		}
+		g = f.truncate(f.mul(g, f.sub(fpoly{two}, f.truncate(f.mul(h, g), prec))), prec)
+	}
+	return &reducer{f, m, g}
+}
+
+func (r *reducer) reduce(a fpoly) fpoly {
+	f, d := r.f, len(r.m)-1
+	if len(a) <= d {
+		return a
+	}
+	k := len(a) - d
+	q := f.truncate(f.mul(f.truncate(reverse(a, len(a)), k), r.inv), k)
+	q = f.trim(reverse(q, k))
+	return f.truncate(f.sub(a, f.mul(q, r.m)), d)
+}
+
+// yPowMod returns y^e mod r.m.
+func (r *reducer) yPowMod(e *big.Int) fpoly {
+	f, d := r.f, len(r.m)-1
+	res := fpoly{big.NewInt(1)}
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		res = r.reduce(f.mul(res, res))
+		if e.Bit(i) == 1 {
+			// multiply by y and take away the leading term times m
+			res = append(fpoly{new(big.Int)}, res...)
+			if len(res) > d {
+				res = f.sub(res, f.scale(r.m, res[d]))
+			}
+		}
+	}
+	return res
+}
+
+// powMod returns a^e mod m by square and multiply with long division, for
+// moduli of small degree.
+func (f field) powMod(a fpoly, e *big.Int, m fpoly) fpoly {
+	res := fpoly{big.NewInt(1)}
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		_, res = f.divMod(f.mul(res, res), m)
+		if e.Bit(i) == 1 {
+			_, res = f.divMod(f.mul(res, a), m)
+		}
+	}
+	return res
+}
+
+// roots returns the distinct roots in GF(p) of a nonzero polynomial.
+func (f field) roots(rnd io.Reader, a fpoly) ([]*big.Int, error) {
+	a = f.monic(a)
+	if len(a) > 2 {
+		// the product of the linear factors of a is gcd(a, y^p - y)
+		h := fpoly{new(big.Int), big.NewInt(1)}
+		if len(a) > 32 {
+			h = f.newReducer(a).yPowMod(f.p)
+		} else {
+			h = f.powMod(h, f.p, a)
+		}
+		a = f.gcd(a, f.sub(h, fpoly{new(big.Int), big.NewInt(1)}))
+	}
+	return f.split(rnd, a)
+}
+
+// split returns the roots of a monic product of distinct linear factors by
+// Cantor-Zassenhaus: the roots c with (c + delta)^((p-1)/2) = 1 are those of
+// gcd(a, (y + delta)^((p-1)/2) - 1), about half of them for random delta.
+func (f field) split(rnd io.Reader, a fpoly) ([]*big.Int, error) {
+	switch len(a) {
+	case 0, 1:
+		return nil, nil
+	case 2:
+		return []*big.Int{new(big.Int).Sub(f.p, a[0])}, nil
+	}
+	e := new(big.Int).Rsh(f.p, 1)
+	for {
+		delta, err := rand.Int(rnd, f.p)
+		if err != nil {
+			return nil, err
+		}
+		h := f.powMod(fpoly{delta, big.NewInt(1)}, e, a)
+		g := f.gcd(a, f.sub(h, fpoly{big.NewInt(1)}))
+		if len(g) < 2 || len(g) == len(a) {
+			continue
+		}
+		q, _ := f.divMod(a, g)
+		r1, err := f.split(rnd, g)
+		if err != nil {
+			return nil, err
+		}
+		r2, err := f.split(rnd, f.monic(q))
+		if err != nil {
+			return nil, err
+		}
+		return append(r1, r2...), nil
+	}
+}
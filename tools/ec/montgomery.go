@@ -0,0 +1,260 @@
+package ec
+
+import (
+	"crypto/rand"
+	"io"
+	"math/big"
+)
+
+// Montgomery is the curve B*v^2 = u^3 + A*u^2 + u over GF(P). U is the u
+// coordinate of a base point of order N and Order the number of points on
+// the curve.
+type Montgomery struct {
+	A, B, P *big.Int
+	U       *big.Int
+	N       *big.Int
+	Order   *big.Int
+}
+
+// Side tells on which of the curve and its quadratic twist a u coordinate
+// lies.
+type Side int
+
+const (
+	// OnCurve means u^3 + A*u^2 + u is B times a nonzero square.
+	OnCurve Side = iota
+	// OnTwist means u^3 + A*u^2 + u is B times a nonsquare, so u is a point
+	// of the quadratic twist.
+	OnTwist
+	// OnBoth means u^3 + A*u^2 + u = 0, (u, 0) lies on both curves.
+	OnBoth
+)
+
+func (s Side) String() string {
+	switch s {
+	case OnCurve:
+		return "curve"
+	case OnTwist:
+		return "twist"
+	}
+	return "both"
+}
+
+// Challenge60 returns v^2 = u^3 + 534*u^2 + u, the Montgomery form of the
+// curve from challenge 59.
+func Challenge60() *Montgomery {
+	w := Challenge59()
+	return &Montgomery{
+		A:     big.NewInt(534),
+		B:     big.NewInt(1),
+		P:     w.P,
+		U:     big.NewInt(4),
+		N:     w.N,
+		Order: w.Order,
+	}
+}
+
+func (m *Montgomery) mod(x *big.Int) *big.Int {
+	return x.Mod(x, m.P)
+}
+
+func (m *Montgomery) inv(x *big.Int) *big.Int {
+	return new(big.Int).ModInverse(m.mod(new(big.Int).Set(x)), m.P)
+}
+
+// rhs returns u^3 + A*u^2 + u mod P.
+func (m *Montgomery) rhs(u *big.Int) *big.Int {
+	r := new(big.Int).Add(u, m.A)
+	r.Mul(r, u)
+	r.Add(r, one)
+	r.Mul(r, u)
+	return m.mod(r)
+}
+
+// Side returns whether u is the coordinate of a point on the curve, on its
+// twist or on both.
+func (m *Montgomery) Side(u *big.Int) Side {
+	r := m.rhs(u)
+	if r.Sign() == 0 {
+		return OnBoth
+	}
+	b := m.mod(new(big.Int).Set(m.B))
+	if big.Jacobi(r, m.P)*big.Jacobi(b, m.P) == 1 {
+		return OnCurve
+	}
+	return OnTwist
+}
+
+// V returns a v coordinate for u, or nil if u is on the twist.
+func (m *Montgomery) V(u *big.Int) *big.Int {
+	v2 := m.rhs(u)
+	v2.Mul(v2, m.inv(m.B))
+	return new(big.Int).ModSqrt(m.mod(v2), m.P)
+}
+
+// Ladder returns the u coordinate of k*(u, v) using the single-coordinate
+// Montgomery ladder. Infinity and (0, 0) both map to 0. The ladder never
+// uses B, so it works just as well for points on the twist. It runs for
+// the bit length of P, or of k if that is longer, so scalars larger than P,
+// such as multiples of the twist order, are not truncated.
+func (m *Montgomery) Ladder(u, k *big.Int) *big.Int {
+	p := m.P
+	u2, w2 := big.NewInt(1), big.NewInt(0)
+	u3, w3 := new(big.Int).Set(u), big.NewInt(1)
+	t1, t2 := new(big.Int), new(big.Int)
+	bits := p.BitLen()
+	if k.BitLen() > bits {
+		bits = k.BitLen()
+	}
+	for i := bits - 1; i >= 0; i-- {
+		b := k.Bit(i)
+		if b == 1 {
+			u2, u3 = u3, u2
+			w2, w3 = w3, w2
+		}
+		// differential addition: u3, w3 = (u2*u3 - w2*w3)^2, u*(u2*w3 - w2*u3)^2
+		t1.Mul(u2, u3)
+		t2.Mul(w2, w3)
+		nu3 := new(big.Int).Sub(t1, t2)
+		nu3.Mul(nu3, nu3)
+		m.mod(nu3)
+		t1.Mul(u2, w3)
+		t2.Mul(w2, u3)
+		nw3 := new(big.Int).Sub(t1, t2)
+		nw3.Mul(nw3, nw3)
+		nw3.Mul(nw3, u)
+		m.mod(nw3)
+		// doubling: u2, w2 = (u2^2 - w2^2)^2, 4*u2*w2*(u2^2 + A*u2*w2 + w2^2)
+		uu := new(big.Int).Mul(u2, u2)
+		ww := new(big.Int).Mul(w2, w2)
+		uw := new(big.Int).Mul(u2, w2)
+		nu2 := new(big.Int).Sub(uu, ww)
+		nu2.Mul(nu2, nu2)
+		m.mod(nu2)
+		nw2 := new(big.Int).Mul(m.A, uw)
+		nw2.Add(nw2, uu)
+		nw2.Add(nw2, ww)
+		nw2.Mul(nw2, uw)
+		nw2.Lsh(nw2, 2)
+		m.mod(nw2)
+
+		u2, w2, u3, w3 = nu2, nw2, nu3, nw3
+		if b == 1 {
+			u2, u3 = u3, u2
+			w2, w3 = w3, w2
+		}
+	}
+	// u2 * w2^(p-2), which is 0 when w2 = 0
+	e := new(big.Int).Sub(p, two)
+	res := new(big.Int).Exp(w2, e, p)
+	res.Mul(res, u2)
+	return m.mod(res)
+}
+
+// TwistOrder returns the number of points on the quadratic twist. The curve
+// and its twist together have 2*P + 2 points.
+func (m *Montgomery) TwistOrder() *big.Int {
+	n := new(big.Int).Lsh(m.P, 1)
+	n.Add(n, two)
+	return n.Sub(n, m.Order)
+}
+
+// Twist returns a quadratic twist B'*v^2 = u^3 + A*u^2 + u of m, where B' is
+// B times a nonsquare. All twists are isomorphic, so which one is returned
+// does not matter. The twist has no base point.
+func (m *Montgomery) Twist() *Montgomery {
+	d := big.NewInt(2)
+	for big.Jacobi(d, m.P) != -1 {
+		d.Add(d, one)
+	}
+	b := new(big.Int).Mul(m.B, d)
+	return &Montgomery{A: m.A, B: m.mod(b), P: m.P, Order: m.TwistOrder()}
+}
+
+// TwistPointOfOrder returns the u coordinate of a random point of prime order
+// r on the twist, r should divide the twist order. Since u = 0 stands for
+// both infinity and (0, 0), r = 2 cannot be found this way.
+func (m *Montgomery) TwistPointOfOrder(rnd io.Reader, r *big.Int) (*big.Int, error) {
+	cof, rem := new(big.Int), new(big.Int)
+	cof.QuoRem(m.TwistOrder(), r, rem)
+	if rem.Sign() != 0 || r.Cmp(two) == 0 {
+		return nil, ErrNoPoint
+	}
+	q := new(big.Int)
+	for {
+		q.QuoRem(cof, r, rem)
+		if rem.Sign() != 0 {
+			break
+		}
+		cof.Set(q)
+	}
+	for i := 0; i < 1000; i++ {
+		u, err := rand.Int(rnd, m.P)
+		if err != nil {
+			return nil, err
+		}
+		if m.Side(u) != OnTwist {
+			continue
+		}
+		u = m.Ladder(u, cof)
+		if u.Sign() == 0 {
+			continue
+		}
+		for {
+			next := m.Ladder(u, r)
+			if next.Sign() == 0 {
+				return u, nil
+			}
+			u = next
+		}
+	}
+	return nil, ErrNoPoint
+}
+
+// Weierstrass returns the short Weierstrass curve isomorphic to m, using
+//
+//	x = u/B + A/(3B), y = v/B
+//
+// which gives a = (3 - A^2)/(3B^2) and b = (2A^3 - 9A)/(27B^3).
+func (m *Montgomery) Weierstrass() *Curve {
+	A2 := new(big.Int).Mul(m.A, m.A)
+	B2 := new(big.Int).Mul(m.B, m.B)
+	a := new(big.Int).Sub(three, A2)
+	a.Mul(a, m.inv(new(big.Int).Mul(three, B2)))
+	b := new(big.Int).Mul(A2, m.A)
+	b.Lsh(b, 1)
+	b.Sub(b, new(big.Int).Mul(big.NewInt(9), m.A))
+	B3 := new(big.Int).Mul(B2, m.B)
+	b.Mul(b, m.inv(B3.Mul(B3, big.NewInt(27))))
+	c := &Curve{A: m.mod(a), B: m.mod(b), P: m.P, N: m.N, Order: m.Order}
+	if m.U != nil {
+		if v := m.V(m.U); v != nil {
+			c.G = m.ToWeierstrass(m.U, v)
+		}
+	}
+	return c
+}
+
+// ToWeierstrass maps the point (u, v) of m to its Weierstrass form.
+func (m *Montgomery) ToWeierstrass(u, v *big.Int) *Point {
+	binv := m.inv(m.B)
+	x := new(big.Int).Mul(u, binv)
+	a3 := new(big.Int).Mul(m.A, binv)
+	a3.Mul(a3, m.inv(three))
+	x.Add(x, a3)
+	y := new(big.Int).Mul(v, binv)
+	return &Point{m.mod(x), m.mod(y)}
+}
+
+// FromWeierstrass maps a point of the Weierstrass form back to (u, v). The
+// point at infinity has no affine (u, v) and yields nil.
+func (m *Montgomery) FromWeierstrass(p *Point) (u, v *big.Int) {
+	if p.IsInfinity() {
+		return nil, nil
+	}
+	// u = B*x - A/3, v = B*y
+	u = new(big.Int).Mul(m.B, p.X)
+	u.Sub(u, new(big.Int).Mul(m.A, m.inv(three)))
+	v = new(big.Int).Mul(m.B, p.Y)
+	return m.mod(u), m.mod(v)
+}
@@ -0,0 +1,132 @@
+package ec
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/ysmolsky/cryptopals/tools/primes"
+)
+
+func TestLadderBase(t *testing.T) {
+	m := Challenge60()
+	if m.Side(m.U) != OnCurve {
+		t.Fatalf("base point u = 4 is not on the curve")
+	}
+	if u := m.Ladder(m.U, m.N); u.Sign() != 0 {
+		t.Errorf("ladder(4, n) = %v; want 0", u)
+	}
+	if u := m.Ladder(m.U, big.NewInt(1)); u.Cmp(m.U) != 0 {
+		t.Errorf("ladder(4, 1) = %v; want 4", u)
+	}
+}
+
+func TestWeierstrassMapping(t *testing.T) {
+	m := Challenge60()
+	w := m.Weierstrass()
+	c := Challenge59()
+	if w.A.Cmp(new(big.Int).Mod(c.A, c.P)) != 0 || w.B.Cmp(c.B) != 0 {
+		t.Fatalf("Weierstrass form a = %v, b = %v; want %v, %v", w.A, w.B, c.A, c.B)
+	}
+	// the base points map onto each other up to sign
+	if !w.G.Equal(c.G) && !w.G.Equal(c.Neg(c.G)) {
+		t.Errorf("mapped base point %v; want ±%v", w.G, c.G)
+	}
+	for i := 0; i < 10; i++ {
+		p, err := c.RandomPoint(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		u, v := m.FromWeierstrass(p)
+		if m.Side(u) == OnTwist {
+			t.Fatalf("mapped point u = %v is on the twist", u)
+		}
+		if !m.ToWeierstrass(u, v).Equal(p) {
+			t.Errorf("mapping %v back and forth gives %v", p, m.ToWeierstrass(u, v))
+		}
+		k, _ := rand.Int(rand.Reader, c.Order)
+		q := c.ScalarMult(p, k)
+		got := m.Ladder(u, k)
+		if q.IsInfinity() {
+			if got.Sign() != 0 {
+				t.Errorf("ladder(u, k) = %v; want 0 for infinity", got)
+			}
+			continue
+		}
+		if exp, _ := m.FromWeierstrass(q); got.Cmp(exp) != 0 {
+			t.Errorf("ladder(u, k) = %v; Weierstrass k*P maps to u = %v", got, exp)
+		}
+	}
+}
+
+func TestLadderLongScalar(t *testing.T) {
+	m := Challenge60()
+	c := m.Weierstrass()
+	for _, k := range []*big.Int{
+		new(big.Int).Add(new(big.Int).Lsh(one, 200), big.NewInt(12345)),
+		new(big.Int).Add(new(big.Int).Lsh(one, uint(m.P.BitLen())), one),
+		new(big.Int).Mul(m.Order, m.TwistOrder()),
+	} {
+		q := c.ScalarMult(c.G, k)
+		got := m.Ladder(m.U, k)
+		if q.IsInfinity() {
+			if got.Sign() != 0 {
+				t.Errorf("ladder(4, %v) = %v; want 0 for infinity", k, got)
+			}
+			continue
+		}
+		if exp, _ := m.FromWeierstrass(q); got.Cmp(exp) != 0 {
+			t.Errorf("ladder(4, %v) = %v; Weierstrass k*G maps to u = %v", k, got, exp)
+		}
+	}
+}
+
+func TestTwist(t *testing.T) {
+	m := Challenge60()
+	u := mustInt("76600469441198017145391791613091732004")
+	if s := m.Side(u); s != OnTwist {
+		t.Fatalf("Side(%v) = %v; want twist", u, s)
+	}
+	if m.V(u) != nil {
+		t.Errorf("found v for a u on the twist")
+	}
+	if got := m.Ladder(u, big.NewInt(11)); got.Sign() != 0 {
+		t.Errorf("ladder(%v, 11) = %v; want 0", u, got)
+	}
+
+	tw := m.Twist()
+	if tw.Side(u) != OnCurve {
+		t.Errorf("%v is not on the twisted curve", u)
+	}
+	sum := new(big.Int).Add(m.Order, tw.Order)
+	if exp := new(big.Int).Add(new(big.Int).Lsh(m.P, 1), two); sum.Cmp(exp) != 0 {
+		t.Errorf("curve and twist orders add up to %v; want 2p+2", sum)
+	}
+	// the ladder agrees with the Weierstrass form of the twist as well
+	tww := tw.Weierstrass()
+	p := tw.ToWeierstrass(u, tw.V(u))
+	if !tww.IsOnCurve(p) {
+		t.Fatalf("twist point %v is not on the Weierstrass twist", p)
+	}
+	if !tww.ScalarMult(p, tw.Order).IsInfinity() {
+		t.Errorf("twist order does not annihilate a twist point")
+	}
+	k := big.NewInt(123456789)
+	got, _ := tw.FromWeierstrass(tww.ScalarMult(p, k))
+	if exp := m.Ladder(u, k); got.Cmp(exp) != 0 {
+		t.Errorf("ladder on the twist = %v; Weierstrass gives %v", exp, got)
+	}
+
+	for _, r := range primes.SmallFactors(tw.Order, 1<<16) {
+		if r.Cmp(two) == 0 {
+			continue
+		}
+		q, err := m.TwistPointOfOrder(rand.Reader, r)
+		if err != nil {
+			t.Fatalf("r = %v: %v", r, err)
+		}
+		if m.Side(q) != OnTwist || m.Ladder(q, r).Sign() != 0 {
+			t.Errorf("u = %v is not a twist point of order %v", q, r)
+		}
+	}
+}
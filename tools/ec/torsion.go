@@ -0,0 +1,131 @@
+package ec
+
+import (
+	"crypto/rand"
+	"io"
+	"math/big"
+)
+
+// divisionPolys computes the division polynomials of the curves
+// y^2 = x^3 + A*x + b at a fixed x as polynomials in b. A point (x, y) has
+// order dividing n exactly when f_n vanishes at x, where f_n is psi_n for
+// odd n and psi_n / y for even n, so that no y is left in the recursion.
+type divisionPolys struct {
+	f    field
+	rhs2 fpoly // (x^3 + A*x + b)^2
+	memo map[int]fpoly
+}
+
+func newDivisionPolys(c *Curve, x *big.Int) *divisionPolys {
+	f := field{c.P}
+	k := func(v int64) *big.Int { return big.NewInt(v) }
+	// sum of the terms c * x^i * A^j
+	term := func(ts ...[3]int64) *big.Int {
+		res := new(big.Int)
+		for _, t := range ts {
+			v := new(big.Int).Exp(x, k(t[1]), c.P)
+			v.Mul(v, new(big.Int).Exp(c.A, k(t[2]), c.P))
+			res.Add(res, v.Mul(v, k(t[0])))
+		}
+		return res.Mod(res, c.P)
+	}
+	rhs := fpoly{term([3]int64{1, 3, 0}, [3]int64{1, 1, 1}), k(1)}
+	d := &divisionPolys{f: f, rhs2: f.mul(rhs, rhs), memo: map[int]fpoly{
+		0: nil,
+		1: f.constant(k(1)),
+		2: f.constant(k(2)),
+		// 3x^4 + 6Ax^2 - A^2 + 12xb
+		3: f.trim(fpoly{term([3]int64{3, 4, 0}, [3]int64{6, 2, 1}, [3]int64{-1, 0, 2}), term([3]int64{12, 1, 0})}),
+		// 4(x^6 + 5Ax^4 - 5A^2x^2 - A^3 + (20x^3 - 4Ax)b - 8b^2)
+		4: f.trim(fpoly{
+			term([3]int64{4, 6, 0}, [3]int64{20, 4, 1}, [3]int64{-20, 2, 2}, [3]int64{-4, 0, 3}),
+			term([3]int64{80, 3, 0}, [3]int64{-16, 1, 1}),
+			term([3]int64{-32, 0, 0}),
+		}),
+	}}
+	return d
+}
+
+func (d *divisionPolys) get(n int) fpoly {
+	if p, ok := d.memo[n]; ok {
+		return p
+	}
+	f := d.f
+	cube := func(a fpoly) fpoly { return f.mul(a, f.mul(a, a)) }
+	sq := func(a fpoly) fpoly { return f.mul(a, a) }
+	m := n / 2
+	var res fpoly
+	if n%2 == 1 {
+		// f_2m+1 = f_m+2 f_m^3 - f_m-1 f_m+1^3, with y^4 on the even products
+		t1 := f.mul(d.get(m+2), cube(d.get(m)))
+		t2 := f.mul(d.get(m-1), cube(d.get(m+1)))
+		if m%2 == 0 {
+			t1 = f.mul(d.rhs2, t1)
+		} else {
+			t2 = f.mul(d.rhs2, t2)
+		}
+		res = f.sub(t1, t2)
+	} else {
+		// f_2m = f_m (f_m+2 f_m-1^2 - f_m-2 f_m+1^2) / 2
+		t := f.sub(f.mul(d.get(m+2), sq(d.get(m-1))), f.mul(d.get(m-2), sq(d.get(m+1))))
+		res = f.scale(f.mul(d.get(m), t), f.inv(two))
+	}
+	d.memo[n] = res
+	return res
+}
+
+// PointOfOrderWithB returns a curve with the A and P of c and a random B,
+// with an unknown order, together with a point on it of prime order r. It
+// picks random x and looks for the B for which x is a root of the r-th
+// division polynomial, which needs no point counting: any root of it is the
+// x of a point of order r on the curve or on its quadratic twist. Singular
+// curves are skipped.
+func (c *Curve) PointOfOrderWithB(rnd io.Reader, r int64) (*Curve, *Point, error) {
+	if r < 2 {
+		return nil, nil, ErrNoPoint
+	}
+	for i := 0; i < 100; i++ {
+		x, err := rand.Int(rnd, c.P)
+		if err != nil {
+			return nil, nil, err
+		}
+		var bs []*big.Int
+		if r == 2 {
+			// (x, 0) has order 2 for b = -x^3 - A*x
+			b := c.WithB(new(big.Int), nil).rhs(x)
+			bs = []*big.Int{b.Neg(b)}
+		} else {
+			d := newDivisionPolys(c, x)
+			if poly := d.get(int(r)); len(poly) > 0 {
+				if bs, err = d.f.roots(rnd, poly); err != nil {
+					return nil, nil, err
+				}
+			}
+		}
+		for _, b := range bs {
+			e := c.WithB(b.Mod(b, c.P), nil)
+			if e.singular() {
+				continue
+			}
+			y := new(big.Int).ModSqrt(e.rhs(x), c.P)
+			if y == nil {
+				continue
+			}
+			p := &Point{x, y}
+			if !e.ScalarMult(p, big.NewInt(r)).IsInfinity() {
+				continue
+			}
+			return e, p, nil
+		}
+	}
+	return nil, nil, ErrNoPoint
+}
+
+// singular reports whether 4A^3 + 27B^2 = 0 mod P.
+func (c *Curve) singular() bool {
+	d := new(big.Int).Exp(c.A, three, c.P)
+	d.Mul(d, big.NewInt(4))
+	b2 := new(big.Int).Mul(c.B, c.B)
+	d.Add(d, b2.Mul(b2, big.NewInt(27)))
+	return c.mod(d).Sign() == 0
+}
@@ -5,29 +5,86 @@ import (
 	"crypto/cipher"
 )
 
-// ECBDecrypt assumes that dst and src of the same length
-func ECBDecrypt(block cipher.Block, dst, src []byte) {
-	if len(src) != len(dst) {
-		panic("src and dst lengths do not match")
+type ecb struct {
+	b  cipher.Block
+	sz int
+}
+
+func (x *ecb) BlockSize() int { return x.sz }
+
+func (x *ecb) check(dst, src []byte) {
+	if err := checkBlocks(x.sz, dst, src); err != nil {
+		panic(err)
+	}
+}
+
+type ecbEncrypter ecb
+
+// NewECBEncrypter returns a cipher.BlockMode which encrypts in ECB mode using
+// the given block.
+func NewECBEncrypter(block cipher.Block) cipher.BlockMode {
+	return &ecbEncrypter{b: block, sz: block.BlockSize()}
+}
+
+func (x *ecbEncrypter) BlockSize() int { return x.sz }
+
+func (x *ecbEncrypter) CryptBlocks(dst, src []byte) {
+	(*ecb)(x).check(dst, src)
+	for from := 0; from < len(src); from += x.sz {
+		to := from + x.sz
+		x.b.Encrypt(dst[from:to], src[from:to])
 	}
-	sz := block.BlockSize()
-	for i := 0; i < len(src)/sz; i++ {
-		from := i * sz
-		to := (i + 1) * sz
-		block.Decrypt(dst[from:to], src[from:to])
+}
+
+type ecbDecrypter ecb
+
+// NewECBDecrypter returns a cipher.BlockMode which decrypts in ECB mode using
+// the given block.
+func NewECBDecrypter(block cipher.Block) cipher.BlockMode {
+	return &ecbDecrypter{b: block, sz: block.BlockSize()}
+}
+
+func (x *ecbDecrypter) BlockSize() int { return x.sz }
+
+func (x *ecbDecrypter) CryptBlocks(dst, src []byte) {
+	(*ecb)(x).check(dst, src)
+	for from := 0; from < len(src); from += x.sz {
+		to := from + x.sz
+		x.b.Decrypt(dst[from:to], src[from:to])
+	}
+}
+
+// ECBDecryptChecked decrypts src into dst in ECB mode. It returns
+// ErrLengthMismatch or ErrNotBlockAligned instead of panicking.
+func ECBDecryptChecked(block cipher.Block, dst, src []byte) error {
+	if err := checkBlocks(block.BlockSize(), dst, src); err != nil {
+		return err
+	}
+	NewECBDecrypter(block).CryptBlocks(dst, src)
+	return nil
+}
+
+// ECBEncryptChecked encrypts src into dst in ECB mode. It returns
+// ErrLengthMismatch or ErrNotBlockAligned instead of panicking.
+func ECBEncryptChecked(block cipher.Block, dst, src []byte) error {
+	if err := checkBlocks(block.BlockSize(), dst, src); err != nil {
+		return err
+	}
+	NewECBEncrypter(block).CryptBlocks(dst, src)
+	return nil
+}
+
+// ECBDecrypt is like ECBDecryptChecked but panics on invalid arguments.
+func ECBDecrypt(block cipher.Block, dst, src []byte) {
+	if err := ECBDecryptChecked(block, dst, src); err != nil {
+		panic(err)
 	}
 }
 
-// ECBDecrypt assumes that dst and src of the same length
+// ECBEncrypt is like ECBEncryptChecked but panics on invalid arguments.
 func ECBEncrypt(block cipher.Block, dst, src []byte) {
-	if len(src) != len(dst) {
-		panic("src and dst lengths do not match")
-	}
-	sz := block.BlockSize()
-	for i := 0; i < len(src)/sz; i++ {
-		from := i * sz
-		to := (i + 1) * sz
-		block.Encrypt(dst[from:to], src[from:to])
+	if err := ECBEncryptChecked(block, dst, src); err != nil {
+		panic(err)
 	}
 }
 
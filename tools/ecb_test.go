@@ -0,0 +1,30 @@
+package tools
+
+import (
+	"bytes"
+	"crypto/aes"
+	"testing"
+)
+
+func TestECBBlockMode(t *testing.T) {
+	block, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, in := range readChallengeInputs(t) {
+		pt := PadPKCS7(in, aes.BlockSize)
+		exp := make([]byte, len(pt))
+		for i := 0; i < len(pt); i += aes.BlockSize {
+			block.Encrypt(exp[i:], pt[i:i+aes.BlockSize])
+		}
+		got := make([]byte, len(pt))
+		NewECBEncrypter(block).CryptBlocks(got, pt)
+		if !bytes.Equal(got, exp) {
+			t.Fatalf("NewECBEncrypter(%.16q) differs from block-wise AES", in)
+		}
+		NewECBDecrypter(block).CryptBlocks(got, got)
+		if !bytes.Equal(got, pt) {
+			t.Fatalf("NewECBDecrypter(%.16q) did not restore the plaintext", in)
+		}
+	}
+}
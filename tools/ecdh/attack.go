@@ -0,0 +1,154 @@
+package ecdh
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"math/big"
+
+	"github.com/ysmolsky/cryptopals/tools/dlog"
+	"github.com/ysmolsky/cryptopals/tools/ec"
+	"github.com/ysmolsky/cryptopals/tools/primes"
+)
+
+// Curves59 returns the three invalid curves of challenge 59: the curve of
+// ec.Challenge59 with B = 210, 504 and 727, and their group orders.
+func Curves59() []*ec.Curve {
+	c := ec.Challenge59()
+	order := func(s string) *big.Int {
+		n, _ := new(big.Int).SetString(s, 10)
+		return n
+	}
+	return []*ec.Curve{
+		c.WithB(big.NewInt(210), order("233970423115425145550826547352470124412")),
+		c.WithB(big.NewInt(504), order("233970423115425145544350131142039591210")),
+		c.WithB(big.NewInt(727), order("233970423115425145545378039958152057148")),
+	}
+}
+
+// InvalidCurveAttack is the attack of challenge 59 on ECDH over Curve, whose
+// base point has prime order N. It sends the oracle points of small order
+// from curves with the same A and another B and recovers the secret modulo
+// each order from the MAC.
+type InvalidCurveAttack struct {
+	Curve  *ec.Curve
+	Oracle Oracle
+	// Curves are invalid curves with a known Order, sharing A and P with
+	// Curve, whose small subgroups are used first, such as Curves59. None
+	// by default.
+	Curves []*ec.Curve
+	// MAC is used to check guesses against the oracle, dlog.MAC by default.
+	MAC func(key, msg []byte) []byte
+	// Bound limits the small primes which are used, 2^16 by default.
+	Bound int
+	// Rand is the source for the order in which the curves are searched,
+	// for the random curves and for the points sent to the oracle,
+	// crypto/rand.Reader by default.
+	Rand io.Reader
+}
+
+// Run recovers the secret modulo small primes r, each below Bound and used
+// once, by querying the oracle with a point of order r and brute forcing the
+// MAC. It first visits Curves in random order and takes the primes dividing
+// their orders. Then it searches random curves with the same A and random B
+// for points of order 2, 3, 5 and so on with ec.(*Curve).PointOfOrderWithB.
+// The residues are combined with CRT until their modulus exceeds N. If the
+// primes below Bound run out before that, the partial congruence is returned
+// with dlog.ErrIncomplete.
+func (a *InvalidCurveAttack) Run() (dlog.Congruence, error) {
+	mac, bound, rnd := a.MAC, a.Bound, a.Rand
+	if mac == nil {
+		mac = dlog.MAC
+	}
+	if bound == 0 {
+		bound = 1 << 16
+	}
+	if rnd == nil {
+		rnd = rand.Reader
+	}
+	order, err := shuffle(rnd, len(a.Curves))
+	if err != nil {
+		return dlog.Congruence{}, err
+	}
+
+	var residues []dlog.Congruence
+	used := make(map[string]bool)
+	prod := big.NewInt(1)
+	// try recovers the secret mod r from the point h of order r on c and
+	// reports whether the residues are complete.
+	try := func(c *ec.Curve, h *ec.Point, r *big.Int) (bool, error) {
+		msg, tag, err := a.Oracle.Query(h)
+		if err != nil {
+			return false, err
+		}
+		x, ok := dlog.BruteForce[*ec.Point](dlog.Curve{C: c}, h, r, func(k *ec.Point) bool {
+			return bytes.Equal(mac(SharedKey(c, k), msg), tag)
+		})
+		if !ok {
+			return false, nil
+		}
+		used[r.String()] = true
+		residues = append(residues, dlog.Congruence{A: x, M: r})
+		prod.Mul(prod, r)
+		return prod.Cmp(a.Curve.N) > 0, nil
+	}
+
+	for _, i := range order {
+		c := a.Curves[i]
+		for _, r := range primes.SmallFactors(c.Order, bound) {
+			if used[r.String()] {
+				continue
+			}
+			h, err := c.PointOfOrder(rnd, r)
+			if err != nil {
+				return dlog.Congruence{}, err
+			}
+			done, err := try(c, h, r)
+			if err != nil {
+				return dlog.Congruence{}, err
+			}
+			if done {
+				return dlog.CRT(residues)
+			}
+		}
+	}
+	for _, r := range primes.Sieve(bound) {
+		if used[big.NewInt(r).String()] {
+			continue
+		}
+		c, h, err := a.Curve.PointOfOrderWithB(rnd, r)
+		if err == ec.ErrNoPoint {
+			continue
+		}
+		if err != nil {
+			return dlog.Congruence{}, err
+		}
+		done, err := try(c, h, big.NewInt(r))
+		if err != nil {
+			return dlog.Congruence{}, err
+		}
+		if done {
+			return dlog.CRT(residues)
+		}
+	}
+	res, err := dlog.CRT(residues)
+	if err != nil {
+		return dlog.Congruence{}, err
+	}
+	return res, dlog.ErrIncomplete
+}
+
+// shuffle returns a random permutation of [0, n).
+func shuffle(rnd io.Reader, n int) ([]int, error) {
+	perm := make([]int, n)
+	for i := range perm {
+		j, err := rand.Int(rnd, big.NewInt(int64(i+1)))
+		if err != nil {
+			return nil, err
+		}
+		k := int(j.Int64())
+		perm[i] = perm[k]
+		perm[k] = i
+	}
+	return perm, nil
+}
@@ -0,0 +1,78 @@
+// Package ecdh is the elliptic curve Diffie-Hellman of challenge 59 with a
+// Bob who does not check that the points he receives are on his curve, and
+// the invalid-curve attack which recovers his secret from the MACs he sends
+// back, either in-process or over a local TCP connection.
+package ecdh
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/ysmolsky/cryptopals/tools/dlog"
+	"github.com/ysmolsky/cryptopals/tools/ec"
+)
+
+// ErrInvalidPoint is returned by a Bob which checks points for a point not
+// on his curve.
+var ErrInvalidPoint = errors.New("ecdh: point is not on the curve")
+
+// Message is the message Bob MACs, as in challenges 57 and 59.
+const Message = "crazy flamboyant for the rap enjoyment"
+
+// Oracle is the victim of the invalid-curve attack: it multiplies h by its
+// secret and returns a message with its MAC keyed by the shared point.
+type Oracle interface {
+	Query(h *ec.Point) (msg, mac []byte, err error)
+}
+
+// SharedKey returns the MAC key for the shared point p: its coordinates as
+// big-endian integers of the byte length of c.P, or nothing for infinity.
+// Using both coordinates keeps k and -k apart, which a key made of x alone
+// would not.
+func SharedKey(c *ec.Curve, p *ec.Point) []byte {
+	if p.IsInfinity() {
+		return nil
+	}
+	size := (c.P.BitLen() + 7) / 8
+	key := make([]byte, 2*size)
+	p.X.FillBytes(key[:size])
+	p.Y.FillBytes(key[size:])
+	return key
+}
+
+// Bob is the server of challenge 59. He answers every query with the MAC of
+// Message under his secret times the received point.
+type Bob struct {
+	Curve  *ec.Curve
+	Secret *big.Int
+	Public *ec.Point
+	// CheckPoints makes Bob reject points which are not on Curve with
+	// ErrInvalidPoint, which is all it takes to stop the attack.
+	CheckPoints bool
+}
+
+// NewBob returns a Bob with a random secret on c, which needs a base point.
+// rnd defaults to crypto/rand.Reader when nil.
+func NewBob(rnd io.Reader, c *ec.Curve) (*Bob, error) {
+	if rnd == nil {
+		rnd = rand.Reader
+	}
+	x, pub, err := c.GenerateKey(rnd)
+	if err != nil {
+		return nil, err
+	}
+	return &Bob{Curve: c, Secret: x, Public: pub}, nil
+}
+
+// Query implements Oracle. Without CheckPoints the arithmetic happily runs on
+// whatever curve h lies on, since B does not take part in the group law.
+func (b *Bob) Query(h *ec.Point) (msg, mac []byte, err error) {
+	if b.CheckPoints && !b.Curve.IsOnCurve(h) {
+		return nil, nil, ErrInvalidPoint
+	}
+	k := b.Curve.ScalarMult(h, b.Secret)
+	msg = []byte(Message)
+	return msg, dlog.MAC(SharedKey(b.Curve, k), msg), nil
+}
@@ -0,0 +1,139 @@
+package ecdh
+
+import (
+	"math/big"
+	"net"
+	"testing"
+
+	"github.com/ysmolsky/cryptopals/tools/dlog"
+	"github.com/ysmolsky/cryptopals/tools/ec"
+)
+
+// curve64 is y^2 = x^3 - 3x + b over a 64-bit prime field with a prime
+// number of points, small enough for the random curve search to be quick.
+func curve64() *ec.Curve {
+	n := func(s string) *big.Int {
+		v, _ := new(big.Int).SetString(s, 10)
+		return v
+	}
+	return &ec.Curve{
+		A:     big.NewInt(-3),
+		B:     n("3651945445938435690"),
+		P:     n("17911363325383247651"),
+		G:     ec.NewPoint(n("10429467003905077662"), n("17861473436475688650")),
+		N:     n("17911363327274779211"),
+		Order: n("17911363327274779211"),
+	}
+}
+
+func checkAttack(t *testing.T, bob *Bob, o Oracle, curves []*ec.Curve) {
+	t.Helper()
+	attack := &InvalidCurveAttack{Curve: bob.Curve, Oracle: o, Curves: curves}
+	got, err := attack.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.M.Cmp(bob.Curve.N) <= 0 {
+		t.Errorf("modulus %v does not exceed N", got.M)
+	}
+	if got.A.Cmp(bob.Secret) != 0 {
+		t.Errorf("recovered secret = %v; want %v", got.A, bob.Secret)
+	}
+}
+
+func TestInvalidCurveAttack(t *testing.T) {
+	bob, err := NewBob(nil, ec.Challenge59())
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkAttack(t, bob, bob, Curves59())
+}
+
+func TestInvalidCurveAttackRandom(t *testing.T) {
+	c := curve64()
+	if !c.IsOnCurve(c.G) || !c.ScalarBaseMult(c.N).IsInfinity() {
+		t.Fatal("bad test curve")
+	}
+	bob, err := NewBob(nil, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkAttack(t, bob, bob, nil)
+}
+
+func TestInvalidCurveAttackRandom59(t *testing.T) {
+	if testing.Short() {
+		t.Skip("searches random curves up to r = 101")
+	}
+	bob, err := NewBob(nil, ec.Challenge59())
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkAttack(t, bob, bob, nil)
+}
+
+func TestInvalidCurveAttackTCP(t *testing.T) {
+	bob, err := NewBob(nil, ec.Challenge59())
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go Serve(l, bob)
+
+	client, err := Dial(l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	checkAttack(t, bob, client, Curves59())
+}
+
+func TestCheckPointsTCP(t *testing.T) {
+	bob, err := NewBob(nil, ec.Challenge59())
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob.CheckPoints = true
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go Serve(l, bob)
+
+	client, err := Dial(l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	attack := &InvalidCurveAttack{Curve: bob.Curve, Oracle: client}
+	if _, err := attack.Run(); err != ErrInvalidPoint {
+		t.Errorf("Run against a checking Bob = %v; want %v", err, ErrInvalidPoint)
+	}
+	msg, mac, err := client.Query(bob.Curve.G)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(msg) != Message || len(mac) == 0 {
+		t.Errorf("Query(G) = %q, %x", msg, mac)
+	}
+}
+
+func TestInvalidCurveAttackIncomplete(t *testing.T) {
+	bob, err := NewBob(nil, ec.Challenge59())
+	if err != nil {
+		t.Fatal(err)
+	}
+	attack := &InvalidCurveAttack{Curve: bob.Curve, Oracle: bob, Bound: 30}
+	got, err := attack.Run()
+	if err != dlog.ErrIncomplete {
+		t.Fatalf("Run with primes below 30 = %v; want %v", err, dlog.ErrIncomplete)
+	}
+	if x := new(big.Int).Mod(bob.Secret, got.M); got.A.Cmp(x) != 0 {
+		t.Errorf("partial residue %v; want %v", got.A, x)
+	}
+}
@@ -0,0 +1,142 @@
+package ecdh
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+
+	"github.com/ysmolsky/cryptopals/tools/ec"
+)
+
+var (
+	// ErrProtocol is returned for a malformed line on the wire.
+	ErrProtocol = errors.New("ecdh: malformed message")
+	// ErrServer is returned by Client when the server failed to answer a
+	// query for a reason other than ErrInvalidPoint.
+	ErrServer = errors.New("ecdh: server error")
+)
+
+// The protocol is line based, like the SRP server of challenge 37. A query
+// is a point as "x y" in decimal, or "O" for infinity. The answer is
+// "ok <msg> <mac>" in hex, "invalid" for ErrInvalidPoint or "error" for any
+// other failure. A connection carries any number of queries.
+
+func formatPoint(p *ec.Point) string {
+	if p.IsInfinity() {
+		return "O"
+	}
+	return p.X.String() + " " + p.Y.String()
+}
+
+func parsePoint(line string) (*ec.Point, error) {
+	if line == "O" {
+		return ec.Infinity(), nil
+	}
+	f := strings.Fields(line)
+	if len(f) != 2 {
+		return nil, ErrProtocol
+	}
+	x, ok := new(big.Int).SetString(f[0], 10)
+	if !ok {
+		return nil, ErrProtocol
+	}
+	y, ok := new(big.Int).SetString(f[1], 10)
+	if !ok {
+		return nil, ErrProtocol
+	}
+	return &ec.Point{X: x, Y: y}, nil
+}
+
+// Serve accepts connections on l and answers the queries on each of them
+// with o, which must be safe for concurrent use, until Accept fails. Closing
+// l stops it.
+func Serve(l net.Listener, o Oracle) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go serveConn(conn, o)
+	}
+}
+
+func serveConn(conn net.Conn, o Oracle) {
+	defer conn.Close()
+	r := bufio.NewScanner(conn)
+	w := bufio.NewWriter(conn)
+	for r.Scan() {
+		var reply string
+		h, err := parsePoint(r.Text())
+		if err == nil {
+			var msg, mac []byte
+			msg, mac, err = o.Query(h)
+			if err == nil {
+				reply = "ok " + hex.EncodeToString(msg) + " " + hex.EncodeToString(mac)
+			}
+		}
+		switch {
+		case err == ErrInvalidPoint:
+			reply = "invalid"
+		case err != nil:
+			reply = "error"
+		}
+		if _, err := fmt.Fprintln(w, reply); err != nil {
+			return
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// Client is an Oracle which sends its queries to a server running Serve.
+// It is not safe for concurrent use.
+type Client struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// Dial connects to the server at addr over TCP.
+func Dial(addr string) (*Client, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn, bufio.NewReader(conn)}, nil
+}
+
+// Query implements Oracle.
+func (c *Client) Query(h *ec.Point) (msg, mac []byte, err error) {
+	if _, err := fmt.Fprintln(c.conn, formatPoint(h)); err != nil {
+		return nil, nil, err
+	}
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, nil, err
+	}
+	f := strings.Fields(line)
+	switch {
+	case len(f) == 1 && f[0] == "invalid":
+		return nil, nil, ErrInvalidPoint
+	case len(f) == 1 && f[0] == "error":
+		return nil, nil, ErrServer
+	case len(f) != 3 || f[0] != "ok":
+		return nil, nil, ErrProtocol
+	}
+	if msg, err = hex.DecodeString(f[1]); err != nil {
+		return nil, nil, ErrProtocol
+	}
+	if mac, err = hex.DecodeString(f[2]); err != nil {
+		return nil, nil, ErrProtocol
+	}
+	return msg, mac, nil
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
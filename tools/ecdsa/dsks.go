@@ -0,0 +1,52 @@
+package ecdsa
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+)
+
+// ErrSignature is returned by DSKS for a signature which does not verify
+// under the original key.
+var ErrSignature = errors.New("ecdsa: signature does not verify")
+
+// DSKS is the duplicate-signature key selection of challenge 61: it returns
+// a new key pair under which the valid signature (r, s) of hashed by pub
+// verifies too. Verification computes R = u1 G + u2 Q = (u1 + u2 d) G, so
+// for a random d' and t = u1 + u2 d' the base point G' = t^-1 R and the
+// public point Q' = d' G' give the same R. The new key keeps the curve and
+// the order N of pub, only the base point changes, and both points are in
+// the subgroup of order N, so it passes the same validation. rnd defaults
+// to crypto/rand.Reader when nil.
+func DSKS(rnd io.Reader, pub *PublicKey, hashed []byte, r, s *big.Int) (*PrivateKey, error) {
+	if !Verify(pub, hashed, r, s) {
+		return nil, ErrSignature
+	}
+	if rnd == nil {
+		rnd = rand.Reader
+	}
+	c := pub.Curve
+	n := c.N
+	u1, u2, _ := scalars(n, hashed, r, s)
+	R := c.Add(c.ScalarBaseMult(u1), c.ScalarMult(pub.Q, u2))
+	for {
+		d, err := rand.Int(rnd, new(big.Int).Sub(n, one))
+		if err != nil {
+			return nil, err
+		}
+		d.Add(d, one)
+		t := new(big.Int).Mul(u2, d)
+		t.Add(t, u1)
+		tInv := new(big.Int).ModInverse(t.Mod(t, n), n)
+		if tInv == nil {
+			continue
+		}
+		curve := *c
+		curve.G = c.ScalarMult(R, tInv)
+		return &PrivateKey{
+			PublicKey: PublicKey{Curve: &curve, Q: curve.ScalarBaseMult(d)},
+			D:         d,
+		}, nil
+	}
+}
@@ -0,0 +1,56 @@
+package ecdsa
+
+import (
+	"crypto/elliptic"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/ysmolsky/cryptopals/tools/ec"
+)
+
+func TestDSKS(t *testing.T) {
+	for name, c := range map[string]*ec.Curve{"challenge 59": ec.Challenge59(), "P-256": fromStdlib(elliptic.P256())} {
+		alice, err := GenerateKey(nil, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashed := sha256.Sum256([]byte("I, Alice, owe Eve a million dollars"))
+		r, s, err := Sign(nil, alice, hashed[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		eve, err := DSKS(nil, &alice.PublicKey, hashed[:], r, s)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !Verify(&eve.PublicKey, hashed[:], r, s) {
+			t.Errorf("%s: Alice's signature does not verify under Eve's key", name)
+		}
+		if eve.Q.Equal(alice.Q) || eve.Curve.G.Equal(c.G) {
+			t.Errorf("%s: Eve's key is Alice's", name)
+		}
+		// only the base point differs, and both points have order N
+		ec2 := eve.Curve
+		if ec2.A.Cmp(c.A) != 0 || ec2.B.Cmp(c.B) != 0 || ec2.P.Cmp(c.P) != 0 || ec2.N.Cmp(c.N) != 0 {
+			t.Errorf("%s: domain parameters changed", name)
+		}
+		for _, p := range []*ec.Point{ec2.G, eve.Q} {
+			if !c.IsOnCurve(p) || p.IsInfinity() || !c.ScalarMult(p, c.N).IsInfinity() {
+				t.Errorf("%s: %v is not a point of order N", name, p)
+			}
+		}
+		// Eve's key is a real key pair
+		mine := sha256.Sum256([]byte("signed by Eve"))
+		if r2, s2, err := Sign(nil, eve, mine[:]); err != nil || !Verify(&eve.PublicKey, mine[:], r2, s2) {
+			t.Errorf("%s: Eve cannot sign with her key: %v", name, err)
+		}
+		if eve.Curve == alice.Curve {
+			t.Errorf("%s: DSKS changed the base point of Alice's curve", name)
+		}
+
+		if _, err := DSKS(nil, &alice.PublicKey, hashed[:], r, new(big.Int).Add(s, big.NewInt(1))); err != ErrSignature {
+			t.Errorf("%s: DSKS of a bad signature = %v; want %v", name, err, ErrSignature)
+		}
+	}
+}
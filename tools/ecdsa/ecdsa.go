@@ -0,0 +1,169 @@
+// Package ecdsa is the ECDSA of challenges 61 and 62 over any short
+// Weierstrass curve of package ec with a base point of prime order:
+// key generation, signatures with random, RFC 6979 deterministic or chosen
+// nonces, verification, and the duplicate-signature key selection of
+// challenge 61. Like package ec it is written for clarity, not speed, and is
+// not constant time.
+package ecdsa
+
+import (
+	"crypto"
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/ysmolsky/cryptopals/tools/dsa"
+	"github.com/ysmolsky/cryptopals/tools/ec"
+)
+
+var (
+	// ErrCurve is returned for a curve without a base point and its order.
+	ErrCurve = errors.New("ecdsa: curve has no base point")
+	// ErrNonce is returned when a nonce is not in [1, N-1].
+	ErrNonce = errors.New("ecdsa: invalid nonce")
+)
+
+var one = big.NewInt(1)
+
+// PublicKey is an ECDSA public key, the point Q = D G. The curve with its
+// base point is part of the key, as in protocols which let the signer choose
+// the domain parameters.
+type PublicKey struct {
+	Curve *ec.Curve
+	Q     *ec.Point
+}
+
+// PrivateKey is an ECDSA private key.
+type PrivateKey struct {
+	PublicKey
+	D *big.Int // secret scalar in [1, N-1]
+}
+
+func hasBase(c *ec.Curve) bool {
+	return c != nil && c.G != nil && c.N != nil && c.N.Cmp(one) > 0
+}
+
+// GenerateKey generates a key on c. rnd defaults to crypto/rand.Reader when
+// nil.
+func GenerateKey(rnd io.Reader, c *ec.Curve) (*PrivateKey, error) {
+	if !hasBase(c) {
+		return nil, ErrCurve
+	}
+	if rnd == nil {
+		rnd = rand.Reader
+	}
+	d, q, err := c.GenerateKey(rnd)
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKey{PublicKey{c, q}, d}, nil
+}
+
+// hashToInt converts a hash to an integer with the leftmost N.BitLen() bits
+// of hashed, like DSA does.
+func hashToInt(hashed []byte, n *big.Int) *big.Int {
+	z := new(big.Int).SetBytes(hashed)
+	if excess := len(hashed)*8 - n.BitLen(); excess > 0 {
+		z.Rsh(z, uint(excess))
+	}
+	return z
+}
+
+// SignWithNonce signs hashed with the nonce k:
+// r = (k G).x mod N, s = k^-1 (z + D r) mod N.
+// It returns ErrNonce if k is not in [1, N-1]. Like dsa.SignWithNonce it
+// does not reject r = 0 or s = 0.
+func SignWithNonce(priv *PrivateKey, hashed []byte, k *big.Int) (r, s *big.Int, err error) {
+	c := priv.Curve
+	if !hasBase(c) {
+		return nil, nil, ErrCurve
+	}
+	n := c.N
+	if k.Sign() <= 0 || k.Cmp(n) >= 0 {
+		return nil, nil, ErrNonce
+	}
+	kInv := new(big.Int).ModInverse(k, n)
+	if kInv == nil {
+		return nil, nil, ErrNonce
+	}
+	r = new(big.Int).Mod(c.ScalarBaseMult(k).X, n)
+	s = new(big.Int).Mul(priv.D, r)
+	s.Add(s, hashToInt(hashed, n))
+	s.Mul(s, kInv)
+	s.Mod(s, n)
+	return r, s, nil
+}
+
+// Sign signs hashed with a random nonce. rnd defaults to crypto/rand.Reader
+// when nil.
+func Sign(rnd io.Reader, priv *PrivateKey, hashed []byte) (r, s *big.Int, err error) {
+	c := priv.Curve
+	if !hasBase(c) {
+		return nil, nil, ErrCurve
+	}
+	if rnd == nil {
+		rnd = rand.Reader
+	}
+	for {
+		k, err := rand.Int(rnd, new(big.Int).Sub(c.N, one))
+		if err != nil {
+			return nil, nil, err
+		}
+		r, s, err = SignWithNonce(priv, hashed, k.Add(k, one))
+		if err == nil && r.Sign() != 0 && s.Sign() != 0 {
+			return r, s, nil
+		}
+	}
+}
+
+// SignDeterministic signs hashed, the output of h, with the nonce derived
+// from the key and the hash by RFC 6979.
+func SignDeterministic(priv *PrivateKey, h crypto.Hash, hashed []byte) (r, s *big.Int, err error) {
+	c := priv.Curve
+	if !hasBase(c) {
+		return nil, nil, ErrCurve
+	}
+	nonces := dsa.RFC6979(c.N, priv.D, h, hashed)
+	for {
+		r, s, err = SignWithNonce(priv, hashed, nonces())
+		if err == nil && r.Sign() != 0 && s.Sign() != 0 {
+			return r, s, nil
+		}
+	}
+}
+
+// scalars returns u1 = z s^-1 and u2 = r s^-1 mod n for the signature
+// (r, s) of hashed, or false if r or s is out of range.
+func scalars(n *big.Int, hashed []byte, r, s *big.Int) (u1, u2 *big.Int, ok bool) {
+	if r.Sign() <= 0 || r.Cmp(n) >= 0 || s.Sign() <= 0 || s.Cmp(n) >= 0 {
+		return nil, nil, false
+	}
+	w := new(big.Int).ModInverse(s, n)
+	if w == nil {
+		return nil, nil, false
+	}
+	u1 = new(big.Int).Mul(hashToInt(hashed, n), w)
+	u1.Mod(u1, n)
+	u2 = w.Mul(r, w)
+	u2.Mod(u2, n)
+	return u1, u2, true
+}
+
+// Verify reports whether (r, s) is a valid signature of hashed by pub.
+func Verify(pub *PublicKey, hashed []byte, r, s *big.Int) bool {
+	if !hasBase(pub.Curve) || pub.Q == nil || pub.Q.IsInfinity() {
+		return false
+	}
+	c := pub.Curve
+	u1, u2, ok := scalars(c.N, hashed, r, s)
+	if !ok {
+		return false
+	}
+	// R = u1 G + u2 Q
+	R := c.Add(c.ScalarBaseMult(u1), c.ScalarMult(pub.Q, u2))
+	if R.IsInfinity() {
+		return false
+	}
+	return new(big.Int).Mod(R.X, pub.Curve.N).Cmp(r) == 0
+}
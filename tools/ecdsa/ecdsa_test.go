@@ -0,0 +1,139 @@
+package ecdsa
+
+import (
+	"crypto"
+	stdecdsa "crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/ysmolsky/cryptopals/tools/ec"
+)
+
+func fromHex(s string) *big.Int {
+	v, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("bad hex in test: " + s)
+	}
+	return v
+}
+
+// fromStdlib returns the curve of crypto/elliptic with its base point.
+func fromStdlib(c elliptic.Curve) *ec.Curve {
+	params := c.Params()
+	return &ec.Curve{
+		A: big.NewInt(-3), B: params.B, P: params.P,
+		G: ec.NewPoint(params.Gx, params.Gy), N: params.N, Order: params.N,
+	}
+}
+
+func TestRFC6979(t *testing.T) {
+	// RFC 6979, appendices A.2.5 and A.2.6, with SHA-256
+	tests := []struct {
+		curve   elliptic.Curve
+		d, x, y string
+		msg     string
+		r, s    string
+	}{
+		{
+			elliptic.P256(),
+			"C9AFA9D845BA75166B5C215767B1D6934E50C3DB36E89B127B8A622B120F6721",
+			"60FED4BA255A9D31C961EB74C6356D68C049B8923B61FA6CE669622E60F29FB6",
+			"7903FE1008B8BC99A41AE9E95628BC64F2F1B20C2D7E9F5177A3C294D4462299",
+			"sample",
+			"EFD48B2AACB6A8FD1140DD9CD45E81D69D2C877B56AAF991C34D0EA84EAF3716",
+			"F7CB1C942D657C41D436C7A1B6E29F65F3E900DBB9AFF4064DC4AB2F843ACDA8",
+		},
+		{
+			elliptic.P256(),
+			"C9AFA9D845BA75166B5C215767B1D6934E50C3DB36E89B127B8A622B120F6721",
+			"60FED4BA255A9D31C961EB74C6356D68C049B8923B61FA6CE669622E60F29FB6",
+			"7903FE1008B8BC99A41AE9E95628BC64F2F1B20C2D7E9F5177A3C294D4462299",
+			"test",
+			"F1ABB023518351CD71D881567B1EA663ED3EFCF6C5132B354F28D3B0B7D38367",
+			"019F4113742A2B14BD25926B49C649155F267E60D3814B4C0CC84250E46F0083",
+		},
+		{
+			elliptic.P384(),
+			"6B9D3DAD2E1B8C1C05B19875B6659F4DE23C3B667BF297BA9AA47740787137D896D5724E4C70A825F872C9EA60D2EDF5",
+			"EC3A4E415B4E19A4568618029F427FA5DA9A8BC4AE92E02E06AAE5286B300C64DEF8F0EA9055866064A254515480BC13",
+			"8015D9B72D7D57244EA8EF9AC0C621896708A59367F9DFB9F54CA84B3F1C9DB1288B231C3AE0D4FE7344FD2533264720",
+			"sample",
+			"21B13D1E013C7FA1392D03C5F99AF8B30C570C6F98D4EA8E354B63A21D3DAA33BDE1E888E63355D92FA2B3C36D8FB2CD",
+			"F3AA443FB107745BF4BD77CB3891674632068A10CA67E3D45DB2266FA7D1FEEBEFDC63ECCD1AC42EC0CB8668A4FA0AB0",
+		},
+	}
+	for _, test := range tests {
+		c := fromStdlib(test.curve)
+		priv := &PrivateKey{PublicKey{c, ec.NewPoint(fromHex(test.x), fromHex(test.y))}, fromHex(test.d)}
+		if !c.ScalarBaseMult(priv.D).Equal(priv.Q) {
+			t.Fatalf("%s: public point does not match the key", test.curve.Params().Name)
+		}
+		hashed := sha256.Sum256([]byte(test.msg))
+		r, s, err := SignDeterministic(priv, crypto.SHA256, hashed[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Cmp(fromHex(test.r)) != 0 || s.Cmp(fromHex(test.s)) != 0 {
+			t.Errorf("%s %q: signature = (%X, %X)", test.curve.Params().Name, test.msg, r, s)
+		}
+		if !Verify(&priv.PublicKey, hashed[:], r, s) {
+			t.Errorf("%s %q: signature does not verify", test.curve.Params().Name, test.msg)
+		}
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	for name, c := range map[string]*ec.Curve{"challenge 59": ec.Challenge59(), "P-256": fromStdlib(elliptic.P256())} {
+		priv, err := GenerateKey(nil, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashed := sha256.Sum256([]byte("hi mom"))
+		r, s, err := Sign(nil, priv, hashed[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Verify(&priv.PublicKey, hashed[:], r, s) {
+			t.Errorf("%s: signature does not verify", name)
+		}
+		other := sha256.Sum256([]byte("hi dad"))
+		if Verify(&priv.PublicKey, other[:], r, s) {
+			t.Errorf("%s: signature verifies for another message", name)
+		}
+		if Verify(&priv.PublicKey, hashed[:], s, r) || Verify(&priv.PublicKey, hashed[:], r, new(big.Int).Add(s, c.N)) {
+			t.Errorf("%s: tampered signature verifies", name)
+		}
+	}
+	if _, err := GenerateKey(nil, &ec.Curve{}); err != ErrCurve {
+		t.Errorf("GenerateKey on a curve without base point = %v; want %v", err, ErrCurve)
+	}
+	priv, _ := GenerateKey(nil, ec.Challenge59())
+	if _, _, err := SignWithNonce(priv, []byte{1}, priv.Curve.N); err != ErrNonce {
+		t.Errorf("SignWithNonce with k = N = %v; want %v", err, ErrNonce)
+	}
+}
+
+func TestStdlib(t *testing.T) {
+	std, err := stdecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv := &PrivateKey{PublicKey{fromStdlib(elliptic.P256()), ec.NewPoint(std.X, std.Y)}, std.D}
+	hashed := sha256.Sum256([]byte("interop"))
+	r, s, err := Sign(nil, priv, hashed[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stdecdsa.Verify(&std.PublicKey, hashed[:], r, s) {
+		t.Errorf("crypto/ecdsa rejects our signature")
+	}
+	if r, s, err = stdecdsa.Sign(rand.Reader, std, hashed[:]); err != nil {
+		t.Fatal(err)
+	}
+	if !Verify(&priv.PublicKey, hashed[:], r, s) {
+		t.Errorf("signature of crypto/ecdsa does not verify")
+	}
+}
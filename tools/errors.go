@@ -0,0 +1,14 @@
+package tools
+
+import "errors"
+
+// Errors returned by the checked cipher helpers. The unchecked variants panic
+// with the same conditions.
+var (
+	ErrBadPadding      = errors.New("bad padding")
+	ErrNotBlockAligned = errors.New("input is not a multiple of the block size")
+	ErrIVSize          = errors.New("IV length should be equal to block size")
+	ErrLengthMismatch  = errors.New("length of dst and src should be equal")
+	ErrBlockSize       = errors.New("CTR mode supports only 128bit blocks")
+	ErrOutOfRange      = errors.New("offset is out of range")
+)
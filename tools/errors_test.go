@@ -0,0 +1,69 @@
+package tools
+
+import (
+	"crypto/aes"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestCheckedErrors(t *testing.T) {
+	block, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	IV := make([]byte, 16)
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"CBCEncrypt short IV", CBCEncryptChecked(block, IV[:8], make([]byte, 16), make([]byte, 16)), ErrIVSize},
+		{"CBCEncrypt lengths", CBCEncryptChecked(block, IV, make([]byte, 16), make([]byte, 32)), ErrLengthMismatch},
+		{"CBCEncrypt unaligned", CBCEncryptChecked(block, IV, make([]byte, 17), make([]byte, 17)), ErrNotBlockAligned},
+		{"CBCDecrypt short IV", CBCDecryptChecked(block, nil, make([]byte, 16), make([]byte, 16)), ErrIVSize},
+		{"CBCDecrypt unaligned", CBCDecryptChecked(block, IV, make([]byte, 5), make([]byte, 5)), ErrNotBlockAligned},
+		{"CBCDecrypt ok", CBCDecryptChecked(block, IV, make([]byte, 32), make([]byte, 32)), nil},
+		{"ECBEncrypt lengths", ECBEncryptChecked(block, make([]byte, 16), make([]byte, 32)), ErrLengthMismatch},
+		{"ECBDecrypt unaligned", ECBDecryptChecked(block, make([]byte, 20), make([]byte, 20)), ErrNotBlockAligned},
+		{"ECBEncrypt ok", ECBEncryptChecked(block, make([]byte, 16), make([]byte, 16)), nil},
+		{"CTREncrypt lengths", CTREncryptChecked(block, 0, make([]byte, 3), make([]byte, 4)), ErrLengthMismatch},
+		{"CTREncrypt ok", CTREncryptChecked(block, 0, make([]byte, 5), make([]byte, 5)), nil},
+		{"CTREdit offset", CTREditChecked(make([]byte, 10), IV, 0, 11, nil), ErrOutOfRange},
+		{"CTREdit overflow", CTREditChecked(make([]byte, 10), IV, 0, 8, make([]byte, 3)), ErrOutOfRange},
+		{"CTREdit ok", CTREditChecked(make([]byte, 10), IV, 0, 8, make([]byte, 2)), nil},
+	}
+	for _, test := range tests {
+		if test.err != test.want {
+			t.Errorf("%s: got %v; want %v", test.name, test.err, test.want)
+		}
+	}
+	var keyErr aes.KeySizeError
+	if err := CTREditChecked(make([]byte, 10), IV[:5], 0, 0, nil); !errors.As(err, &keyErr) {
+		t.Errorf("CTREditChecked with a bad key = %v; want aes.KeySizeError", err)
+	}
+}
+
+func TestCheckedWrappersPanic(t *testing.T) {
+	block, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if r := recover(); r != ErrNotBlockAligned {
+			t.Errorf("CBCEncrypt panicked with %v; want %v", r, ErrNotBlockAligned)
+		}
+	}()
+	CBCEncrypt(block, make([]byte, 16), make([]byte, 3), make([]byte, 3))
+}
+
+func TestDHKEGenKeysChecked(t *testing.T) {
+	public, private := new(big.Int), new(big.Int)
+	p, g := big.NewInt(37), big.NewInt(5)
+	if err := DHKEGenKeysChecked(public, private, p, g); err != nil {
+		t.Fatal(err)
+	}
+	if new(big.Int).Exp(g, private, p).Cmp(public) != 0 {
+		t.Errorf("public key %v does not match private key %v", public, private)
+	}
+}
@@ -0,0 +1,130 @@
+// Package gcm is a readable implementation of the Galois/Counter Mode from
+// NIST SP 800-38D. Unlike crypto/cipher it exposes the pieces of the mode -
+// the authentication key H, the pre-counter block J0 and GHASH - so that the
+// attacks on GCM can be built on top of them.
+package gcm
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+
+	"github.com/ysmolsky/cryptopals/tools"
+)
+
+const (
+	// StandardNonceSize is the recommended 96 bit nonce size.
+	StandardNonceSize = 12
+	// MaxTagSize is the size of an untruncated tag.
+	MaxTagSize = 16
+)
+
+var (
+	ErrOpen    = errors.New("gcm: message authentication failed")
+	ErrTagSize = errors.New("gcm: tag size should be between 1 and 16 bytes")
+	ErrNonce   = errors.New("gcm: nonce should not be empty")
+)
+
+// GCM is an AES-GCM style AEAD over any 128 bit block cipher. It implements
+// cipher.AEAD.
+type GCM struct {
+	block   cipher.Block
+	h       []byte
+	tagSize int
+}
+
+// New returns GCM over block producing tags of tagSize bytes. Tags shorter
+// than MaxTagSize are the truncated tags of challenge 64.
+func New(block cipher.Block, tagSize int) (*GCM, error) {
+	if block.BlockSize() != BlockSize {
+		return nil, tools.ErrBlockSize
+	}
+	if tagSize < 1 || tagSize > MaxTagSize {
+		return nil, ErrTagSize
+	}
+	return &GCM{block: block, h: AuthKey(block), tagSize: tagSize}, nil
+}
+
+// AuthKey returns the authentication key H = E(K, 0^128).
+func AuthKey(block cipher.Block) []byte {
+	h := make([]byte, BlockSize)
+	block.Encrypt(h, h)
+	return h
+}
+
+// J0 derives the pre-counter block from nonce. A 96 bit nonce is extended to
+// nonce || 0^31 || 1, any other length is hashed with GHASH under h.
+func J0(h, nonce []byte) []byte {
+	if len(nonce) == StandardNonceSize {
+		j0 := make([]byte, BlockSize)
+		copy(j0, nonce)
+		j0[BlockSize-1] = 1
+		return j0
+	}
+	return GHASHBlocks(h, append(pad(nonce), LengthBlock(0, len(nonce))...))
+}
+
+// AuthKey returns the authentication key H of g.
+func (g *GCM) AuthKey() []byte {
+	return append([]byte(nil), g.h...)
+}
+
+// NonceSize returns the recommended nonce size, Seal and Open accept nonces
+// of any non-zero length.
+func (g *GCM) NonceSize() int { return StandardNonceSize }
+
+// Overhead returns the size of the tag.
+func (g *GCM) Overhead() int { return g.tagSize }
+
+// ctr returns the keystream used for the message, which starts at inc32(J0).
+func (g *GCM) ctr(j0 []byte) cipher.Stream {
+	initial := binary.BigEndian.Uint32(j0[12:]) + 1
+	s, err := tools.NewCTRStream(g.block, tools.GCMCTR(j0[:12], initial))
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Tag computes the full tag GHASH(aad, ct) + E(K, J0) before truncation.
+func (g *GCM) Tag(j0, aad, ct []byte) []byte {
+	s := make([]byte, BlockSize)
+	g.block.Encrypt(s, j0)
+	t := GHASH(g.h, aad, ct)
+	tools.XorBytesInplace(t, s)
+	return t
+}
+
+// Seal encrypts and authenticates plaintext and appends the ciphertext
+// followed by the tag to dst.
+func (g *GCM) Seal(dst, nonce, plaintext, aad []byte) []byte {
+	if len(nonce) == 0 {
+		panic(ErrNonce)
+	}
+	j0 := J0(g.h, nonce)
+	ct := make([]byte, len(plaintext), len(plaintext)+g.tagSize)
+	g.ctr(j0).XORKeyStream(ct, plaintext)
+	ct = append(ct, g.Tag(j0, aad, ct)[:g.tagSize]...)
+	return append(dst, ct...)
+}
+
+// Open authenticates and decrypts ciphertext and appends the plaintext to
+// dst. It returns ErrOpen if the tag does not match.
+func (g *GCM) Open(dst, nonce, ciphertext, aad []byte) ([]byte, error) {
+	if len(nonce) == 0 {
+		return nil, ErrNonce
+	}
+	if len(ciphertext) < g.tagSize {
+		return nil, ErrOpen
+	}
+	ct := ciphertext[:len(ciphertext)-g.tagSize]
+	tag := ciphertext[len(ciphertext)-g.tagSize:]
+	j0 := J0(g.h, nonce)
+	if subtle.ConstantTimeCompare(g.Tag(j0, aad, ct)[:g.tagSize], tag) != 1 {
+		return nil, ErrOpen
+	}
+	pt := make([]byte, len(ct))
+	g.ctr(j0).XORKeyStream(pt, ct)
+	return append(dst, pt...), nil
+}
@@ -0,0 +1,217 @@
+package gcm
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"math/rand"
+	"testing"
+)
+
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// Test cases 1-4 from "The Galois/Counter Mode of Operation (GCM)" by McGrew
+// and Viega, which are also used by NIST.
+var nistTests = []struct {
+	key, h, nonce, pt, aad, ct, tag string
+}{
+	{
+		"00000000000000000000000000000000",
+		"66e94bd4ef8a2c3b884cfa59ca342b2e",
+		"000000000000000000000000",
+		"",
+		"",
+		"",
+		"58e2fccefa7e3061367f1d57a4e7455a",
+	},
+	{
+		"00000000000000000000000000000000",
+		"66e94bd4ef8a2c3b884cfa59ca342b2e",
+		"000000000000000000000000",
+		"00000000000000000000000000000000",
+		"",
+		"0388dace60b6a392f328c2b971b2fe78",
+		"ab6e47d42cec13bdf53a67b21257bddf",
+	},
+	{
+		"feffe9928665731c6d6a8f9467308308",
+		"b83b533708bf535d0aa6e52980d53b78",
+		"cafebabefacedbaddecaf888",
+		"d9313225f88406e5a55909c5aff5269a86a7a9531534f7da2e4c303d8a318a72" +
+			"1c3c0c95956809532fcf0e2449a6b525b16aedf5aa0de657ba637b391aafd255",
+		"",
+		"42831ec2217774244b7221b784d0d49ce3aa212f2c02a4e035c17e2329aca12e" +
+			"21d514b25466931c7d8f6a5aac84aa051ba30b396a0aac973d58e091473f5985",
+		"4d5c2af327cd64a62cf35abd2ba6fab4",
+	},
+	{
+		"feffe9928665731c6d6a8f9467308308",
+		"b83b533708bf535d0aa6e52980d53b78",
+		"cafebabefacedbaddecaf888",
+		"d9313225f88406e5a55909c5aff5269a86a7a9531534f7da2e4c303d8a318a72" +
+			"1c3c0c95956809532fcf0e2449a6b525b16aedf5aa0de657ba637b39",
+		"feedfacedeadbeeffeedfacedeadbeefabaddad2",
+		"42831ec2217774244b7221b784d0d49ce3aa212f2c02a4e035c17e2329aca12e" +
+			"21d514b25466931c7d8f6a5aac84aa051ba30b396a0aac973d58e091",
+		"5bc94fbc3221a5db94fae95ae7121a47",
+	},
+}
+
+func TestNISTVectors(t *testing.T) {
+	for i, test := range nistTests {
+		block, err := aes.NewCipher(unhex(test.key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		g, err := New(block, MaxTagSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if h := g.AuthKey(); !bytes.Equal(h, unhex(test.h)) {
+			t.Errorf("test %d: H = %x; want %s", i+1, h, test.h)
+		}
+		out := g.Seal(nil, unhex(test.nonce), unhex(test.pt), unhex(test.aad))
+		exp := unhex(test.ct + test.tag)
+		if !bytes.Equal(out, exp) {
+			t.Errorf("test %d: Seal = %x; want %x", i+1, out, exp)
+		}
+		pt, err := g.Open(nil, unhex(test.nonce), exp, unhex(test.aad))
+		if err != nil || !bytes.Equal(pt, unhex(test.pt)) {
+			t.Errorf("test %d: Open = %x, %v; want %s", i+1, pt, err, test.pt)
+		}
+	}
+}
+
+func TestMatchesStdlib(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		key := make([]byte, []int{16, 24, 32}[i%3])
+		rng.Read(key)
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nonceSize := StandardNonceSize
+		if i%4 == 0 {
+			nonceSize = rng.Intn(64) + 1
+		}
+		tagSize := MaxTagSize
+		if i%5 == 0 {
+			tagSize = 12 + rng.Intn(4)
+		}
+		var std cipher.AEAD
+		switch {
+		case nonceSize != StandardNonceSize:
+			std, err = cipher.NewGCMWithNonceSize(block, nonceSize)
+		default:
+			std, err = cipher.NewGCMWithTagSize(block, tagSize)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if nonceSize != StandardNonceSize {
+			tagSize = MaxTagSize
+		}
+		g, err := New(block, tagSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		nonce := make([]byte, nonceSize)
+		pt := make([]byte, rng.Intn(200))
+		aad := make([]byte, rng.Intn(50))
+		rng.Read(nonce)
+		rng.Read(pt)
+		rng.Read(aad)
+
+		exp := std.Seal(nil, nonce, pt, aad)
+		got := g.Seal(nil, nonce, pt, aad)
+		if !bytes.Equal(got, exp) {
+			t.Fatalf("Seal(nonce %d, pt %d, aad %d, tag %d) differs from crypto/cipher",
+				nonceSize, len(pt), len(aad), tagSize)
+		}
+		dec, err := g.Open(nil, nonce, exp, aad)
+		if err != nil || !bytes.Equal(dec, pt) {
+			t.Fatalf("Open of a crypto/cipher message failed: %v", err)
+		}
+		exp[rng.Intn(len(exp))] ^= 1
+		if _, err := g.Open(nil, nonce, exp, aad); err != ErrOpen {
+			t.Fatalf("Open of a tampered message = %v; want %v", err, ErrOpen)
+		}
+	}
+}
+
+func TestTruncatedTag(t *testing.T) {
+	block, err := aes.NewCipher(make([]byte, 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	full, err := New(block, MaxTagSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	short, err := New(block, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := make([]byte, 12)
+	pt := []byte("attack at dawn")
+	ct := short.Seal(nil, nonce, pt, nil)
+	if len(ct) != len(pt)+4 {
+		t.Fatalf("truncated Seal returned %d bytes; want %d", len(ct), len(pt)+4)
+	}
+	if exp := full.Seal(nil, nonce, pt, nil); !bytes.Equal(ct, exp[:len(ct)]) {
+		t.Errorf("truncated tag is not a prefix of the full tag")
+	}
+	if got, err := short.Open(nil, nonce, ct, nil); err != nil || !bytes.Equal(got, pt) {
+		t.Errorf("Open with a truncated tag = %q, %v", got, err)
+	}
+	if _, err := New(block, 0); err != ErrTagSize {
+		t.Errorf("New(block, 0) = %v; want %v", err, ErrTagSize)
+	}
+}
+
+func TestGHASHPolynomial(t *testing.T) {
+	// GHASH is the evaluation of a polynomial at h:
+	// a0*h^3 + c0*h^2 + len*h
+	h := unhex("66e94bd4ef8a2c3b884cfa59ca342b2e")
+	aad := unhex("0102030405060708090a0b0c0d0e0f10")
+	ct := unhex("f0e0d0c0b0a090807060504030201000")
+	l := LengthBlock(len(aad), len(ct))
+	h2 := Mul(h, h)
+	h3 := Mul(h2, h)
+	exp := Mul(aad, h3)
+	for i, b := range Mul(ct, h2) {
+		exp[i] ^= b
+	}
+	for i, b := range Mul(l, h) {
+		exp[i] ^= b
+	}
+	if got := GHASH(h, aad, ct); !bytes.Equal(got, exp) {
+		t.Errorf("GHASH = %x; want %x", got, exp)
+	}
+}
+
+func TestJ0(t *testing.T) {
+	h := make([]byte, BlockSize)
+	nonce := unhex("cafebabefacedbaddecaf888")
+	if got, exp := J0(h, nonce), unhex("cafebabefacedbaddecaf88800000001"); !bytes.Equal(got, exp) {
+		t.Errorf("J0(96 bit nonce) = %x; want %x", got, exp)
+	}
+	// GHASH with h = 1 (the x^0 bit) is the xor of all input blocks
+	one := make([]byte, BlockSize)
+	one[0] = 0x80
+	long := unhex("000102030405060708090a0b0c0d0e0f10")
+	exp := unhex("100102030405060708090a0b0c0d0e0f")
+	exp[15] ^= 17 * 8
+	if got := J0(one, long); !bytes.Equal(got, exp) {
+		t.Errorf("J0(136 bit nonce) = %x; want %x", got, exp)
+	}
+}
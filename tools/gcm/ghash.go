@@ -0,0 +1,68 @@
+package gcm
+
+import (
+	"encoding/binary"
+
+	"github.com/ysmolsky/cryptopals/tools/gf128"
+)
+
+// BlockSize is the size of a GHASH block and of the authentication key.
+const BlockSize = 16
+
+// Mul multiplies two 16 byte blocks as elements of GF(2^128) in the bit order
+// of GCM, see package gf128.
+func Mul(x, y []byte) []byte {
+	return gf128.FromBytes(x).Mul(gf128.FromBytes(y)).Bytes()
+}
+
+// GHASHBlocks computes GHASH_H over blocks, which must be a multiple of
+// BlockSize long:
+//
+//	g := 0
+//	for b in blocks:
+//	    g := (g + b) * h
+func GHASHBlocks(h, blocks []byte) []byte {
+	if len(blocks)%BlockSize != 0 {
+		panic("gcm: GHASH input is not a multiple of the block size")
+	}
+	hh := gf128.FromBytes(h)
+	var g gf128.Element
+	for i := 0; i < len(blocks); i += BlockSize {
+		g = g.Add(gf128.FromBytes(blocks[i : i+BlockSize])).Mul(hh)
+	}
+	return g.Bytes()
+}
+
+// HashInput returns the blocks GHASH is computed over for the given
+// associated data and ciphertext: both zero-padded to the block size and
+// followed by a block holding their bit lengths.
+func HashInput(aad, ct []byte) []byte {
+	var res []byte
+	res = append(res, pad(aad)...)
+	res = append(res, pad(ct)...)
+	return append(res, LengthBlock(len(aad), len(ct))...)
+}
+
+// LengthBlock returns len(AD) || len(C) as 64 bit big-endian bit lengths.
+func LengthBlock(aadLen, ctLen int) []byte {
+	l := make([]byte, BlockSize)
+	binary.BigEndian.PutUint64(l[:8], uint64(aadLen)*8)
+	binary.BigEndian.PutUint64(l[8:], uint64(ctLen)*8)
+	return l
+}
+
+// GHASH computes the GCM hash of associated data and ciphertext under the
+// authentication key h.
+func GHASH(h, aad, ct []byte) []byte {
+	return GHASHBlocks(h, HashInput(aad, ct))
+}
+
+// pad zero-pads b to a multiple of BlockSize.
+func pad(b []byte) []byte {
+	if len(b)%BlockSize == 0 {
+		return b
+	}
+	res := make([]byte, len(b)+BlockSize-len(b)%BlockSize)
+	copy(res, b)
+	return res
+}
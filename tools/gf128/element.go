@@ -0,0 +1,125 @@
+// Package gf128 implements arithmetic in GF(2^128) as used by GCM and in the
+// ring of polynomials over that field, including the factorisation needed to
+// recover the GCM authentication key from a repeated nonce.
+package gf128
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+)
+
+// Element is an element of GF(2^128) = GF(2)[x]/(x^128 + x^7 + x^2 + x + 1)
+// in the bit order of GCM: the most significant bit of hi is the coefficient
+// of x^0 and the least significant bit of lo the coefficient of x^127.
+type Element struct {
+	hi, lo uint64
+}
+
+var (
+	// Zero is the additive identity.
+	Zero = Element{}
+	// One is the multiplicative identity.
+	One = Element{hi: 1 << 63}
+	// X is the element x, the generator of the field.
+	X = Element{hi: 1 << 62}
+)
+
+// order is the size of the multiplicative group, 2^128 - 1.
+var order = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+
+// FromBytes converts a 16 byte block into a field element.
+func FromBytes(b []byte) Element {
+	if len(b) != 16 {
+		panic("gf128: element should be 16 bytes long")
+	}
+	return Element{binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:])}
+}
+
+// Bytes converts e into a 16 byte block.
+func (e Element) Bytes() []byte {
+	b := make([]byte, 16)
+	binary.BigEndian.PutUint64(b[:8], e.hi)
+	binary.BigEndian.PutUint64(b[8:], e.lo)
+	return b
+}
+
+func (e Element) String() string {
+	return fmt.Sprintf("%016x%016x", e.hi, e.lo)
+}
+
+// IsZero reports whether e is zero.
+func (e Element) IsZero() bool {
+	return e == Zero
+}
+
+// Add returns e + f, which is also e - f.
+func (e Element) Add(f Element) Element {
+	return Element{e.hi ^ f.hi, e.lo ^ f.lo}
+}
+
+// Mul returns e * f.
+func (e Element) Mul(f Element) Element {
+	var z Element
+	v := f
+	for i := 0; i < 128; i++ {
+		var bit uint64
+		if i < 64 {
+			bit = e.hi >> (63 - i) & 1
+		} else {
+			bit = e.lo >> (127 - i) & 1
+		}
+		if bit == 1 {
+			z.hi ^= v.hi
+			z.lo ^= v.lo
+		}
+		// multiply v by x and reduce by the field polynomial
+		carry := v.lo & 1
+		v.lo = v.lo>>1 | v.hi<<63
+		v.hi >>= 1
+		if carry == 1 {
+			v.hi ^= 0xe1 << 56
+		}
+	}
+	return z
+}
+
+// Square returns e * e.
+func (e Element) Square() Element {
+	return e.Mul(e)
+}
+
+// Exp returns e^n for n >= 0.
+func (e Element) Exp(n *big.Int) Element {
+	res := One
+	for i := n.BitLen() - 1; i >= 0; i-- {
+		res = res.Square()
+		if n.Bit(i) == 1 {
+			res = res.Mul(e)
+		}
+	}
+	return res
+}
+
+// Inv returns the multiplicative inverse of e, it panics if e is zero.
+func (e Element) Inv() Element {
+	if e.IsZero() {
+		panic("gf128: inverse of zero")
+	}
+	// e^(2^128 - 2) = e^-1
+	return e.Exp(new(big.Int).Sub(order, big.NewInt(1)))
+}
+
+// Div returns e / f.
+func (e Element) Div(f Element) Element {
+	return e.Mul(f.Inv())
+}
+
+// Sqrt returns the unique square root of e, which is e^(2^127) because
+// squaring is the Frobenius automorphism of the field.
+func (e Element) Sqrt() Element {
+	for i := 0; i < 127; i++ {
+		e = e.Square()
+	}
+	return e
+}
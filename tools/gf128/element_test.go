@@ -0,0 +1,78 @@
+package gf128
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+func randElement() Element {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return FromBytes(b)
+}
+
+func TestMulKnown(t *testing.T) {
+	// GHASH of a single zero-key block from GCM test case 2:
+	// C * H^2 + L * H, computed step by step.
+	h, _ := hex.DecodeString("66e94bd4ef8a2c3b884cfa59ca342b2e")
+	c, _ := hex.DecodeString("0388dace60b6a392f328c2b971b2fe78")
+	l, _ := hex.DecodeString("00000000000000000000000000000080")
+	hh := FromBytes(h)
+	g := FromBytes(c).Mul(hh).Add(FromBytes(l)).Mul(hh)
+	if exp := "f38cbb1ad69223dcc3457ae5b6b0f885"; g.String() != exp {
+		t.Errorf("GHASH = %v; want %s", g, exp)
+	}
+}
+
+func TestFieldAxioms(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		a, b, c := randElement(), randElement(), randElement()
+		if a.Mul(b) != b.Mul(a) {
+			t.Fatalf("%v * %v is not commutative", a, b)
+		}
+		if a.Mul(b).Mul(c) != a.Mul(b.Mul(c)) {
+			t.Fatalf("multiplication is not associative")
+		}
+		if a.Mul(b.Add(c)) != a.Mul(b).Add(a.Mul(c)) {
+			t.Fatalf("multiplication does not distribute over addition")
+		}
+		if a.Mul(One) != a || a.Add(a) != Zero {
+			t.Fatalf("identities do not hold for %v", a)
+		}
+		if a.IsZero() {
+			continue
+		}
+		if a.Mul(a.Inv()) != One {
+			t.Fatalf("%v * %v^-1 != 1", a, a)
+		}
+		if a.Mul(b).Div(a) != b {
+			t.Fatalf("(a*b)/a != b")
+		}
+		if a.Sqrt().Square() != a {
+			t.Fatalf("sqrt(%v)^2 != %v", a, a)
+		}
+	}
+}
+
+func TestExp(t *testing.T) {
+	a := randElement()
+	exp := One
+	for i := 0; i < 20; i++ {
+		if got := a.Exp(big.NewInt(int64(i))); got != exp {
+			t.Fatalf("%v^%d = %v; want %v", a, i, got, exp)
+		}
+		exp = exp.Mul(a)
+	}
+	if a.Exp(order) != One {
+		t.Errorf("a^(2^128-1) != 1")
+	}
+	// x^128 = x^7 + x^2 + x + 1
+	x128 := X.Exp(big.NewInt(128))
+	if exp := (Element{hi: 0xe1 << 56}); x128 != exp {
+		t.Errorf("x^128 = %v; want %v", x128, exp)
+	}
+}
@@ -0,0 +1,194 @@
+package gf128
+
+import "math/big"
+
+// Factor is a factor of a polynomial together with its multiplicity.
+type Factor struct {
+	P    Poly
+	Mult int
+}
+
+// DegreeFactor is the product of all irreducible factors of degree Deg of a
+// square-free polynomial.
+type DegreeFactor struct {
+	P   Poly
+	Deg int
+}
+
+// y is the polynomial y.
+var y = NewPoly(Zero, One)
+
+// sqrt returns the square root of a polynomial which only has terms of even
+// degree, that is one which is the square of another polynomial.
+func (p Poly) sqrt() Poly {
+	res := make(Poly, len(p)/2+1)
+	for i := 0; i < len(p); i += 2 {
+		res[i/2] = p[i].Sqrt()
+	}
+	return res.trim()
+}
+
+// SquareFree returns the square-free factorisation of the monic polynomial f:
+// square-free, pairwise coprime polynomials whose product, each raised to its
+// multiplicity, is f. The zero polynomial has no factorisation and gives
+// nil.
+func SquareFree(f Poly) []Factor {
+	if f.IsZero() {
+		return nil
+	}
+	f = f.Monic()
+	var res []Factor
+	c := GCD(f, f.Derivative())
+	w := f.Div(c)
+	for i := 1; !w.IsOne(); i++ {
+		g := GCD(w, c)
+		if fac := w.Div(g); !fac.IsOne() {
+			res = append(res, Factor{fac, i})
+		}
+		w = g
+		c = c.Div(g)
+	}
+	// Whatever is left in c has a zero derivative, so it is a square.
+	if !c.IsOne() {
+		for _, fac := range SquareFree(c.sqrt()) {
+			res = append(res, Factor{fac.P, 2 * fac.Mult})
+		}
+	}
+	return res
+}
+
+// frobenius returns h^q mod m for q = 2^128.
+func frobenius(h, m Poly) Poly {
+	for i := 0; i < 128; i++ {
+		h = h.Mul(h).Mod(m)
+	}
+	return h
+}
+
+// DistinctDegree splits the monic square-free polynomial f into products of
+// irreducible factors of equal degree. It returns nil for the zero
+// polynomial.
+func DistinctDegree(f Poly) []DegreeFactor {
+	if f.IsZero() {
+		return nil
+	}
+	var res []DegreeFactor
+	rest := f.Monic()
+	h := y.Mod(rest)
+	for i := 1; 2*i <= rest.Deg(); i++ {
+		// h = y^(q^i) mod rest, the irreducible factors of degree i are
+		// exactly the factors of y^(q^i) - y
+		h = frobenius(h, rest)
+		g := GCD(rest, h.Add(y))
+		if !g.IsOne() {
+			res = append(res, DegreeFactor{g, i})
+			rest = rest.Div(g)
+			h = h.Mod(rest)
+		}
+	}
+	if rest.Deg() > 0 {
+		res = append(res, DegreeFactor{rest, rest.Deg()})
+	}
+	return res
+}
+
+// EqualDegree splits the monic square-free polynomial f, whose irreducible
+// factors all have degree d, into those factors using Cantor-Zassenhaus.
+// It returns nil for the zero polynomial.
+func EqualDegree(f Poly, d int) []Poly {
+	if f.IsZero() {
+		return nil
+	}
+	f = f.Monic()
+	n := f.Deg()
+	if n <= d {
+		return []Poly{f}
+	}
+	r := n / d
+	// q^d - 1 is divisible by 3 for q = 2^128, raising to (q^d - 1)/3 maps
+	// into the subgroup of order 3 of every factor's field.
+	e := new(big.Int).Lsh(big.NewInt(1), uint(128*d))
+	e.Sub(e, big.NewInt(1))
+	e.Div(e, big.NewInt(3))
+	one := NewPoly(One)
+
+	S := []Poly{f}
+	for len(S) < r {
+		h := RandomPoly(n)
+		g := GCD(h, f)
+		if g.IsOne() {
+			g = h.ExpMod(e, f).Add(one)
+		}
+		var next []Poly
+		for _, u := range S {
+			if u.Deg() == d {
+				next = append(next, u)
+				continue
+			}
+			gu := GCD(g, u)
+			if gu.IsOne() || gu.Deg() == u.Deg() {
+				next = append(next, u)
+				continue
+			}
+			next = append(next, gu, u.Div(gu))
+		}
+		S = next
+	}
+	return S
+}
+
+// Factorize returns the monic irreducible factors of f with their
+// multiplicities. The leading coefficient of f is dropped.
+func Factorize(f Poly) []Factor {
+	var res []Factor
+	for _, sf := range SquareFree(f) {
+		for _, df := range DistinctDegree(sf.P) {
+			for _, p := range EqualDegree(df.P, df.Deg) {
+				res = append(res, Factor{p, sf.Mult})
+			}
+		}
+	}
+	return res
+}
+
+// Roots returns the distinct roots of f in GF(2^128). Every element is a
+// root of the zero polynomial, for which it returns nil.
+func (f Poly) Roots() []Element {
+	var res []Element
+	for _, sf := range SquareFree(f) {
+		for _, df := range DistinctDegree(sf.P) {
+			if df.Deg != 1 {
+				continue
+			}
+			for _, p := range EqualDegree(df.P, 1) {
+				// y + c = 0 means y = c in characteristic 2
+				res = append(res, p[0])
+			}
+		}
+	}
+	return res
+}
+
+// TagPoly returns the polynomial f with f(H) = E(K, J0) for a GCM message,
+// where blocks is the GHASH input (see gcm.HashInput) and tag the full 16
+// byte tag:
+//
+//	f(y) = b0*y^n + b1*y^(n-1) + ... + b(n-1)*y + tag
+func TagPoly(blocks, tag []byte) Poly {
+	n := len(blocks) / 16
+	f := make(Poly, n+1)
+	f[0] = FromBytes(tag)
+	for i := 0; i < n; i++ {
+		f[n-i] = FromBytes(blocks[16*i : 16*(i+1)])
+	}
+	return f.trim()
+}
+
+// RepeatedNonceRoots returns the candidates for the GCM authentication key H
+// given two messages sealed under the same key and nonce. Adding their tag
+// polynomials cancels the nonce mask, so H is a root of the sum. Two
+// identical messages give the zero polynomial, which says nothing about H,
+// and no candidates.
+func RepeatedNonceRoots(blocks1, tag1, blocks2, tag2 []byte) []Element {
+	return TagPoly(blocks1, tag1).Add(TagPoly(blocks2, tag2)).Roots()
+}
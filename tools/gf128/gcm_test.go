@@ -0,0 +1,59 @@
+package gf128_test
+
+import (
+	"crypto/aes"
+	"testing"
+
+	"github.com/ysmolsky/cryptopals/tools"
+	"github.com/ysmolsky/cryptopals/tools/gcm"
+	"github.com/ysmolsky/cryptopals/tools/gf128"
+)
+
+func TestRepeatedNonceRoots(t *testing.T) {
+	block, err := aes.NewCipher(tools.RandBytes(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := gcm.New(block, gcm.MaxTagSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := tools.RandBytes(gcm.StandardNonceSize)
+	aad1, pt1 := []byte("header one"), []byte("the first message, a bit longer than a block")
+	aad2, pt2 := []byte("second header"), []byte("another message")
+	out1 := g.Seal(nil, nonce, pt1, aad1)
+	out2 := g.Seal(nil, nonce, pt2, aad2)
+	ct1, tag1 := out1[:len(pt1)], out1[len(pt1):]
+	ct2, tag2 := out2[:len(pt2)], out2[len(pt2):]
+
+	roots := gf128.RepeatedNonceRoots(gcm.HashInput(aad1, ct1), tag1, gcm.HashInput(aad2, ct2), tag2)
+	h := gf128.FromBytes(g.AuthKey())
+	found := false
+	for _, r := range roots {
+		if r == h {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("H = %v is not among the candidates %v", h, roots)
+	}
+}
+
+func TestRepeatedNonceRootsIdentical(t *testing.T) {
+	block, err := aes.NewCipher(tools.RandBytes(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := gcm.New(block, gcm.MaxTagSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := tools.RandBytes(gcm.StandardNonceSize)
+	aad, pt := []byte("header"), []byte("the same message twice")
+	out := g.Seal(nil, nonce, pt, aad)
+	ct, tag := out[:len(pt)], out[len(pt):]
+	blocks := gcm.HashInput(aad, ct)
+	if roots := gf128.RepeatedNonceRoots(blocks, tag, blocks, tag); roots != nil {
+		t.Errorf("identical messages give candidates %v; want none", roots)
+	}
+}
@@ -0,0 +1,235 @@
+package gf128
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Poly is a polynomial over GF(2^128), p[i] is the coefficient of y^i. All
+// operations return trimmed polynomials without zero leading coefficients, so
+// the zero polynomial is empty.
+type Poly []Element
+
+// NewPoly returns the polynomial with the given coefficients, lowest degree
+// first.
+func NewPoly(coeffs ...Element) Poly {
+	return Poly(append([]Element(nil), coeffs...)).trim()
+}
+
+// Monomial returns c*y^n.
+func Monomial(c Element, n int) Poly {
+	p := make(Poly, n+1)
+	p[n] = c
+	return p.trim()
+}
+
+func (p Poly) trim() Poly {
+	for len(p) > 0 && p[len(p)-1].IsZero() {
+		p = p[:len(p)-1]
+	}
+	return p
+}
+
+// Deg returns the degree of p, -1 for the zero polynomial.
+func (p Poly) Deg() int {
+	return len(p.trim()) - 1
+}
+
+// Lead returns the leading coefficient of p.
+func (p Poly) Lead() Element {
+	p = p.trim()
+	if len(p) == 0 {
+		return Zero
+	}
+	return p[len(p)-1]
+}
+
+// IsZero reports whether p is the zero polynomial.
+func (p Poly) IsZero() bool {
+	return p.Deg() < 0
+}
+
+// IsOne reports whether p is the constant 1.
+func (p Poly) IsOne() bool {
+	return p.Deg() == 0 && p[0] == One
+}
+
+// Equal reports whether p and q are the same polynomial.
+func (p Poly) Equal(q Poly) bool {
+	p, q = p.trim(), q.trim()
+	if len(p) != len(q) {
+		return false
+	}
+	for i := range p {
+		if p[i] != q[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (p Poly) String() string {
+	p = p.trim()
+	if len(p) == 0 {
+		return "0"
+	}
+	var terms []string
+	for i := len(p) - 1; i >= 0; i-- {
+		if p[i].IsZero() {
+			continue
+		}
+		switch i {
+		case 0:
+			terms = append(terms, p[i].String())
+		case 1:
+			terms = append(terms, p[i].String()+"*y")
+		default:
+			terms = append(terms, p[i].String()+"*y^"+strconv.Itoa(i))
+		}
+	}
+	return strings.Join(terms, " + ")
+}
+
+// Add returns p + q, which is also p - q.
+func (p Poly) Add(q Poly) Poly {
+	if len(p) < len(q) {
+		p, q = q, p
+	}
+	res := append(Poly(nil), p...)
+	for i := range q {
+		res[i] = res[i].Add(q[i])
+	}
+	return res.trim()
+}
+
+// Scale returns c*p.
+func (p Poly) Scale(c Element) Poly {
+	res := make(Poly, len(p))
+	for i := range p {
+		res[i] = p[i].Mul(c)
+	}
+	return res.trim()
+}
+
+// Mul returns p * q.
+func (p Poly) Mul(q Poly) Poly {
+	p, q = p.trim(), q.trim()
+	if len(p) == 0 || len(q) == 0 {
+		return nil
+	}
+	res := make(Poly, len(p)+len(q)-1)
+	for i := range p {
+		if p[i].IsZero() {
+			continue
+		}
+		for j := range q {
+			res[i+j] = res[i+j].Add(p[i].Mul(q[j]))
+		}
+	}
+	return res.trim()
+}
+
+// DivMod returns the quotient and the remainder of p / q. It panics if q is
+// zero.
+func (p Poly) DivMod(q Poly) (Poly, Poly) {
+	q = q.trim()
+	if len(q) == 0 {
+		panic("gf128: division by zero polynomial")
+	}
+	r := append(Poly(nil), p.trim()...)
+	if len(r) < len(q) {
+		return nil, r
+	}
+	quo := make(Poly, len(r)-len(q)+1)
+	inv := q.Lead().Inv()
+	for d := len(r) - len(q); d >= 0; d-- {
+		c := r[d+len(q)-1].Mul(inv)
+		if c.IsZero() {
+			continue
+		}
+		quo[d] = c
+		for i := range q {
+			r[d+i] = r[d+i].Add(c.Mul(q[i]))
+		}
+	}
+	return quo.trim(), r.trim()
+}
+
+// Div returns the quotient of p / q.
+func (p Poly) Div(q Poly) Poly {
+	quo, _ := p.DivMod(q)
+	return quo
+}
+
+// Mod returns the remainder of p / q.
+func (p Poly) Mod(q Poly) Poly {
+	_, r := p.DivMod(q)
+	return r
+}
+
+// Monic returns p divided by its leading coefficient.
+func (p Poly) Monic() Poly {
+	if p.IsZero() {
+		return nil
+	}
+	return p.Scale(p.Lead().Inv())
+}
+
+// Eval returns p(x).
+func (p Poly) Eval(x Element) Element {
+	var res Element
+	for i := len(p) - 1; i >= 0; i-- {
+		res = res.Mul(x).Add(p[i])
+	}
+	return res
+}
+
+// Derivative returns the formal derivative of p. In characteristic 2 the
+// terms of even degree vanish.
+func (p Poly) Derivative() Poly {
+	if len(p) < 2 {
+		return nil
+	}
+	res := make(Poly, len(p)-1)
+	for i := 1; i < len(p); i += 2 {
+		res[i-1] = p[i]
+	}
+	return res.trim()
+}
+
+// ExpMod returns p^n mod m.
+func (p Poly) ExpMod(n *big.Int, m Poly) Poly {
+	res := NewPoly(One).Mod(m)
+	base := p.Mod(m)
+	for i := n.BitLen() - 1; i >= 0; i-- {
+		res = res.Mul(res).Mod(m)
+		if n.Bit(i) == 1 {
+			res = res.Mul(base).Mod(m)
+		}
+	}
+	return res
+}
+
+// GCD returns the monic greatest common divisor of p and q.
+func GCD(p, q Poly) Poly {
+	p, q = p.trim(), q.trim()
+	for !q.IsZero() {
+		p, q = q, p.Mod(q)
+	}
+	return p.Monic()
+}
+
+// RandomPoly returns a uniformly random polynomial of degree less than n.
+func RandomPoly(n int) Poly {
+	buf := make([]byte, 16*n)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	p := make(Poly, n)
+	for i := range p {
+		p[i] = FromBytes(buf[16*i : 16*(i+1)])
+	}
+	return p.trim()
+}
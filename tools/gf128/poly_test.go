@@ -0,0 +1,184 @@
+package gf128
+
+import (
+	"math/big"
+	"testing"
+)
+
+// linear returns y + c, which has the root c.
+func linear(c Element) Poly {
+	return NewPoly(c, One)
+}
+
+func randPoly(n int) Poly {
+	for {
+		if p := RandomPoly(n + 1); p.Deg() == n {
+			return p
+		}
+	}
+}
+
+func TestDivMod(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		p := randPoly(7 + i%5)
+		q := randPoly(1 + i%6)
+		quo, rem := p.DivMod(q)
+		if rem.Deg() >= q.Deg() {
+			t.Fatalf("deg(rem) = %d >= deg(q) = %d", rem.Deg(), q.Deg())
+		}
+		if !quo.Mul(q).Add(rem).Equal(p) {
+			t.Fatalf("quo*q + rem != p")
+		}
+	}
+}
+
+func TestGCD(t *testing.T) {
+	common := randPoly(3)
+	a := common.Mul(randPoly(4))
+	b := common.Mul(randPoly(5))
+	g := GCD(a, b)
+	if g.Lead() != One {
+		t.Errorf("GCD is not monic: %v", g)
+	}
+	if !a.Mod(g).IsZero() || !b.Mod(g).IsZero() {
+		t.Errorf("GCD does not divide its arguments")
+	}
+	if !g.Mod(common.Monic()).IsZero() {
+		t.Errorf("GCD %v is not a multiple of the common factor", g)
+	}
+}
+
+func TestEvalAndExpMod(t *testing.T) {
+	p := randPoly(5)
+	m := randPoly(3)
+	x := randElement()
+	if p.Mul(p).Eval(x) != p.Eval(x).Square() {
+		t.Errorf("Eval does not agree with Mul")
+	}
+	p3 := p.Mul(p).Mul(p).Mod(m)
+	if got := p.ExpMod(big.NewInt(3), m); !got.Equal(p3) {
+		t.Errorf("ExpMod(3) = %v; want %v", got, p3)
+	}
+}
+
+func TestSquareFree(t *testing.T) {
+	a, b, c := randPoly(1).Monic(), randPoly(2).Monic(), randPoly(1).Monic()
+	// f = a * b^2 * c^3
+	f := a.Mul(b).Mul(b).Mul(c).Mul(c).Mul(c)
+	got := NewPoly(One)
+	for _, fac := range SquareFree(f) {
+		if !GCD(fac.P, fac.P.Derivative()).IsOne() {
+			t.Errorf("factor %v is not square-free", fac.P)
+		}
+		for i := 0; i < fac.Mult; i++ {
+			got = got.Mul(fac.P)
+		}
+		switch fac.Mult {
+		case 1:
+			if !fac.P.Equal(a) {
+				t.Errorf("multiplicity 1 factor = %v; want %v", fac.P, a)
+			}
+		case 2:
+			if !fac.P.Equal(b) {
+				t.Errorf("multiplicity 2 factor = %v; want %v", fac.P, b)
+			}
+		case 3:
+			if !fac.P.Equal(c) {
+				t.Errorf("multiplicity 3 factor = %v; want %v", fac.P, c)
+			}
+		default:
+			t.Errorf("unexpected multiplicity %d", fac.Mult)
+		}
+	}
+	if !got.Equal(f.Monic()) {
+		t.Errorf("product of square-free factors differs from f")
+	}
+}
+
+func TestZeroPoly(t *testing.T) {
+	zero := NewPoly(Zero, Zero)
+	if got := SquareFree(zero); got != nil {
+		t.Errorf("SquareFree(0) = %v; want nil", got)
+	}
+	if got := DistinctDegree(zero); got != nil {
+		t.Errorf("DistinctDegree(0) = %v; want nil", got)
+	}
+	if got := EqualDegree(zero, 1); got != nil {
+		t.Errorf("EqualDegree(0, 1) = %v; want nil", got)
+	}
+	if got := Factorize(zero); got != nil {
+		t.Errorf("Factorize(0) = %v; want nil", got)
+	}
+	if got := zero.Roots(); got != nil {
+		t.Errorf("Roots of 0 = %v; want nil", got)
+	}
+}
+
+func TestFactorize(t *testing.T) {
+	r1, r2, r3 := randElement(), randElement(), randElement()
+	// an irreducible quadratic: y^2 + y + a has no root for a suitable a,
+	// find one by trial
+	var quad Poly
+	for {
+		quad = NewPoly(randElement(), One, One)
+		if len(quad.Roots()) == 0 {
+			break
+		}
+	}
+	f := linear(r1).Mul(linear(r2)).Mul(linear(r2)).Mul(linear(r3)).Mul(quad).Scale(randElement())
+
+	factors := Factorize(f)
+	prod := NewPoly(One)
+	degrees := map[int]int{}
+	for _, fac := range factors {
+		for i := 0; i < fac.Mult; i++ {
+			prod = prod.Mul(fac.P)
+		}
+		degrees[fac.P.Deg()] += fac.Mult
+	}
+	if !prod.Equal(f.Monic()) {
+		t.Errorf("product of factors differs from f")
+	}
+	if degrees[1] != 4 || degrees[2] != 1 || len(degrees) != 2 {
+		t.Errorf("factor degrees = %v; want four linear and one quadratic", degrees)
+	}
+
+	roots := f.Roots()
+	if len(roots) != 3 {
+		t.Fatalf("found %d roots; want 3", len(roots))
+	}
+	for _, r := range roots {
+		if !f.Eval(r).IsZero() {
+			t.Errorf("%v is not a root", r)
+		}
+		if r != r1 && r != r2 && r != r3 {
+			t.Errorf("unexpected root %v", r)
+		}
+	}
+}
+
+func TestDistinctDegree(t *testing.T) {
+	var quads Poly = NewPoly(One)
+	for n := 0; n < 2; {
+		q := NewPoly(randElement(), randElement(), One)
+		if len(q.Roots()) == 0 {
+			quads = quads.Mul(q)
+			n++
+		}
+	}
+	lin := linear(randElement()).Mul(linear(randElement()))
+	dd := DistinctDegree(lin.Mul(quads))
+	if len(dd) != 2 {
+		t.Fatalf("DistinctDegree returned %d parts; want 2", len(dd))
+	}
+	if dd[0].Deg != 1 || !dd[0].P.Equal(lin) {
+		t.Errorf("degree 1 part = %v", dd[0].P)
+	}
+	if dd[1].Deg != 2 || !dd[1].P.Equal(quads) {
+		t.Errorf("degree 2 part = %v", dd[1].P)
+	}
+	parts := EqualDegree(dd[1].P, 2)
+	if len(parts) != 2 || parts[0].Deg() != 2 || !parts[0].Mul(parts[1]).Equal(quads) {
+		t.Errorf("EqualDegree did not split the quadratics: %v", parts)
+	}
+}
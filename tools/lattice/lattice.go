@@ -0,0 +1,247 @@
+// Package lattice implements the LLL basis reduction of challenge 62 on
+// vectors of rationals or integers. Everything is exact: the Gram-Schmidt
+// data is kept as integers and updated in place instead of being recomputed
+// after every step, which is what makes the reduction fast enough for the
+// hidden number problem.
+package lattice
+
+import (
+	"errors"
+	"math/big"
+)
+
+// ErrDependent is returned by Reduce for vectors which are not linearly
+// independent and so are not the basis of a lattice.
+var ErrDependent = errors.New("lattice: basis vectors are linearly dependent")
+
+// Vector is a row vector of rationals.
+type Vector []*big.Rat
+
+// NewVector returns the vector of the integers xs.
+func NewVector(xs ...int64) Vector {
+	v := make(Vector, len(xs))
+	for i, x := range xs {
+		v[i] = big.NewRat(x, 1)
+	}
+	return v
+}
+
+// Copy returns a deep copy of v.
+func (v Vector) Copy() Vector {
+	w := make(Vector, len(v))
+	for i, x := range v {
+		w[i] = new(big.Rat).Set(x)
+	}
+	return w
+}
+
+// Dot returns the inner product of v and w.
+func (v Vector) Dot(w Vector) *big.Rat {
+	sum, t := new(big.Rat), new(big.Rat)
+	for i := range v {
+		sum.Add(sum, t.Mul(v[i], w[i]))
+	}
+	return sum
+}
+
+// subMul sets v to v - c*w.
+func (v Vector) subMul(c *big.Rat, w Vector) {
+	t := new(big.Rat)
+	for i := range v {
+		v[i].Sub(v[i], t.Mul(c, w[i]))
+	}
+}
+
+// String formats v like [1/2 -1 0 0].
+func (v Vector) String() string {
+	s := "["
+	for i, x := range v {
+		if i > 0 {
+			s += " "
+		}
+		s += x.RatString()
+	}
+	return s + "]"
+}
+
+// GramSchmidt returns the orthogonal basis of the span of b given by
+// b*[i] = b[i] - sum(mu[i][j] b*[j], j < i). Zero vectors appear where b is
+// linearly dependent.
+func GramSchmidt(b []Vector) []Vector {
+	bs := make([]Vector, len(b))
+	for i := range b {
+		bs[i] = b[i].Copy()
+		for j := 0; j < i; j++ {
+			nj := bs[j].Dot(bs[j])
+			if nj.Sign() == 0 {
+				continue
+			}
+			bs[i].subMul(nj.Quo(b[i].Dot(bs[j]), nj), bs[j])
+		}
+	}
+	return bs
+}
+
+// roundQuo returns a/b rounded to the nearest integer, halves rounded up,
+// for b > 0.
+func roundQuo(a, b *big.Int) *big.Int {
+	// floor((2a + b) / 2b), Div rounds down for positive divisors
+	num := new(big.Int).Lsh(a, 1)
+	num.Add(num, b)
+	return num.Div(num, new(big.Int).Lsh(b, 1))
+}
+
+// dot returns the inner product of the integer vectors v and w.
+func dot(v, w []*big.Int) *big.Int {
+	sum, t := new(big.Int), new(big.Int)
+	for i := range v {
+		sum.Add(sum, t.Mul(v[i], w[i]))
+	}
+	return sum
+}
+
+// Reduce returns an LLL-reduced basis of the lattice spanned by b. delta is
+// the Lovász constant in (1/4, 1], 3/4 if nil; the challenge suggests 99/100.
+// b is not modified. Reduce returns ErrDependent if the vectors of b are
+// linearly dependent.
+//
+// The vectors are scaled by the common denominator of their entries and
+// reduced with ReduceInts, which computes the same sequence of steps as the
+// rational algorithm of the challenge.
+func Reduce(b []Vector, delta *big.Rat) ([]Vector, error) {
+	den := big.NewInt(1)
+	for _, v := range b {
+		for _, x := range v {
+			g := new(big.Int).GCD(nil, nil, den, x.Denom())
+			den.Mul(den, g.Quo(x.Denom(), g))
+		}
+	}
+	ints := make([][]*big.Int, len(b))
+	for i, v := range b {
+		ints[i] = make([]*big.Int, len(v))
+		for j, x := range v {
+			ints[i][j] = new(big.Int).Mul(x.Num(), new(big.Int).Quo(den, x.Denom()))
+		}
+	}
+	reduced, err := ReduceInts(ints, delta)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Vector, len(reduced))
+	for i, v := range reduced {
+		out[i] = make(Vector, len(v))
+		for j, x := range v {
+			out[i][j] = new(big.Rat).SetFrac(x, den)
+		}
+	}
+	return out, nil
+}
+
+// ReduceInts is Reduce for a basis of integer vectors. It is the integral
+// LLL of Cohen, "A Course in Computational Algebraic Number Theory",
+// algorithm 2.6.7: instead of the rational Gram-Schmidt coefficients mu and
+// squared norms |b*[i]|^2 it keeps the integers d[i], the Gram determinant
+// of the first i vectors, and lambda[i][j] = d[j+1] mu[i][j], and updates
+// them exactly after every step rather than recomputing them.
+func ReduceInts(b [][]*big.Int, delta *big.Rat) ([][]*big.Int, error) {
+	if delta == nil {
+		delta = big.NewRat(3, 4)
+	}
+	n := len(b)
+	basis := make([][]*big.Int, n)
+	for i, v := range b {
+		basis[i] = make([]*big.Int, len(v))
+		for j, x := range v {
+			basis[i][j] = new(big.Int).Set(x)
+		}
+	}
+
+	// d[0] = 1 and d[i+1] = d[i] |b*[i]|^2
+	d := make([]*big.Int, n+1)
+	d[0] = big.NewInt(1)
+	lambda := make([][]*big.Int, n)
+	t := new(big.Int)
+	for k := range basis {
+		lambda[k] = make([]*big.Int, n)
+		for j := 0; j <= k; j++ {
+			u := dot(basis[k], basis[j])
+			for i := 0; i < j; i++ {
+				// u = (d[i+1] u - lambda[k][i] lambda[j][i]) / d[i]
+				u.Mul(u, d[i+1])
+				u.Sub(u, t.Mul(lambda[k][i], lambda[j][i]))
+				u.Quo(u, d[i])
+			}
+			if j < k {
+				lambda[k][j] = u
+			} else if u.Sign() == 0 {
+				return nil, ErrDependent
+			} else {
+				d[k+1] = u
+			}
+		}
+	}
+
+	// sizeReduce makes |mu[k][j]| <= 1/2 by subtracting a multiple of b[j]
+	// from b[k].
+	sizeReduce := func(k, j int) {
+		if t.Abs(lambda[k][j]).Lsh(t, 1).Cmp(d[j+1]) <= 0 {
+			return
+		}
+		q := roundQuo(lambda[k][j], d[j+1])
+		for i, x := range basis[j] {
+			basis[k][i].Sub(basis[k][i], t.Mul(q, x))
+		}
+		lambda[k][j].Sub(lambda[k][j], t.Mul(q, d[j+1]))
+		for i := 0; i < j; i++ {
+			lambda[k][i].Sub(lambda[k][i], t.Mul(q, lambda[j][i]))
+		}
+	}
+
+	num, den := delta.Num(), delta.Denom()
+	for k := 1; k < n; {
+		sizeReduce(k, k-1)
+		// The Lovász condition |b*[k]|^2 >= (delta - mu^2) |b*[k-1]|^2 with
+		// mu = lambda[k][k-1] / d[k] is, multiplied by d[k] d[k-1] and the
+		// denominator of delta,
+		// den d[k+1] d[k-1] >= num d[k]^2 - den lambda[k][k-1]^2.
+		l := lambda[k][k-1]
+		lhs := new(big.Int).Mul(d[k+1], d[k-1])
+		lhs.Mul(lhs, den)
+		rhs := new(big.Int).Mul(d[k], d[k])
+		rhs.Mul(rhs, num)
+		rhs.Sub(rhs, t.Mul(l, l).Mul(t, den))
+		if lhs.Cmp(rhs) >= 0 {
+			for j := k - 2; j >= 0; j-- {
+				sizeReduce(k, j)
+			}
+			k++
+			continue
+		}
+
+		// swap b[k-1] and b[k]
+		basis[k-1], basis[k] = basis[k], basis[k-1]
+		for j := 0; j < k-1; j++ {
+			lambda[k-1][j], lambda[k][j] = lambda[k][j], lambda[k-1][j]
+		}
+		// the new d[k] is (d[k-1] d[k+1] + l^2) / d[k]
+		nd := new(big.Int).Mul(d[k-1], d[k+1])
+		nd.Add(nd, t.Mul(l, l))
+		nd.Quo(nd, d[k])
+		for i := k + 1; i < n; i++ {
+			// lambda[i][k] = (d[k+1] lambda[i][k-1] - l lambda[i][k]) / d[k]
+			// lambda[i][k-1] = (nd lambda[i][k] + l (new lambda[i][k])) / d[k+1]
+			old := lambda[i][k]
+			u := new(big.Int).Mul(d[k+1], lambda[i][k-1])
+			u.Sub(u, t.Mul(l, old))
+			u.Quo(u, d[k])
+			v := new(big.Int).Mul(nd, old)
+			v.Add(v, t.Mul(l, u))
+			lambda[i][k], lambda[i][k-1] = u, v.Quo(v, d[k+1])
+		}
+		d[k] = nd
+		if k > 1 {
+			k--
+		}
+	}
+	return basis, nil
+}
@@ -0,0 +1,113 @@
+package lattice
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func ratVector(xs ...string) Vector {
+	v := make(Vector, len(xs))
+	for i, x := range xs {
+		v[i], _ = new(big.Rat).SetString(x)
+	}
+	return v
+}
+
+func TestReduceChallenge(t *testing.T) {
+	b := []Vector{
+		ratVector("-2", "0", "2", "0"),
+		ratVector("1/2", "-1", "0", "0"),
+		ratVector("-1", "0", "-2", "1/2"),
+		ratVector("-1", "1", "1", "2"),
+	}
+	want := []Vector{
+		ratVector("1/2", "-1", "0", "0"),
+		ratVector("-1", "0", "-2", "1/2"),
+		ratVector("-1/2", "0", "1", "2"),
+		ratVector("-3/2", "-1", "2", "0"),
+	}
+	got, err := Reduce(b, big.NewRat(99, 100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range want {
+		if got[i].String() != want[i].String() {
+			t.Errorf("b%d = %v; want %v", i+1, got[i], want[i])
+		}
+	}
+	if b[0].String() != "[-2 0 2 0]" {
+		t.Errorf("Reduce modified its input: %v", b[0])
+	}
+}
+
+// isReduced reports whether b is size reduced and satisfies the Lovász
+// condition for delta.
+func isReduced(b []Vector, delta *big.Rat) bool {
+	bs := GramSchmidt(b)
+	for i := 1; i < len(b); i++ {
+		var m *big.Rat
+		for j := 0; j < i; j++ {
+			m = new(big.Rat).Quo(b[i].Dot(bs[j]), bs[j].Dot(bs[j]))
+			if new(big.Rat).Abs(m).Cmp(big.NewRat(1, 2)) > 0 {
+				return false
+			}
+		}
+		bound := new(big.Rat).Mul(m, m)
+		bound.Sub(delta, bound)
+		bound.Mul(bound, bs[i-1].Dot(bs[i-1]))
+		if bs[i].Dot(bs[i]).Cmp(bound) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// volume returns the square of the volume of the lattice, the product of
+// the squared norms of the Gram-Schmidt vectors.
+func volume(b []Vector) *big.Rat {
+	v := big.NewRat(1, 1)
+	for _, x := range GramSchmidt(b) {
+		v.Mul(v, x.Dot(x))
+	}
+	return v
+}
+
+func TestReduceRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(62))
+	delta := big.NewRat(99, 100)
+	for n := 2; n <= 12; n++ {
+		b := make([][]*big.Int, n)
+		for i := range b {
+			b[i] = make([]*big.Int, n)
+			for j := range b[i] {
+				b[i][j] = new(big.Int).Rand(rnd, big.NewInt(1<<40))
+			}
+		}
+		reduced, err := ReduceInts(b, delta)
+		if err != nil {
+			t.Fatalf("n = %d: %v", n, err)
+		}
+		rb, rr := make([]Vector, n), make([]Vector, n)
+		for i := range b {
+			rb[i], rr[i] = make(Vector, n), make(Vector, n)
+			for j := range b[i] {
+				rb[i][j] = new(big.Rat).SetInt(b[i][j])
+				rr[i][j] = new(big.Rat).SetInt(reduced[i][j])
+			}
+		}
+		if !isReduced(rr, delta) {
+			t.Errorf("n = %d: basis is not reduced", n)
+		}
+		if volume(rr).Cmp(volume(rb)) != 0 {
+			t.Errorf("n = %d: reduction changed the lattice volume", n)
+		}
+	}
+}
+
+func TestReduceDependent(t *testing.T) {
+	b := []Vector{NewVector(1, 2, 3), NewVector(2, 4, 6)}
+	if _, err := Reduce(b, nil); err != ErrDependent {
+		t.Errorf("Reduce = %v; want %v", err, ErrDependent)
+	}
+}
@@ -1,5 +1,10 @@
 package mtrand
 
+import "errors"
+
+// ErrNotSeeded is returned when a Source is used before Seed was called.
+var ErrNotSeeded = errors.New("generator was never seeded")
+
 // The coefficients for MT19937 are:
 const (
 	w = uint32(32)
@@ -59,12 +64,21 @@ func (s *Source) Seed(seed uint32) {
 	// fmt.Println(mt)
 }
 
-// Rand extracts a tempered value based on MT[index]
-// calling twist() every n numbers.
+// Rand is like RandChecked but panics if the generator was never seeded.
 func (s *Source) Rand() uint32 {
+	y, err := s.RandChecked()
+	if err != nil {
+		panic(err)
+	}
+	return y
+}
+
+// RandChecked extracts a tempered value based on MT[index]
+// calling twist() every n numbers.
+func (s *Source) RandChecked() (uint32, error) {
 	if s.index >= n {
 		if s.index > n {
-			panic("generator was never seeded")
+			return 0, ErrNotSeeded
 		}
 		s.twist()
 	}
@@ -79,7 +93,7 @@ func (s *Source) Rand() uint32 {
 	y = y ^ (y >> l)
 	// fmt.Printf("y4=%#x\n", y)
 	s.index++
-	return y
+	return y, nil
 }
 
 // twist generates the next n values from the series x_i.
@@ -0,0 +1,175 @@
+package nonce
+
+import (
+	"crypto"
+	"errors"
+	"math/big"
+
+	"github.com/ysmolsky/cryptopals/tools/lattice"
+)
+
+var (
+	// ErrLeak is returned for a Leak which does not fit the order of the
+	// scheme or for signatures which cannot come from a valid signer.
+	ErrLeak = errors.New("nonce: invalid nonce leak")
+	// ErrNoKey is returned by HNP.Recover when no candidate matches the
+	// public key.
+	ErrNoKey = errors.New("nonce: no candidate private key matches the public key")
+)
+
+var one = big.NewInt(1)
+
+// Leak describes the bits every nonce gives away.
+type Leak struct {
+	Bits int // number of known bits
+	// High says the known bits are the top Bits of the Order().BitLen()
+	// bits of the nonce, otherwise they are the bottom ones.
+	High bool
+}
+
+// Biased is a signature whose nonce leaks bits.
+type Biased struct {
+	Record
+	// Known is the value of the leaked bits, nil for zero as with the
+	// masked nonces of challenge 62.
+	Known *big.Int
+}
+
+// HNP recovers private keys from signatures with partially known nonces by
+// solving the hidden number problem with lattice reduction, as in challenge
+// 62. With l known bits a little more than Order().BitLen()/l signatures
+// are needed.
+type HNP struct {
+	Scheme Scheme
+	// Hash computes z for records which only have the message.
+	Hash crypto.Hash
+	Leak Leak
+	// Delta is the Lovász constant of the reduction, 99/100 if nil.
+	Delta *big.Rat
+}
+
+// pair returns t and u with x t = u + b mod q for the private key x and an
+// unknown b with |b| <= q/2^(l+1) for l known bits.
+//
+// Both schemes give k = s^-1 z + s^-1 r x mod q. With the low l bits c
+// known, k = 2^l b + c and b = (s^-1 r x + s^-1 z - c) / 2^l with
+// 0 <= b < q/2^l. With the high ones, k = 2^m c + b for m = |q| - l and
+// b = s^-1 r x + s^-1 z - 2^m c with 0 <= b < 2^m. Centering b around zero
+// halves the bound the lattice has to find.
+func (h *HNP) pair(q, z, r, s, c *big.Int) (t, u *big.Int, err error) {
+	sInv := new(big.Int).ModInverse(s, q)
+	if sInv == nil || r.Sign() <= 0 || r.Cmp(q) >= 0 {
+		return nil, nil, ErrLeak
+	}
+	t = new(big.Int).Mul(sInv, r)
+	a := new(big.Int).Mul(sInv, z)
+	var bound *big.Int
+	if h.Leak.High {
+		m := uint(q.BitLen() - h.Leak.Bits)
+		bound = new(big.Int).Lsh(one, m)
+		a.Sub(a, new(big.Int).Lsh(c, m))
+	} else {
+		l := uint(h.Leak.Bits)
+		bound = new(big.Int).Rsh(new(big.Int).Sub(q, one), l)
+		bound.Add(bound, one)
+		inv := new(big.Int).ModInverse(new(big.Int).Lsh(one, l), q)
+		a.Sub(a, c).Mul(a, inv)
+		t.Mul(t, inv)
+	}
+	a.Sub(a, new(big.Int).Rsh(bound, 1))
+	// b = t x + a, so t x = -a + b
+	u = a.Neg(a)
+	return t.Mod(t, q), u.Mod(u, q), nil
+}
+
+// Candidates builds the lattice of challenge 62 from the signatures and
+// returns the private keys suggested by its reduced basis. Among them is the
+// key if there are enough signatures; they are not checked.
+//
+// The rows are q e_i, the t row [t_1 ... t_n, ct, 0] and the u row [u_1 ...
+// u_n, 0, cu] with ct = 1/2^l and cu = q/2^l for l known bits, all
+// multiplied by 2^l to make them integers. The combination u - x t +
+// sum(m_i q e_i) is then the short vector [2^l b_1 ... 2^l b_n, -x, q], whose
+// entries are all about the size of q.
+func (h *HNP) Candidates(sigs []Biased) ([]*big.Int, error) {
+	q := h.Scheme.Order()
+	if h.Leak.Bits <= 0 || h.Leak.Bits >= q.BitLen() || len(sigs) == 0 {
+		return nil, ErrLeak
+	}
+	n := len(sigs)
+	basis := make([][]*big.Int, n+2)
+	for i := range basis {
+		basis[i] = make([]*big.Int, n+2)
+		for j := range basis[i] {
+			basis[i][j] = new(big.Int)
+		}
+	}
+	l := uint(h.Leak.Bits)
+	for i := range sigs {
+		sig := &sigs[i]
+		hashed, err := digest(h.Hash, &sig.Record)
+		if err != nil {
+			return nil, err
+		}
+		c := sig.Known
+		if c == nil {
+			c = new(big.Int)
+		}
+		t, u, err := h.pair(q, hashToInt(hashed, q), sig.R, sig.S, c)
+		if err != nil {
+			return nil, err
+		}
+		basis[i][i].Lsh(q, l)
+		basis[n][i].Lsh(t, l)
+		basis[n+1][i].Lsh(u, l)
+	}
+	basis[n][n].SetInt64(1)
+	cu := basis[n+1][n+1].Set(q)
+
+	delta := h.Delta
+	if delta == nil {
+		delta = big.NewRat(99, 100)
+	}
+	reduced, err := lattice.ReduceInts(basis, delta)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []*big.Int
+	seen := make(map[string]bool)
+	negCu := new(big.Int).Neg(cu)
+	for _, v := range reduced {
+		x := new(big.Int)
+		switch {
+		case v[n+1].Cmp(cu) == 0:
+			x.Neg(v[n])
+		case v[n+1].Cmp(negCu) == 0:
+			x.Set(v[n])
+		default:
+			continue
+		}
+		x.Mod(x, q)
+		if x.Sign() != 0 && !seen[x.String()] {
+			seen[x.String()] = true
+			keys = append(keys, x)
+		}
+	}
+	return keys, nil
+}
+
+// Recover returns the private key of the signatures, which all have to be
+// by the key sigs[0].PublicKey, checked with the scheme. It returns ErrNoKey
+// if the lattice did not reveal it, usually because there are too few
+// signatures for the leak.
+func (h *HNP) Recover(sigs []Biased) (*big.Int, error) {
+	keys, err := h.Candidates(sigs)
+	if err != nil {
+		return nil, err
+	}
+	for _, x := range keys {
+		if h.Scheme.CheckKey(sigs[0].PublicKey, x) {
+			return x, nil
+		}
+	}
+	return nil, ErrNoKey
+}
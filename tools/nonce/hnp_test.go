@@ -0,0 +1,112 @@
+package nonce
+
+import (
+	"crypto"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ysmolsky/cryptopals/tools/dsa"
+	"github.com/ysmolsky/cryptopals/tools/ec"
+)
+
+// biasedNonce returns a random nonce below q whose leaked bits are zero, or
+// random if known is set, and the value of those bits.
+func biasedNonce(t *testing.T, q *big.Int, leak Leak, known bool) (k, c *big.Int) {
+	shift := uint(leak.Bits) // where the unknown part b starts
+	if leak.High {
+		shift = 0
+	}
+	for {
+		c = new(big.Int)
+		b, err := rand.Int(rand.Reader, new(big.Int).Lsh(one, uint(q.BitLen()-leak.Bits)))
+		if err == nil && known {
+			c, err = rand.Int(rand.Reader, new(big.Int).Lsh(one, uint(leak.Bits)))
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if leak.High {
+			k = new(big.Int).Lsh(c, uint(q.BitLen()-leak.Bits))
+		} else {
+			k = new(big.Int).Set(c)
+		}
+		k.Add(k, b.Lsh(b, shift))
+		if k.Sign() > 0 && k.Cmp(q) < 0 {
+			return k, c
+		}
+	}
+}
+
+func TestHNPDSA(t *testing.T) {
+	params := dsa.PregenParameters()
+	priv, err := dsa.GenerateKey(nil, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, known := range []bool{true, false} {
+		if testing.Short() && !known {
+			continue
+		}
+		leak := Leak{Bits: 8}
+		var sigs []Biased
+		for i := 0; i < 22; i++ {
+			msg := fmt.Sprint("message ", i)
+			k, c := biasedNonce(t, params.Q, leak, known)
+			r, s, err := dsa.SignWithNonce(priv, sha256Sum(msg), k)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sigs = append(sigs, Biased{
+				Record: Record{Message: []byte(msg), R: r, S: s, PublicKey: priv.Y.Text(16)},
+				Known:  c,
+			})
+		}
+		h := &HNP{Scheme: DSA{params}, Hash: crypto.SHA256, Leak: leak}
+		x, err := h.Recover(sigs)
+		if err != nil || x.Cmp(priv.X) != 0 {
+			t.Errorf("known bits %v: Recover = %v, %v; want %v", known, x, err, priv.X)
+		}
+	}
+}
+
+func TestHNPECDSA(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		c     *ec.Curve
+		leak  Leak
+		count int
+	}{
+		{"challenge 59, low byte zero", ec.Challenge59(), Leak{Bits: 8}, 22},
+		{"P-256, top 16 bits zero", p256(), Leak{Bits: 16, High: true}, 20},
+	} {
+		if testing.Short() && tc.leak.High {
+			continue
+		}
+		d, pub, err := tc.c.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sigs []Biased
+		for i := 0; i < tc.count; i++ {
+			h := sha256Sum(fmt.Sprint("message ", i))
+			k, _ := biasedNonce(t, tc.c.N, tc.leak, false)
+			r, s := ecdsaSign(tc.c, d, h, k, false)
+			sigs = append(sigs, Biased{Record: Record{Hash: h, R: r, S: s, PublicKey: sec1(tc.c, pub)}})
+		}
+		h := &HNP{Scheme: ECDSA{tc.c}, Leak: tc.leak}
+		x, err := h.Recover(sigs)
+		if err != nil || x.Cmp(d) != 0 {
+			t.Errorf("%s: Recover = %v, %v; want %v", tc.name, x, err, d)
+		}
+		// far too few signatures for the bias
+		if _, err := h.Recover(sigs[:4]); err != ErrNoKey {
+			t.Errorf("%s: Recover with 4 signatures = %v; want %v", tc.name, err, ErrNoKey)
+		}
+	}
+	h := &HNP{Scheme: ECDSA{ec.Challenge59()}, Leak: Leak{Bits: 0}}
+	if _, err := h.Candidates([]Biased{{}}); err != ErrLeak {
+		t.Errorf("Candidates with no leaked bits = %v; want %v", err, ErrLeak)
+	}
+}
@@ -0,0 +1,225 @@
+// Package nonce finds DSA and ECDSA private keys in logs of signatures that
+// reuse a nonce. Both schemes sign with s = k^-1 (z + x r) mod q, where r
+// depends only on the nonce k, so two signatures by one key with the same r
+// share k and give away x. Nonces which are merely biased give it away too,
+// given enough signatures and lattice reduction.
+package nonce
+
+import (
+	"crypto"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/ysmolsky/cryptopals/tools/dsa"
+	"github.com/ysmolsky/cryptopals/tools/ec"
+)
+
+// ErrNoHash is returned by Scan for a record without a hash when the
+// Scanner has no hash function to compute it.
+var ErrNoHash = errors.New("nonce: record has no hash and no hash function is set")
+
+// Scheme is the part of a signature scheme of the DSA family the scanner
+// needs.
+type Scheme interface {
+	// Order returns q, the modulus of r, s and the nonces.
+	Order() *big.Int
+	// CheckKey reports whether x is the private key of pub, the public key
+	// as written in the records.
+	CheckKey(pub string, x *big.Int) bool
+}
+
+// DSA is the Scheme of DSA with fixed domain parameters. Public keys are Y
+// in hexadecimal.
+type DSA struct {
+	Params *dsa.Parameters
+}
+
+// Order returns Q.
+func (d DSA) Order() *big.Int { return d.Params.Q }
+
+// CheckKey reports whether G^x = Y mod P.
+func (d DSA) CheckKey(pub string, x *big.Int) bool {
+	y, ok := new(big.Int).SetString(normalizeKey(pub), 16)
+	return ok && new(big.Int).Exp(d.Params.G, x, d.Params.P).Cmp(y) == 0
+}
+
+// ECDSA is the Scheme of ECDSA on a curve with a base point. Public keys are
+// uncompressed SEC 1 points in hexadecimal, 04 || X || Y.
+type ECDSA struct {
+	Curve *ec.Curve
+}
+
+// Order returns the order of the base point.
+func (e ECDSA) Order() *big.Int { return e.Curve.N }
+
+// CheckKey reports whether x G is the public point.
+func (e ECDSA) CheckKey(pub string, x *big.Int) bool {
+	b, err := hex.DecodeString(normalizeKey(pub))
+	size := (e.Curve.P.BitLen() + 7) / 8
+	if err != nil || len(b) != 1+2*size || b[0] != 4 {
+		return false
+	}
+	p := ec.NewPoint(new(big.Int).SetBytes(b[1:1+size]), new(big.Int).SetBytes(b[1+size:]))
+	return e.Curve.ScalarBaseMult(x).Equal(p)
+}
+
+// normalizeKey returns a public key in hexadecimal without a 0x prefix and
+// in lower case, so that logs which spell one key differently still agree.
+func normalizeKey(pub string) string {
+	if len(pub) >= 2 && pub[0] == '0' && (pub[1] == 'x' || pub[1] == 'X') {
+		pub = pub[2:]
+	}
+	return strings.ToLower(pub)
+}
+
+// Record is one logged signature.
+type Record struct {
+	Line      int    // where the record starts in the input
+	Message   []byte // signed message, hashed if Hash is nil
+	Hash      []byte
+	R, S      *big.Int
+	PublicKey string
+}
+
+// Reuse is a group of signatures by one key with the same r.
+type Reuse struct {
+	R     *big.Int
+	Lines []int    // the Line of every record in the group
+	K     *big.Int // the shared nonce, nil if no pair of records gave it
+}
+
+// Report sums up the signatures of one public key.
+type Report struct {
+	PublicKey  string // as written in the first record of the key
+	Signatures int
+	Reuses     []*Reuse
+	// X is the private key if a reused nonce revealed one which matches
+	// PublicKey, nil otherwise.
+	X *big.Int
+}
+
+// Scanner looks for reused nonces with a signature scheme.
+type Scanner struct {
+	Scheme Scheme
+	// Hash computes z for records which only have the message.
+	Hash crypto.Hash
+}
+
+// hashToInt returns z, the leftmost Order().BitLen() bits of hashed.
+func hashToInt(hashed []byte, q *big.Int) *big.Int {
+	z := new(big.Int).SetBytes(hashed)
+	if excess := len(hashed)*8 - q.BitLen(); excess > 0 {
+		z.Rsh(z, uint(excess))
+	}
+	return z
+}
+
+// digest returns the hash of rec, computed with h if the record has none.
+func digest(h crypto.Hash, rec *Record) ([]byte, error) {
+	if rec.Hash != nil {
+		return rec.Hash, nil
+	}
+	if h == 0 || !h.Available() {
+		return nil, ErrNoHash
+	}
+	d := h.New()
+	d.Write(rec.Message)
+	return d.Sum(nil), nil
+}
+
+// Scan groups the records by public key and r in a single pass, recovers
+// the nonce and the private key from every group of two or more signatures
+// and checks the key with the scheme. Public keys are compared regardless
+// of a 0x prefix and the case of the hex digits. It returns one report per public key
+// in the order of first appearance.
+func (sc *Scanner) Scan(records []Record) ([]*Report, error) {
+	q := sc.Scheme.Order()
+	type group struct {
+		reuse   *Reuse
+		records []int // indices into records
+	}
+	reports := make(map[string]*Report)
+	var order []*Report
+	groups := make(map[string]*group)
+	var reused []*group
+	zs := make([]*big.Int, len(records))
+
+	for i := range records {
+		rec := &records[i]
+		hashed, err := digest(sc.Hash, rec)
+		if err != nil {
+			return nil, err
+		}
+		zs[i] = hashToInt(hashed, q)
+
+		pub := normalizeKey(rec.PublicKey)
+		rep := reports[pub]
+		if rep == nil {
+			rep = &Report{PublicKey: rec.PublicKey}
+			reports[pub] = rep
+			order = append(order, rep)
+		}
+		rep.Signatures++
+
+		key := pub + "\x00" + rec.R.Text(16)
+		g := groups[key]
+		if g == nil {
+			groups[key] = &group{records: []int{i}}
+			continue
+		}
+		if g.reuse == nil {
+			g.reuse = &Reuse{R: rec.R, Lines: []int{records[g.records[0]].Line}}
+			rep.Reuses = append(rep.Reuses, g.reuse)
+			reused = append(reused, g)
+		}
+		g.reuse.Lines = append(g.reuse.Lines, rec.Line)
+		g.records = append(g.records, i)
+	}
+
+	for _, g := range reused {
+		first := g.records[0]
+		rep := reports[normalizeKey(records[first].PublicKey)]
+		for _, j := range g.records[1:] {
+			k, x := sc.recover(&records[first], &records[j], zs[first], zs[j])
+			if k != nil {
+				g.reuse.K = k
+				rep.X = x
+				break
+			}
+		}
+	}
+	return order, nil
+}
+
+// recover returns the nonce and the private key from two signatures with
+// the same r, or nils if the key does not check out. ECDSA signatures may
+// have been normalized to s or q-s, so both are tried for the second one.
+func (sc *Scanner) recover(a, b *Record, za, zb *big.Int) (k, x *big.Int) {
+	q := sc.Scheme.Order()
+	rInv := new(big.Int).ModInverse(a.R, q)
+	if rInv == nil {
+		return nil, nil
+	}
+	dz := new(big.Int).Sub(za, zb)
+	for _, sb := range []*big.Int{b.S, new(big.Int).Sub(q, b.S)} {
+		// k = (z1 - z2) / (s1 - s2)
+		ds := new(big.Int).Sub(a.S, sb)
+		inv := new(big.Int).ModInverse(ds.Mod(ds, q), q)
+		if inv == nil {
+			continue
+		}
+		k = inv.Mul(inv, dz)
+		k.Mod(k, q)
+		// x = (s1 k - z1) / r
+		x = new(big.Int).Mul(a.S, k)
+		x.Sub(x, za)
+		x.Mul(x, rInv)
+		x.Mod(x, q)
+		if sc.Scheme.CheckKey(a.PublicKey, x) {
+			return k, x
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,180 @@
+package nonce
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"crypto/rand"
+	_ "crypto/sha256"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ysmolsky/cryptopals/tools/dsa"
+	"github.com/ysmolsky/cryptopals/tools/ec"
+)
+
+func sha256Sum(msg string) []byte {
+	h := crypto.SHA256.New()
+	h.Write([]byte(msg))
+	return h.Sum(nil)
+}
+
+func TestScanDSA(t *testing.T) {
+	params := dsa.PregenParameters()
+	var keys []*dsa.PrivateKey
+	for i := 0; i < 3; i++ {
+		priv, err := dsa.GenerateKey(nil, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, priv)
+	}
+	var records []Record
+	sign := func(priv *dsa.PrivateKey, msg string, k *big.Int) {
+		if k == nil {
+			k, _ = rand.Int(rand.Reader, params.Q)
+		}
+		r, s, err := dsa.SignWithNonce(priv, sha256Sum(msg), k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, Record{
+			Line: len(records) + 1, Message: []byte(msg), R: r, S: s,
+			PublicKey: priv.Y.Text(16),
+		})
+	}
+	shared := big.NewInt(0xdeadbeef)
+	// key 0 reuses a nonce far apart in the log
+	sign(keys[0], "first", shared)
+	for i := 0; i < 10; i++ {
+		sign(keys[i%3], fmt.Sprint("filler ", i), nil)
+	}
+	sign(keys[0], "second", shared)
+	// key 1 uses the same nonce as key 0, which alone reveals nothing
+	sign(keys[1], "other key", shared)
+	// key 2 signs the same message twice with one nonce, then a third one
+	sign(keys[2], "again", big.NewInt(42))
+	sign(keys[2], "again", big.NewInt(42))
+	sign(keys[2], "third", big.NewInt(42))
+
+	sc := &Scanner{Scheme: DSA{params}, Hash: crypto.SHA256}
+	reports, err := sc.Scan(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 3 {
+		t.Fatalf("got %d reports; want 3", len(reports))
+	}
+	for i, rep := range reports {
+		if rep.PublicKey != keys[i].Y.Text(16) {
+			t.Fatalf("report %d is for another key", i)
+		}
+	}
+	if rep := reports[0]; rep.Signatures != 6 || len(rep.Reuses) != 1 || rep.X == nil ||
+		rep.X.Cmp(keys[0].X) != 0 || rep.Reuses[0].K.Cmp(shared) != 0 {
+		t.Errorf("key 0: %+v", rep)
+	} else if lines := rep.Reuses[0].Lines; len(lines) != 2 || lines[0] != 1 || lines[1] != 12 {
+		t.Errorf("key 0: reuse on lines %v; want [1 12]", lines)
+	}
+	if rep := reports[1]; len(rep.Reuses) != 0 || rep.X != nil {
+		t.Errorf("key 1: %+v", rep)
+	}
+	if rep := reports[2]; len(rep.Reuses) != 1 || len(rep.Reuses[0].Lines) != 3 || rep.X == nil ||
+		rep.X.Cmp(keys[2].X) != 0 {
+		t.Errorf("key 2: %+v", rep)
+	}
+}
+
+func TestScanKeySpelling(t *testing.T) {
+	c := ec.Challenge59()
+	d, pub, err := c.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, err := rand.Int(rand.Reader, c.N)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := sec1(c, pub)
+	var records []Record
+	for i, spelling := range []string{key, "0x" + strings.ToUpper(key), "0X" + key} {
+		h := sha256Sum(fmt.Sprint("message ", i))
+		r, s := ecdsaSign(c, d, h, k, false)
+		records = append(records, Record{Line: i + 1, Hash: h, R: r, S: s, PublicKey: spelling})
+	}
+	sc := &Scanner{Scheme: ECDSA{c}}
+	reports, err := sc.Scan(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 1 {
+		t.Fatalf("got %d reports; want 1", len(reports))
+	}
+	rep := reports[0]
+	if rep.PublicKey != key || rep.Signatures != 3 || len(rep.Reuses) != 1 ||
+		len(rep.Reuses[0].Lines) != 3 || rep.X == nil || rep.X.Cmp(d) != 0 {
+		t.Errorf("%+v", rep)
+	}
+}
+
+// ecdsaSign signs with the nonce k, normalizing s to the lower half if low
+// is set.
+func ecdsaSign(c *ec.Curve, d *big.Int, hashed []byte, k *big.Int, low bool) (r, s *big.Int) {
+	r = new(big.Int).Mod(c.ScalarBaseMult(k).X, c.N)
+	s = new(big.Int).Mul(r, d)
+	s.Add(s, hashToInt(hashed, c.N))
+	s.Mul(s, new(big.Int).ModInverse(k, c.N))
+	s.Mod(s, c.N)
+	if low && s.Cmp(new(big.Int).Rsh(c.N, 1)) > 0 {
+		s.Sub(c.N, s)
+	}
+	return r, s
+}
+
+func sec1(c *ec.Curve, p *ec.Point) string {
+	size := (c.P.BitLen() + 7) / 8
+	b := append([]byte{4}, p.X.FillBytes(make([]byte, size))...)
+	return fmt.Sprintf("%x", append(b, p.Y.FillBytes(make([]byte, size))...))
+}
+
+func p256() *ec.Curve {
+	params := elliptic.P256().Params()
+	return &ec.Curve{
+		A: big.NewInt(-3), B: params.B, P: params.P,
+		G: ec.NewPoint(params.Gx, params.Gy), N: params.N, Order: params.N,
+	}
+}
+
+func TestScanECDSA(t *testing.T) {
+	for name, c := range map[string]*ec.Curve{"challenge 59": ec.Challenge59(), "P-256": p256()} {
+		d, pub, err := c.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		k, err := rand.Int(rand.Reader, c.N)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var records []Record
+		for i, msg := range []string{"one", "two", "three"} {
+			// only the last signature is normalized to a low s
+			h := sha256Sum(msg)
+			r, s := ecdsaSign(c, d, h, k, i == 2)
+			records = append(records, Record{Line: i + 1, Hash: h, R: r, S: s, PublicKey: sec1(c, pub)})
+		}
+		sc := &Scanner{Scheme: ECDSA{c}}
+		for _, recs := range [][]Record{records[:2], {records[0], records[2]}, {records[2], records[1]}} {
+			reports, err := sc.Scan(recs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(reports) != 1 || reports[0].X == nil || reports[0].X.Cmp(d) != 0 {
+				t.Errorf("%s: lines %d and %d: %+v", name, recs[0].Line, recs[1].Line, reports[0])
+			}
+		}
+		if _, err := sc.Scan([]Record{{Message: []byte("no hash"), R: big.NewInt(1), S: big.NewInt(1)}}); err != ErrNoHash {
+			t.Errorf("%s: Scan without a hash function = %v; want %v", name, err, ErrNoHash)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package nonce
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+)
+
+// parseInt parses a decimal integer or a hexadecimal one with a 0x prefix.
+func parseInt(s string) (*big.Int, bool) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		return new(big.Int).SetString(s[2:], 16)
+	}
+	return new(big.Int).SetString(s, 10)
+}
+
+// parseHash decodes a hexadecimal hash. Leading zeros may be missing, as
+// they are in 44.txt.
+func parseHash(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		s = s[2:]
+	}
+	if len(s)%2 != 0 {
+		s = "0" + s
+	}
+	return hex.DecodeString(s)
+}
+
+// fill sets the fields of rec from their textual values.
+func (rec *Record) fill(hash, r, s string) error {
+	var ok bool
+	if rec.R, ok = parseInt(r); !ok {
+		return fmt.Errorf("nonce: line %d: bad r %q", rec.Line, r)
+	}
+	if rec.S, ok = parseInt(s); !ok {
+		return fmt.Errorf("nonce: line %d: bad s %q", rec.Line, s)
+	}
+	if hash != "" {
+		h, err := parseHash(hash)
+		if err != nil {
+			return fmt.Errorf("nonce: line %d: bad hash %q", rec.Line, hash)
+		}
+		rec.Hash = h
+	}
+	return nil
+}
+
+// ReadChallenge44 reads the format of 44.txt, blocks of lines
+//
+//	msg: <message>
+//	s: <decimal>
+//	r: <decimal>
+//	m: <hex hash>
+//
+// The file has no public keys, all records get pub.
+func ReadChallenge44(r io.Reader, pub string) ([]Record, error) {
+	var records []Record
+	fields := make(map[string]string)
+	start := 0
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		i := strings.Index(line, ": ")
+		if i < 0 {
+			return nil, fmt.Errorf("nonce: line %d: expected name: value", n)
+		}
+		name, value := line[:i], line[i+2:]
+		if name == "msg" {
+			if len(fields) != 0 {
+				return nil, fmt.Errorf("nonce: line %d: incomplete record", start)
+			}
+			start = n
+		}
+		fields[name] = value
+		if len(fields) < 4 {
+			continue
+		}
+		rec := Record{Line: start, Message: []byte(fields["msg"]), PublicKey: pub}
+		if err := rec.fill(fields["m"], fields["r"], fields["s"]); err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+		fields = make(map[string]string)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(fields) != 0 {
+		return nil, fmt.Errorf("nonce: line %d: incomplete record", start)
+	}
+	return records, nil
+}
+
+// jsonInt accepts an integer as a JSON number or as a string.
+type jsonInt string
+
+func (v *jsonInt) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		s = string(b)
+	}
+	*v = jsonInt(s)
+	return nil
+}
+
+// ReadJSONL reads one JSON object per line with the fields msg, hash (hex),
+// r, s and pub. Either msg or hash has to be present. r and s are numbers or
+// strings in decimal or 0x-prefixed hexadecimal.
+func ReadJSONL(r io.Reader) ([]Record, error) {
+	var records []Record
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var v struct {
+			Msg  *string `json:"msg"`
+			Hash string  `json:"hash"`
+			R    jsonInt `json:"r"`
+			S    jsonInt `json:"s"`
+			Pub  string  `json:"pub"`
+		}
+		if err := json.Unmarshal([]byte(line), &v); err != nil {
+			return nil, fmt.Errorf("nonce: line %d: %v", n, err)
+		}
+		if v.Msg == nil && v.Hash == "" {
+			return nil, fmt.Errorf("nonce: line %d: neither msg nor hash", n)
+		}
+		rec := Record{Line: n, PublicKey: v.Pub}
+		if v.Msg != nil {
+			rec.Message = []byte(*v.Msg)
+		}
+		if err := rec.fill(v.Hash, string(v.R), string(v.S)); err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	return records, scanner.Err()
+}
+
+// ReadCSV reads CSV with a header naming the columns msg, hash, r, s and
+// pub in any order. Either msg or hash has to be present.
+func ReadCSV(r io.Reader) ([]Record, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("nonce: reading CSV header: %v", err)
+	}
+	col := make(map[string]int)
+	for i, name := range header {
+		col[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"r", "s", "pub"} {
+		if _, ok := col[name]; !ok {
+			return nil, fmt.Errorf("nonce: CSV has no %s column", name)
+		}
+	}
+	_, hasMsg := col["msg"]
+	_, hasHash := col["hash"]
+	if !hasMsg && !hasHash {
+		return nil, fmt.Errorf("nonce: CSV has neither msg nor hash column")
+	}
+	get := func(row []string, name string) string {
+		if i, ok := col[name]; ok {
+			return row[i]
+		}
+		return ""
+	}
+
+	var records []Record
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("nonce: %v", err)
+		}
+		line, _ := cr.FieldPos(0)
+		rec := Record{Line: line, PublicKey: get(row, "pub")}
+		if hasMsg {
+			rec.Message = []byte(get(row, "msg"))
+		}
+		if err := rec.fill(get(row, "hash"), get(row, "r"), get(row, "s")); err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+}
@@ -0,0 +1,120 @@
+package nonce
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReadChallenge44(t *testing.T) {
+	in := `msg: Listen for me, you better listen for me now. 
+s: 1267396447369736888040262262183731677867615804316
+r: 1105520928110492191417703162650245113664610474875
+m: a4db3de27e2db3e5ef085ced2bced91b82e0df19
+msg: Listen for me, you better listen for me now. 
+s: 29097472083055673620219739525237952924429516683
+r: 51241962016175933742870323080382366896234169532
+m: 4db3de27e2db3e5ef085ced2bced91b82e0df19
+`
+	records, err := ReadChallenge44(strings.NewReader(in), "y")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records; want 2", len(records))
+	}
+	rec := records[0]
+	if rec.Line != 1 || rec.PublicKey != "y" || string(rec.Message) != "Listen for me, you better listen for me now. " ||
+		rec.S.String() != "1267396447369736888040262262183731677867615804316" ||
+		rec.R.String() != "1105520928110492191417703162650245113664610474875" || len(rec.Hash) != 20 {
+		t.Errorf("first record = %+v", rec)
+	}
+	// a hash with a missing leading zero still decodes
+	if records[1].Line != 5 || len(records[1].Hash) != 20 || records[1].Hash[0] != 0x04 {
+		t.Errorf("second record = %+v", records[1])
+	}
+
+	for _, bad := range []string{
+		"msg: a\ns: 1\nr: 2\n",
+		"msg: a\ns: 1\nmsg: b\nr: 2\nm: 00\n",
+		"msg: a\ns: x\nr: 2\nm: 00\n",
+		"no separator\n",
+	} {
+		if _, err := ReadChallenge44(strings.NewReader(bad), ""); err == nil {
+			t.Errorf("ReadChallenge44(%q) did not fail", bad)
+		}
+	}
+}
+
+func TestReadJSONL(t *testing.T) {
+	in := `{"msg": "hello", "r": "0x10", "s": 17, "pub": "abc"}
+
+{"hash": "00ff", "r": 1, "s": "2", "pub": "abc"}
+{"hash": "0X1Ff", "r": 1, "s": "2", "pub": "abc"}
+`
+	records, err := ReadJSONL(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records; want 3", len(records))
+	}
+	if rec := records[0]; rec.Line != 1 || string(rec.Message) != "hello" || rec.Hash != nil ||
+		rec.R.Int64() != 16 || rec.S.Int64() != 17 || rec.PublicKey != "abc" {
+		t.Errorf("first record = %+v", rec)
+	}
+	if rec := records[1]; rec.Line != 3 || rec.Message != nil || !bytes.Equal(rec.Hash, []byte{0, 0xff}) {
+		t.Errorf("second record = %+v", rec)
+	}
+	if rec := records[2]; rec.Line != 4 || !bytes.Equal(rec.Hash, []byte{1, 0xff}) {
+		t.Errorf("third record = %+v", rec)
+	}
+	for _, bad := range []string{
+		`{"r": 1, "s": 2, "pub": "abc"}`,
+		`{"msg": "x", "r": "one", "s": 2}`,
+		`{"msg": "x", "r": 1, "s": 2, "hash": "zz"}`,
+		`not json`,
+	} {
+		if _, err := ReadJSONL(strings.NewReader(bad)); err == nil {
+			t.Errorf("ReadJSONL(%q) did not fail", bad)
+		}
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	in := "pub,S,r,msg\nabc,2,0x3,\"hello, world\"\ndef,4,5,\"multi\nline\"\nabc,6,7,x\n"
+	records, err := ReadCSV(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records; want 3", len(records))
+	}
+	if rec := records[0]; rec.Line != 2 || string(rec.Message) != "hello, world" || rec.R.Int64() != 3 ||
+		rec.S.Int64() != 2 || rec.PublicKey != "abc" {
+		t.Errorf("first record = %+v", rec)
+	}
+	if rec := records[2]; rec.Line != 5 || rec.PublicKey != "abc" {
+		t.Errorf("third record = %+v", rec)
+	}
+	records, err = ReadCSV(strings.NewReader("pub,r,s,hash\nabc,1,2,0XABCD\nabc,1,2,0xabcd\n"))
+	if err != nil || len(records) != 2 {
+		t.Fatalf("ReadCSV with 0X hashes = %d records, %v", len(records), err)
+	}
+	for _, rec := range records {
+		if !bytes.Equal(rec.Hash, []byte{0xab, 0xcd}) {
+			t.Errorf("line %d: hash = %x; want abcd", rec.Line, rec.Hash)
+		}
+	}
+	for _, bad := range []string{
+		"",
+		"msg,r,s\nx,1,2\n",
+		"pub,r,s\nx,1,2\n",
+		"pub,r,s,hash\nx,1,2,zz\n",
+		"pub,r,s,msg\nx,1,2\n",
+	} {
+		if _, err := ReadCSV(strings.NewReader(bad)); err == nil {
+			t.Errorf("ReadCSV(%q) did not fail", bad)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+// Package primes has the small primes and the trial division shared by the
+// attacks that work in small subgroups or need smooth numbers.
+package primes
+
+import "math/big"
+
+// Sieve returns all primes below n using the sieve of Eratosthenes.
+func Sieve(n int) []int64 {
+	var primes []int64
+	composite := make([]bool, n)
+	for i := 2; i < n; i++ {
+		if composite[i] {
+			continue
+		}
+		primes = append(primes, int64(i))
+		for j := i * i; j < n; j += i {
+			composite[j] = true
+		}
+	}
+	return primes
+}
+
+// SmallFactors returns the distinct primes below bound dividing n > 0, in
+// increasing order, found by trial division with the primes of Sieve. It
+// stops early once the factors found cover n.
+func SmallFactors(n *big.Int, bound int) []*big.Int {
+	var res []*big.Int
+	rest := new(big.Int).Set(n)
+	r, q, m := new(big.Int), new(big.Int), new(big.Int)
+	for _, p := range Sieve(bound) {
+		if rest.BitLen() <= 1 {
+			break
+		}
+		r.SetInt64(p)
+		if m.Mod(rest, r).Sign() != 0 {
+			continue
+		}
+		res = append(res, big.NewInt(p))
+		for {
+			q.QuoRem(rest, r, m)
+			if m.Sign() != 0 {
+				break
+			}
+			rest.Set(q)
+		}
+	}
+	return res
+}
@@ -0,0 +1,41 @@
+package primes
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestSieve(t *testing.T) {
+	primes := Sieve(100)
+	if len(primes) != 25 || primes[0] != 2 || primes[24] != 97 {
+		t.Errorf("Sieve(100) = %v", primes)
+	}
+	if primes := Sieve(2); len(primes) != 0 {
+		t.Errorf("Sieve(2) = %v; want none", primes)
+	}
+}
+
+func TestSmallFactors(t *testing.T) {
+	tests := []struct {
+		n     int64
+		bound int
+		want  []int64
+	}{
+		{2 * 2 * 3 * 5 * 5 * 1021 * 65537, 1 << 16, []int64{2, 3, 5, 1021}},
+		{2 * 2 * 2 * 3 * 11 * 101 * 1009, 200, []int64{2, 3, 11, 101}},
+		{1 << 40, 100, []int64{2}},
+		{1, 100, nil},
+	}
+	for _, tc := range tests {
+		got := SmallFactors(big.NewInt(tc.n), tc.bound)
+		if len(got) != len(tc.want) {
+			t.Errorf("SmallFactors(%d, %d) = %v; want %v", tc.n, tc.bound, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if got[i].Int64() != tc.want[i] {
+				t.Errorf("SmallFactors(%d, %d) = %v; want %v", tc.n, tc.bound, got, tc.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// Package attacks recovers RSA private keys from badly generated public
+// keys: small private exponents, primes too close to each other, primes
+// shared between moduli and primes p with a smooth p-1. It also decrypts a
+// message encrypted under two exponents of a common modulus and makes keys
+// under which someone else's signature verifies. Every key recovery comes
+// with a generator of deliberately weak keys to try it on.
+package attacks
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ysmolsky/cryptopals/tools/rsa"
+)
+
+// ErrNotFound is returned when an attack does not apply to the key.
+var ErrNotFound = errors.New("attacks: key is not vulnerable")
+
+// weakE is the public exponent of the generated keys, except for Wiener's
+// attack where e follows from the small d.
+const weakE = 65537
+
+var (
+	one = big.NewInt(1)
+	two = big.NewInt(2)
+)
+
+// fromFactor builds the private key of pub from a nontrivial factor p of N.
+func fromFactor(pub *rsa.PublicKey, p *big.Int) (*rsa.PrivateKey, error) {
+	q, r := new(big.Int).QuoRem(pub.N, p, new(big.Int))
+	if r.Sign() != 0 || p.Cmp(one) <= 0 || q.Cmp(one) <= 0 {
+		return nil, ErrNotFound
+	}
+	if p.Cmp(q) < 0 {
+		p, q = q, p
+	}
+	return rsa.NewPrivateKey(p, q, pub.E)
+}
+
+// fromSum builds the private key of pub from s = p + q. The primes are the
+// roots of x^2 - s x + N.
+func fromSum(pub *rsa.PublicKey, s *big.Int) (*rsa.PrivateKey, error) {
+	// disc = s^2 - 4N = (p - q)^2
+	disc := new(big.Int).Mul(s, s)
+	disc.Sub(disc, new(big.Int).Lsh(pub.N, 2))
+	if disc.Sign() < 0 {
+		return nil, ErrNotFound
+	}
+	root := new(big.Int).Sqrt(disc)
+	if new(big.Int).Mul(root, root).Cmp(disc) != 0 {
+		return nil, ErrNotFound
+	}
+	p := root.Add(root, s)
+	if p.Bit(0) != 0 {
+		return nil, ErrNotFound
+	}
+	return fromFactor(pub, p.Rsh(p, 1))
+}
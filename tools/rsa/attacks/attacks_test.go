@@ -0,0 +1,76 @@
+package attacks
+
+import (
+	"testing"
+
+	"github.com/ysmolsky/cryptopals/tools/rsa"
+)
+
+// checkRecovered fails the test unless got is the private key of want.
+func checkRecovered(t *testing.T, name string, want, got *rsa.PrivateKey, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	if got.N.Cmp(want.N) != 0 || got.D.Cmp(want.D) != 0 {
+		t.Fatalf("%s recovered the wrong key: N = %x, D = %x", name, got.N, got.D)
+	}
+	ct, err := want.Encrypt([]byte(name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pt, err := got.Decrypt(ct)
+	if err != nil || string(pt[len(pt)-len(name):]) != name {
+		t.Errorf("%s: decryption with the recovered key = %q, %v", name, pt, err)
+	}
+}
+
+func TestWiener(t *testing.T) {
+	for _, bits := range []int{256, 511, 1024, 2048} {
+		weak, err := WienerKey(nil, bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if weak.N.BitLen() != bits || weak.D.BitLen() > bits/4-2 {
+			t.Fatalf("WienerKey(%d) has N of %d bits and D of %d bits", bits, weak.N.BitLen(), weak.D.BitLen())
+		}
+		got, err := Wiener(&weak.PublicKey)
+		checkRecovered(t, "Wiener", weak, got, err)
+	}
+	strong, err := rsa.GenerateKey(nil, 512, 65537)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Wiener(&strong.PublicKey); err != ErrNotFound {
+		t.Errorf("Wiener on a key with e = 65537 = %v; want %v", err, ErrNotFound)
+	}
+}
+
+func TestFermat(t *testing.T) {
+	for _, tc := range []struct{ bits, gap int }{{256, 20}, {512, 100}, {1024, 200}} {
+		weak, err := FermatKey(nil, tc.bits, tc.gap)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if weak.N.BitLen() != tc.bits {
+			t.Fatalf("FermatKey(%d) has N of %d bits", tc.bits, weak.N.BitLen())
+		}
+		got, err := Fermat(&weak.PublicKey, 1)
+		checkRecovered(t, "Fermat", weak, got, err)
+	}
+	// a gap above bits/4 takes (p - q)^2 / (8 sqrt(N)), about 2^13 steps here
+	weak, err := FermatKey(nil, 256, 72)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Fermat(&weak.PublicKey, 1<<16)
+	checkRecovered(t, "Fermat", weak, got, err)
+
+	strong, err := rsa.GenerateKey(nil, 512, 65537)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Fermat(&strong.PublicKey, 1000); err != ErrNotFound {
+		t.Errorf("Fermat on a random key = %v; want %v", err, ErrNotFound)
+	}
+}
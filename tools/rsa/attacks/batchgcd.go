@@ -0,0 +1,119 @@
+package attacks
+
+import (
+	"crypto/rand"
+	"io"
+	"math/big"
+
+	"github.com/ysmolsky/cryptopals/tools/rsa"
+)
+
+// BatchGCD returns gcd(n_i, prod_{j != i} n_j) for every modulus with
+// Bernstein's product and remainder trees. Computing all of them costs about
+// as much as a few multiplications of the whole product instead of a GCD
+// per pair. A result other than 1 means the modulus shares a prime with
+// another one.
+func BatchGCD(ns []*big.Int) []*big.Int {
+	if len(ns) == 0 {
+		return nil
+	}
+	// tree[0] are the moduli, tree[i+1][j] = tree[i][2j] * tree[i][2j+1]
+	tree := [][]*big.Int{ns}
+	for level := ns; len(level) > 1; {
+		next := make([]*big.Int, (len(level)+1)/2)
+		for j := range next {
+			next[j] = new(big.Int).Set(level[2*j])
+			if 2*j+1 < len(level) {
+				next[j].Mul(next[j], level[2*j+1])
+			}
+		}
+		tree = append(tree, next)
+		level = next
+	}
+	// going down, rem[j] = prod mod tree[i][j]^2
+	rem := tree[len(tree)-1]
+	sq := new(big.Int)
+	for i := len(tree) - 2; i >= 0; i-- {
+		next := make([]*big.Int, len(tree[i]))
+		for j, n := range tree[i] {
+			next[j] = new(big.Int).Mod(rem[j/2], sq.Mul(n, n))
+		}
+		rem = next
+	}
+	// prod mod n^2 / n = (prod / n) mod n
+	gs := make([]*big.Int, len(ns))
+	for i, n := range ns {
+		r := new(big.Int).Quo(rem[i], n)
+		gs[i] = r.GCD(nil, nil, r, n)
+	}
+	return gs
+}
+
+// SharedPrimes factors the keys which share a prime with another key of
+// the list. The result has a private key at the index of every broken key
+// and nil elsewhere.
+func SharedPrimes(pubs []*rsa.PublicKey) []*rsa.PrivateKey {
+	ns := make([]*big.Int, len(pubs))
+	for i, pub := range pubs {
+		ns[i] = pub.N
+	}
+	privs := make([]*rsa.PrivateKey, len(pubs))
+	g := new(big.Int)
+	for i, p := range BatchGCD(ns) {
+		if p.Cmp(one) == 0 {
+			continue
+		}
+		if p.Cmp(ns[i]) == 0 {
+			// both primes are shared, find them one modulus at a time
+			for j := range ns {
+				g.GCD(nil, nil, ns[i], ns[j])
+				if j != i && g.Cmp(one) != 0 && g.Cmp(ns[i]) != 0 {
+					p = g
+					break
+				}
+			}
+		}
+		if priv, err := fromFactor(pubs[i], p); err == nil {
+			privs[i] = priv
+		}
+	}
+	return privs
+}
+
+// SharedPrimeKeys generates count keys of bits bits. The first weak of them
+// all contain the same prime, the others are independent.
+func SharedPrimeKeys(rnd io.Reader, bits, count, weak int) ([]*rsa.PrivateKey, error) {
+	if rnd == nil {
+		rnd = rand.Reader
+	}
+	if bits < 16 {
+		return nil, rsa.ErrKeySize
+	}
+	e := big.NewInt(weakE)
+	shared, err := rand.Prime(rnd, bits/2)
+	if err != nil {
+		return nil, err
+	}
+	privs := make([]*rsa.PrivateKey, 0, count)
+	for len(privs) < count {
+		p := shared
+		if len(privs) >= weak {
+			if p, err = rand.Prime(rnd, bits/2); err != nil {
+				return nil, err
+			}
+		}
+		q, err := rand.Prime(rnd, bits-bits/2)
+		if err != nil {
+			return nil, err
+		}
+		priv, err := rsa.NewPrivateKey(p, q, e)
+		if err == rsa.ErrExponent || err == nil && priv.N.BitLen() != bits {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		privs = append(privs, priv)
+	}
+	return privs, nil
+}
@@ -0,0 +1,67 @@
+package attacks
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ysmolsky/cryptopals/tools/rsa"
+)
+
+func TestBatchGCD(t *testing.T) {
+	ns := []*big.Int{
+		big.NewInt(3 * 5), big.NewInt(7 * 11), big.NewInt(5 * 13),
+		big.NewInt(17 * 19), big.NewInt(11 * 3), big.NewInt(23 * 29),
+		big.NewInt(31 * 37),
+	}
+	want := []int64{15, 11, 5, 1, 33, 1, 1}
+	gs := BatchGCD(ns)
+	for i, g := range gs {
+		if g.Int64() != want[i] {
+			t.Errorf("BatchGCD[%d] = %v; want %d", i, g, want[i])
+		}
+	}
+	if ns[0].Int64() != 15 {
+		t.Errorf("BatchGCD modified its input")
+	}
+	if gs := BatchGCD(ns[:1]); gs[0].Int64() != 1 || ns[0].Int64() != 15 {
+		t.Errorf("BatchGCD of one modulus = %v; want 1", gs[0])
+	}
+	if BatchGCD(nil) != nil {
+		t.Errorf("BatchGCD(nil) is not empty")
+	}
+}
+
+func TestSharedPrimes(t *testing.T) {
+	const count, weak = 50, 3
+	keys, err := SharedPrimeKeys(nil, 512, count, weak)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubs := make([]*rsa.PublicKey, len(keys))
+	for i, k := range keys {
+		pubs[i] = &k.PublicKey
+	}
+	// move the weak keys around
+	pubs[1], pubs[20] = pubs[20], pubs[1]
+	keys[1], keys[20] = keys[20], keys[1]
+	// a key made of two shared primes is broken as well
+	both, err := rsa.NewPrivateKey(keys[20].P, keys[40].P, keys[40].E)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys = append(keys, both)
+	pubs = append(pubs, &both.PublicKey)
+
+	privs := SharedPrimes(pubs)
+	for i, priv := range privs {
+		broken := i == 0 || i == 20 || i == 2 || i == 40 || i == count
+		switch {
+		case broken && priv == nil:
+			t.Errorf("key %d was not broken", i)
+		case broken:
+			checkRecovered(t, "SharedPrimes", keys[i], priv, nil)
+		case priv != nil:
+			t.Errorf("key %d does not share a prime but was broken", i)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package attacks
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/ysmolsky/cryptopals/tools/rsa"
+)
+
+var (
+	// ErrModulus is returned by CommonModulus for keys with different moduli.
+	ErrModulus = errors.New("attacks: keys do not share a modulus")
+	// ErrExponents is returned for public exponents which are not coprime.
+	ErrExponents = errors.New("attacks: public exponents are not coprime")
+)
+
+// CommonModulus decrypts a message encrypted unpadded under two keys with
+// the same modulus and coprime exponents e1 and e2. With u e1 + v e2 = 1,
+// c1^u c2^v = m^(u e1 + v e2) = m. The message is returned as Size bytes.
+func CommonModulus(pub1, pub2 *rsa.PublicKey, c1, c2 []byte) ([]byte, error) {
+	n := pub1.N
+	if n.Cmp(pub2.N) != 0 {
+		return nil, ErrModulus
+	}
+	u, v, g := rsa.ExtendedGCD(pub1.E, pub2.E)
+	if g.Cmp(one) != 0 {
+		return nil, ErrExponents
+	}
+	m1, err := expSigned(new(big.Int).SetBytes(c1), u, n)
+	if err != nil {
+		return nil, err
+	}
+	m2, err := expSigned(new(big.Int).SetBytes(c2), v, n)
+	if err != nil {
+		return nil, err
+	}
+	m := m1.Mul(m1, m2)
+	m.Mod(m, n)
+	return m.FillBytes(make([]byte, pub1.Size())), nil
+}
+
+// expSigned returns c^x mod n for a possibly negative x.
+func expSigned(c, x, n *big.Int) (*big.Int, error) {
+	if c.Cmp(n) >= 0 {
+		return nil, rsa.ErrMessageTooLong
+	}
+	if x.Sign() >= 0 {
+		return new(big.Int).Exp(c, x, n), nil
+	}
+	inv, err := rsa.Invmod(c, n)
+	if err != nil {
+		return nil, err
+	}
+	return inv.Exp(inv, new(big.Int).Neg(x), n), nil
+}
+
+// CommonModulusKeys generates two keys with the same modulus of bits bits
+// and the coprime public exponents e1 and e2.
+func CommonModulusKeys(rnd io.Reader, bits int, e1, e2 int64) (*rsa.PrivateKey, *rsa.PrivateKey, error) {
+	E1, E2 := big.NewInt(e1), big.NewInt(e2)
+	if new(big.Int).GCD(nil, nil, E1, E2).Cmp(one) != 0 {
+		return nil, nil, ErrExponents
+	}
+	for {
+		priv1, err := rsa.GenerateKey(rnd, bits, e1)
+		if err != nil {
+			return nil, nil, err
+		}
+		priv2, err := rsa.NewPrivateKey(priv1.P, priv1.Q, E2)
+		if err == rsa.ErrExponent {
+			continue
+		}
+		return priv1, priv2, err
+	}
+}
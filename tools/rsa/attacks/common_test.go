@@ -0,0 +1,58 @@
+package attacks
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ysmolsky/cryptopals/tools/rsa"
+)
+
+func TestCommonModulus(t *testing.T) {
+	msg := []byte("one modulus for the whole team")
+	for _, e := range [][2]int64{{3, 65537}, {65537, 3}, {5, 7}, {17, 65537}} {
+		priv1, priv2, err := CommonModulusKeys(nil, 512, e[0], e[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if priv1.N.Cmp(priv2.N) != 0 || priv1.D.Cmp(priv2.D) == 0 {
+			t.Fatalf("CommonModulusKeys(%d, %d) gave unrelated keys", e[0], e[1])
+		}
+		c1, err := priv1.Encrypt(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c2, err := priv2.Encrypt(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := CommonModulus(&priv1.PublicKey, &priv2.PublicKey, c1, c2)
+		if err != nil {
+			t.Fatalf("e = %v: %v", e, err)
+		}
+		if !bytes.Equal(bytes.TrimLeft(got, "\x00"), msg) || len(got) != priv1.Size() {
+			t.Errorf("e = %v: CommonModulus = %q", e, got)
+		}
+	}
+}
+
+func TestCommonModulusErrors(t *testing.T) {
+	if _, _, err := CommonModulusKeys(nil, 256, 3, 9); err != ErrExponents {
+		t.Errorf("CommonModulusKeys(3, 9) = %v; want %v", err, ErrExponents)
+	}
+	priv1, err := rsa.GenerateKey(nil, 256, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv2, err := rsa.GenerateKey(nil, 256, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c1, _ := priv1.Encrypt([]byte("x"))
+	c2, _ := priv2.Encrypt([]byte("x"))
+	if _, err := CommonModulus(&priv1.PublicKey, &priv2.PublicKey, c1, c2); err != ErrModulus {
+		t.Errorf("CommonModulus of different moduli = %v; want %v", err, ErrModulus)
+	}
+	if _, err := CommonModulus(&priv1.PublicKey, &priv1.PublicKey, c1, c1); err != ErrExponents {
+		t.Errorf("CommonModulus with e1 = e2 = %v; want %v", err, ErrExponents)
+	}
+}
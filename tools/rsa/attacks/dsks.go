@@ -0,0 +1,128 @@
+package attacks
+
+import (
+	"crypto"
+	"crypto/rand"
+	"io"
+	"math/big"
+
+	"github.com/ysmolsky/cryptopals/tools/dlog"
+	"github.com/ysmolsky/cryptopals/tools/primes"
+	"github.com/ysmolsky/cryptopals/tools/rsa"
+)
+
+// dsksBound limits the prime factors of p-1 and q-1 in DSKS. The discrete
+// logarithms are brute forced in subgroups of these orders.
+const dsksBound = 1 << 12
+
+// primitiveRoot reports whether g generates the multiplicative group mod p,
+// where p - 1 = 2 r_1 ... r_k.
+func primitiveRoot(g, p *big.Int, factors []*big.Int) bool {
+	p1 := new(big.Int).Sub(p, one)
+	e, t := new(big.Int), new(big.Int)
+	for _, r := range factors {
+		if t.Exp(g, e.Quo(p1, r), p).Cmp(one) == 0 {
+			return false
+		}
+	}
+	return t.Mod(g, p).Sign() != 0
+}
+
+// dsksPrime returns a prime p in [lo, hi] with smooth p - 1 whose odd
+// factors are not in exclude and for which s and m are primitive roots, and
+// the prime factors of p - 1.
+func dsksPrime(rnd io.Reader, lo, hi, s, m *big.Int, small []int64, exclude map[int64]bool) (*big.Int, []*big.Int, error) {
+	for {
+		p, odd, err := smoothPrime(rnd, lo, hi, small, exclude)
+		if err != nil {
+			return nil, nil, err
+		}
+		factors := []*big.Int{two}
+		for _, r := range odd {
+			factors = append(factors, big.NewInt(r))
+		}
+		if primitiveRoot(s, p, factors) && primitiveRoot(m, p, factors) {
+			return p, factors, nil
+		}
+	}
+}
+
+// logMod returns the logarithm of m to the base s mod p, given the prime
+// factors of p - 1.
+func logMod(p, s, m *big.Int, factors []*big.Int) (dlog.Congruence, error) {
+	g := new(big.Int).Mod(s, p)
+	h := new(big.Int).Mod(m, p)
+	return dlog.PohligHellman[*big.Int](dlog.ModP{P: p}, g, h, factors)
+}
+
+// DSKS is the RSA half of the duplicate-signature key selection of
+// challenge 61: it returns a new key of the same size under which sig, a
+// valid PKCS #1 v1.5 signature of hashed by pub, verifies too. That means
+// finding e' with sig^e' = m mod N' for the padded hash m, a discrete
+// logarithm which is easy for N' = p q with smooth p-1 and q-1. Both primes
+// are chosen with sig and m as primitive roots, so the logarithms exist and
+// e' is invertible, and with no common factor in p-1 and q-1 but 2, so the
+// logarithms modulo p-1 and q-1 combine with CRT. N' is larger than N, so
+// sig and m fit, but not longer. e' is about as long as N'. If sig does not
+// verify, the error of rsa.VerifyPKCS1v15 is returned. rnd defaults to
+// crypto/rand.Reader when nil.
+func DSKS(rnd io.Reader, pub *rsa.PublicKey, hash crypto.Hash, hashed, sig []byte) (*rsa.PrivateKey, error) {
+	if err := rsa.VerifyPKCS1v15(pub, hash, hashed, sig); err != nil {
+		return nil, err
+	}
+	if rnd == nil {
+		rnd = rand.Reader
+	}
+	em, err := rsa.EncodePKCS1v15Signature(pub.Size(), hash, hashed)
+	if err != nil {
+		return nil, err
+	}
+	m := new(big.Int).SetBytes(em)
+	s := new(big.Int).SetBytes(sig)
+	small := primes.Sieve(dsksBound)
+
+	// N < N' < 2^(8 Size) keeps the length of signatures
+	bits := pub.N.BitLen()
+	limit := new(big.Int).Lsh(one, uint(8*pub.Size()))
+	plo := new(big.Int).Lsh(one, uint(bits/2-1))
+	phi := new(big.Int).Lsh(plo, 1)
+	for {
+		p, pf, err := dsksPrime(rnd, plo, phi, s, m, small, nil)
+		if err != nil {
+			return nil, err
+		}
+		exclude := make(map[int64]bool)
+		for _, r := range pf[1:] {
+			exclude[r.Int64()] = true
+		}
+		// q in [N/p + 1, (2^(8 Size) - 1) / p]
+		qlo := new(big.Int).Quo(pub.N, p)
+		qlo.Add(qlo, one)
+		qhi := new(big.Int).Sub(limit, one)
+		qhi.Quo(qhi, p)
+		q, qf, err := dsksPrime(rnd, qlo, qhi, s, m, small, exclude)
+		if err == ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		ep, err := logMod(p, s, m, pf)
+		if err != nil {
+			return nil, err
+		}
+		eq, err := logMod(q, s, m, qf)
+		if err != nil {
+			return nil, err
+		}
+		e, err := dlog.CRT([]dlog.Congruence{ep, eq})
+		if err != nil {
+			return nil, err
+		}
+		if p.Cmp(q) < 0 {
+			p, q = q, p
+		}
+		return rsa.NewPrivateKey(p, q, e.A)
+	}
+}
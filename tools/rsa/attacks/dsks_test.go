@@ -0,0 +1,47 @@
+package attacks
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/ysmolsky/cryptopals/tools/rsa"
+)
+
+func TestDSKS(t *testing.T) {
+	for _, bits := range []int{512, 1024, 2048} {
+		if testing.Short() && bits > 1024 {
+			continue
+		}
+		alice, err := rsa.GenerateKey(nil, bits, weakE)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashed := sha256.Sum256([]byte("I, Alice, owe Eve a million dollars"))
+		sig, err := rsa.SignPKCS1v15(alice, crypto.SHA256, hashed[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		eve, err := DSKS(nil, &alice.PublicKey, crypto.SHA256, hashed[:], sig)
+		if err != nil {
+			t.Fatalf("%d bits: %v", bits, err)
+		}
+		if err := rsa.VerifyPKCS1v15(&eve.PublicKey, crypto.SHA256, hashed[:], sig); err != nil {
+			t.Errorf("%d bits: Alice's signature under Eve's key: %v", bits, err)
+		}
+		if eve.N.Cmp(alice.N) <= 0 || eve.Size() != alice.Size() {
+			t.Errorf("%d bits: Eve's modulus has %d bits", bits, eve.N.BitLen())
+		}
+		// Eve can sign too
+		mine := sha256.Sum256([]byte("signed by Eve"))
+		s2, err := rsa.SignPKCS1v15(eve, crypto.SHA256, mine[:])
+		if err != nil || rsa.VerifyPKCS1v15(&eve.PublicKey, crypto.SHA256, mine[:], s2) != nil {
+			t.Errorf("%d bits: Eve cannot sign with her key: %v", bits, err)
+		}
+
+		sig[len(sig)-1] ^= 1
+		if _, err := DSKS(nil, &alice.PublicKey, crypto.SHA256, hashed[:], sig); err != rsa.ErrVerification {
+			t.Errorf("%d bits: DSKS of a bad signature = %v; want %v", bits, err, rsa.ErrVerification)
+		}
+	}
+}
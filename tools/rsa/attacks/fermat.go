@@ -0,0 +1,73 @@
+package attacks
+
+import (
+	"crypto/rand"
+	"io"
+	"math/big"
+
+	"github.com/ysmolsky/cryptopals/tools/rsa"
+)
+
+// Fermat factors N as a^2 - b^2 = (a + b)(a - b), trying a = ceil(sqrt(N)),
+// ceil(sqrt(N)) + 1, ... for at most steps values. For primes p and q the
+// right a is (p + q) / 2, which is found in about (p - q)^2 / (8 sqrt(N))
+// steps, so the attack is immediate when the primes share their top half.
+func Fermat(pub *rsa.PublicKey, steps int) (*rsa.PrivateKey, error) {
+	n := pub.N
+	a := new(big.Int).Sqrt(n)
+	if new(big.Int).Mul(a, a).Cmp(n) != 0 {
+		a.Add(a, one)
+	}
+	// b2 = a^2 - N, which grows by 2a + 1 with every step
+	b2 := new(big.Int).Mul(a, a)
+	b2.Sub(b2, n)
+	b, sq := new(big.Int), new(big.Int)
+	for i := 0; i < steps; i++ {
+		b.Sqrt(b2)
+		if sq.Mul(b, b).Cmp(b2) == 0 {
+			return fromFactor(pub, b.Add(a, b))
+		}
+		b2.Add(b2, a)
+		b2.Add(b2, a)
+		b2.Add(b2, one)
+		a.Add(a, one)
+	}
+	return nil, ErrNotFound
+}
+
+// FermatKey generates a key of bits bits whose primes differ by less than
+// 2^gap, so that Fermat factors it in a single step for gap < bits/4. bits
+// has to be even.
+func FermatKey(rnd io.Reader, bits, gap int) (*rsa.PrivateKey, error) {
+	if rnd == nil {
+		rnd = rand.Reader
+	}
+	if bits < 16 || bits%2 != 0 || gap < 2 {
+		return nil, rsa.ErrKeySize
+	}
+	e := big.NewInt(weakE)
+	for {
+		q, err := rand.Prime(rnd, bits/2)
+		if err != nil {
+			return nil, err
+		}
+		// p is the first prime after q + delta
+		delta, err := rand.Int(rnd, new(big.Int).Lsh(one, uint(gap-1)))
+		if err != nil {
+			return nil, err
+		}
+		p := delta.Add(delta, q)
+		p.SetBit(p, 0, 1)
+		for p.Cmp(q) <= 0 || !p.ProbablyPrime(20) {
+			p.Add(p, two)
+		}
+		if new(big.Int).Sub(p, q).BitLen() > gap {
+			continue
+		}
+		priv, err := rsa.NewPrivateKey(p, q, e)
+		if err == rsa.ErrExponent || err == nil && priv.N.BitLen() != bits {
+			continue
+		}
+		return priv, err
+	}
+}
@@ -0,0 +1,141 @@
+package attacks
+
+import (
+	"crypto/rand"
+	"io"
+	"math/big"
+
+	"github.com/ysmolsky/cryptopals/tools/primes"
+	"github.com/ysmolsky/cryptopals/tools/rsa"
+)
+
+// PollardPM1 factors N with Pollard's p-1 method when p-1 is bound-smooth
+// and no prime power in it exceeds bound. Then a = 2^(bound!) is 1 mod p and
+// gcd(a - 1, N) = p. The GCD is taken every few steps; if both primes show up
+// at once, the last stretch is redone one step at a time.
+func PollardPM1(pub *rsa.PublicKey, bound int) (*rsa.PrivateKey, error) {
+	const stride = 64
+	n := pub.N
+	a, saved := big.NewInt(2), big.NewInt(2)
+	g, k := new(big.Int), new(big.Int)
+	from := 2
+	for i := 2; i <= bound; i++ {
+		a.Exp(a, k.SetInt64(int64(i)), n)
+		if (i-from+1)%stride != 0 && i != bound {
+			continue
+		}
+		g.GCD(nil, nil, g.Sub(a, one), n)
+		switch {
+		case g.Cmp(one) == 0:
+			saved.Set(a)
+			from = i + 1
+		case g.Cmp(n) == 0:
+			// backtrack to the last value with gcd 1
+			a.Set(saved)
+			for j := from; j <= i; j++ {
+				a.Exp(a, k.SetInt64(int64(j)), n)
+				g.GCD(nil, nil, g.Sub(a, one), n)
+				if g.Cmp(one) != 0 {
+					break
+				}
+			}
+			if g.Cmp(n) == 0 {
+				return nil, ErrNotFound
+			}
+			return fromFactor(pub, g)
+		default:
+			return fromFactor(pub, g)
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// SmoothKey generates a key of bits bits where p-1 is a product of 2 and
+// distinct primes below bound, so PollardPM1 with the same bound factors it.
+func SmoothKey(rnd io.Reader, bits, bound int) (*rsa.PrivateKey, error) {
+	if rnd == nil {
+		rnd = rand.Reader
+	}
+	// the odd primes below bound have to be enough for p
+	odd := primes.Sieve(bound)[1:]
+	all := big.NewInt(2)
+	for _, q := range odd {
+		all.Mul(all, big.NewInt(q))
+	}
+	if bits < 32 || all.BitLen() < bits {
+		return nil, rsa.ErrKeySize
+	}
+	e := big.NewInt(weakE)
+	lo := new(big.Int).Lsh(one, uint(bits/2-1))
+	hi := new(big.Int).Lsh(one, uint(bits/2))
+	hi.Sub(hi, one)
+	for {
+		p, _, err := smoothPrime(rnd, lo, hi, odd, nil)
+		if err != nil {
+			return nil, err
+		}
+		q, err := rand.Prime(rnd, bits-p.BitLen())
+		if err != nil {
+			return nil, err
+		}
+		priv, err := rsa.NewPrivateKey(p, q, e)
+		if err == rsa.ErrExponent || err == nil && priv.N.BitLen() != bits {
+			continue
+		}
+		return priv, err
+	}
+}
+
+// smoothPrime returns a prime p in [lo, hi] with p - 1 = 2 r_1 ... r_k for
+// distinct odd primes r_i taken from small and not in exclude, and the
+// r_i. Random primes are multiplied in until one more factor from the list
+// can land p in the interval, so hi should be at least twice lo.
+func smoothPrime(rnd io.Reader, lo, hi *big.Int, small []int64, exclude map[int64]bool) (*big.Int, []int64, error) {
+	largest := big.NewInt(small[len(small)-1])
+	nprimes := big.NewInt(int64(len(small)))
+	lo1 := new(big.Int).Sub(lo, one)
+	hi1 := new(big.Int).Sub(hi, one)
+	for attempt := 0; attempt < 1<<16; attempt++ {
+		prod := big.NewInt(2)
+		used := make(map[int64]bool)
+		var factors []int64
+		bound := new(big.Int)
+		for bound.Mul(prod, largest).Cmp(lo1) < 0 {
+			i, err := rand.Int(rnd, nprimes)
+			if err != nil {
+				return nil, nil, err
+			}
+			r := small[i.Int64()]
+			if r == 2 || used[r] || exclude[r] {
+				continue
+			}
+			used[r] = true
+			factors = append(factors, r)
+			prod.Mul(prod, big.NewInt(r))
+		}
+		// the last factor f has to satisfy lo <= prod f + 1 <= hi
+		fmin := new(big.Int).Add(lo1, prod)
+		fmin.Sub(fmin, one).Quo(fmin, prod)
+		fmax := new(big.Int).Quo(hi1, prod)
+		var last []int64
+		for _, r := range small {
+			if r != 2 && !used[r] && !exclude[r] && fmin.Cmp(big.NewInt(r)) <= 0 && fmax.Cmp(big.NewInt(r)) >= 0 {
+				last = append(last, r)
+			}
+		}
+		if len(last) == 0 {
+			continue
+		}
+		i, err := rand.Int(rnd, big.NewInt(int64(len(last))))
+		if err != nil {
+			return nil, nil, err
+		}
+		r := last[i.Int64()]
+		p := prod.Mul(prod, big.NewInt(r))
+		p.Add(p, one)
+		if p.ProbablyPrime(20) {
+			return p, append(factors, r), nil
+		}
+	}
+	return nil, nil, ErrNotFound
+}
@@ -0,0 +1,31 @@
+package attacks
+
+import (
+	"testing"
+
+	"github.com/ysmolsky/cryptopals/tools/rsa"
+)
+
+func TestPollardPM1(t *testing.T) {
+	for _, tc := range []struct{ bits, bound int }{{256, 1000}, {512, 1 << 12}, {1024, 1 << 14}} {
+		weak, err := SmoothKey(nil, tc.bits, tc.bound)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if weak.N.BitLen() != tc.bits {
+			t.Fatalf("SmoothKey(%d) has N of %d bits", tc.bits, weak.N.BitLen())
+		}
+		got, err := PollardPM1(&weak.PublicKey, tc.bound)
+		checkRecovered(t, "PollardPM1", weak, got, err)
+	}
+	strong, err := rsa.GenerateKey(nil, 512, 65537)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := PollardPM1(&strong.PublicKey, 1000); err != ErrNotFound {
+		t.Errorf("PollardPM1 on a random key = %v; want %v", err, ErrNotFound)
+	}
+	if _, err := SmoothKey(nil, 512, 100); err != rsa.ErrKeySize {
+		t.Errorf("SmoothKey with too few primes = %v; want %v", err, rsa.ErrKeySize)
+	}
+}
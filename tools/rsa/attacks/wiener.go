@@ -0,0 +1,86 @@
+package attacks
+
+import (
+	"crypto/rand"
+	"io"
+	"math/big"
+
+	"github.com/ysmolsky/cryptopals/tools/rsa"
+)
+
+// Wiener recovers the private key of a public key with a small private
+// exponent, d < N^(1/4) / 3, with Wiener's continued fraction attack. Since
+// e d = 1 + k phi(N) and phi(N) is close to N, k/d is one of the convergents
+// of e/N. Each convergent gives a candidate phi(N) and with it p + q.
+func Wiener(pub *rsa.PublicKey) (*rsa.PrivateKey, error) {
+	a, b := new(big.Int).Set(pub.E), new(big.Int).Set(pub.N)
+	// convergents h/k, starting from h_-2/k_-2 = 0/1 and h_-1/k_-1 = 1/0
+	h0, h1 := big.NewInt(0), big.NewInt(1)
+	k0, k1 := big.NewInt(1), big.NewInt(0)
+	quo, rem := new(big.Int), new(big.Int)
+	for b.Sign() != 0 {
+		quo.QuoRem(a, b, rem)
+		a, b = b, new(big.Int).Set(rem)
+		h0, h1 = h1, new(big.Int).Add(new(big.Int).Mul(quo, h1), h0)
+		k0, k1 = k1, new(big.Int).Add(new(big.Int).Mul(quo, k1), k0)
+		if priv, err := wienerTry(pub, h1, k1); err == nil {
+			return priv, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// wienerTry checks the guess k/d for the convergent.
+func wienerTry(pub *rsa.PublicKey, k, d *big.Int) (*rsa.PrivateKey, error) {
+	if k.Sign() == 0 || d.Bit(0) == 0 {
+		return nil, ErrNotFound
+	}
+	// phi = (e d - 1) / k, p + q = N - phi + 1
+	phi := new(big.Int).Mul(pub.E, d)
+	phi.Sub(phi, one)
+	phi, rem := phi.QuoRem(phi, k, new(big.Int))
+	if rem.Sign() != 0 {
+		return nil, ErrNotFound
+	}
+	s := new(big.Int).Sub(pub.N, phi)
+	return fromSum(pub, s.Add(s, one))
+}
+
+// WienerKey generates a key of bits bits with q < p < 2q and a random
+// private exponent d < N^(1/4) / 3, which Wiener's attack recovers.
+func WienerKey(rnd io.Reader, bits int) (*rsa.PrivateKey, error) {
+	if rnd == nil {
+		rnd = rand.Reader
+	}
+	if bits < 64 {
+		return nil, rsa.ErrKeySize
+	}
+	// N >= 2^(bits-1), so 2^(bits/4-2) < N^(1/4) / 3
+	dmax := new(big.Int).Lsh(one, uint(bits/4-2))
+	for {
+		p, err := rand.Prime(rnd, bits-bits/2)
+		if err != nil {
+			return nil, err
+		}
+		q, err := rand.Prime(rnd, bits/2)
+		if err != nil {
+			return nil, err
+		}
+		if p.Cmp(q) < 0 {
+			p, q = q, p
+		}
+		if p.Cmp(q) == 0 || p.Cmp(new(big.Int).Lsh(q, 1)) >= 0 {
+			continue
+		}
+		phi := new(big.Int).Mul(new(big.Int).Sub(p, one), new(big.Int).Sub(q, one))
+		d, err := rand.Int(rnd, dmax)
+		if err != nil {
+			return nil, err
+		}
+		e, err := rsa.Invmod(d, phi)
+		if err != nil || d.Cmp(two) <= 0 {
+			continue
+		}
+		return rsa.NewPrivateKey(p, q, e)
+	}
+}
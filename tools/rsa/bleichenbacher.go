@@ -0,0 +1,253 @@
+package rsa
+
+import (
+	"context"
+	"crypto/rand"
+	"math/big"
+)
+
+// PaddingOracle reports whether the RSA decryption of ct is PKCS conforming.
+type PaddingOracle func(ct []byte) bool
+
+// Conformance decides whether a decrypted block is PKCS conforming. The
+// checks below range from the weakest oracle, which helps the attack most,
+// to the strongest one.
+type Conformance func(em []byte) bool
+
+// ConformsPrefix only checks that em starts with 00 02, which is the oracle
+// of challenges 47 and 48.
+func ConformsPrefix(em []byte) bool {
+	return len(em) > 2 && em[0] == 0 && em[1] == 2
+}
+
+// ConformsPadding checks the complete block type 2 padding as
+// DecodePKCS1v15Encryption does.
+func ConformsPadding(em []byte) bool {
+	_, err := DecodePKCS1v15Encryption(em)
+	return err == nil
+}
+
+// ConformsLength returns a check which additionally requires the message to
+// be exactly n bytes long, as a server expecting a key of fixed size would.
+func ConformsLength(n int) Conformance {
+	return func(em []byte) bool {
+		msg, err := DecodePKCS1v15Encryption(em)
+		return err == nil && len(msg) == n
+	}
+}
+
+// NewPaddingOracle returns an oracle which decrypts with priv and checks the
+// block with conforms.
+func NewPaddingOracle(priv *PrivateKey, conforms Conformance) PaddingOracle {
+	return func(ct []byte) bool {
+		em, err := priv.Decrypt(ct)
+		return err == nil && conforms(em)
+	}
+}
+
+// BleichenbacherResult describes a run of Bleichenbacher.
+type BleichenbacherResult struct {
+	// Plaintext is the recovered block of Size bytes including its padding.
+	Plaintext []byte
+	// Queries is the number of oracle calls, Blinding the part of them
+	// spent in step 1.
+	Queries  int
+	Blinding int
+	// Iterations is the number of searches for s_i in step 2.
+	Iterations int
+}
+
+// interval is the closed range [a, b].
+type interval struct {
+	a, b *big.Int
+}
+
+// Bleichenbacher recovers the plaintext block of ct with the adaptive chosen
+// ciphertext attack of "Chosen Ciphertext Attacks Against Protocols Based on
+// the RSA Encryption Standard PKCS #1" (CRYPTO '98). oracle has to answer
+// true only for ciphertexts whose plaintext starts with 00 02; it may reject
+// some of those, which merely costs more queries.
+//
+// ct does not have to be PKCS conforming, step 1 blinds it with random
+// values until it is. The attack stops with ctx.Err() when ctx is done; the
+// result is never nil and always holds the number of queries made so far.
+func Bleichenbacher(ctx context.Context, pub *PublicKey, ct []byte, oracle PaddingOracle) (*BleichenbacherResult, error) {
+	res := new(BleichenbacherResult)
+	n := pub.N
+	k := pub.Size()
+	if k < 11 {
+		return res, ErrKeySize
+	}
+	B := new(big.Int).Lsh(one, uint(8*(k-2)))
+	B2 := new(big.Int).Lsh(B, 1)
+	B3 := new(big.Int).Add(B2, B)
+	B3m1 := new(big.Int).Sub(B3, one)
+
+	c0 := new(big.Int).SetBytes(ct)
+	if c0.Cmp(n) >= 0 {
+		return res, ErrMessageTooLong
+	}
+	// conforming reports whether c0 * s^e decrypts to a conforming block
+	conforming := func(s *big.Int) (bool, error) {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		res.Queries++
+		c := pub.EncryptInt(s)
+		c.Mul(c, c0)
+		c.Mod(c, n)
+		return oracle(c.FillBytes(make([]byte, k))), nil
+	}
+
+	// Step 1: blinding, find s0 such that c0 * s0^e is conforming
+	s0 := big.NewInt(1)
+	for {
+		ok, err := conforming(s0)
+		if err != nil {
+			res.Blinding = res.Queries
+			return res, err
+		}
+		if ok {
+			break
+		}
+		var e error
+		if s0, e = rand.Int(rand.Reader, n); e != nil {
+			return res, e
+		}
+	}
+	res.Blinding = res.Queries
+	c0 = pub.EncryptInt(s0)
+	c0.Mul(c0, new(big.Int).SetBytes(ct))
+	c0.Mod(c0, n)
+
+	M := []interval{{new(big.Int).Set(B2), new(big.Int).Set(B3m1)}}
+	s := new(big.Int)
+	for i := 1; ; i++ {
+		res.Iterations = i
+		var err error
+		switch {
+		case i == 1:
+			// Step 2a: the smallest s1 >= n/(3B) which is conforming
+			s, err = searchFrom(ceilDiv(n, B3), conforming)
+		case len(M) > 1:
+			// Step 2b: several intervals left, continue the linear search
+			s, err = searchFrom(new(big.Int).Add(s, one), conforming)
+		default:
+			// Step 2c: one interval left, search s in ranges which halve the
+			// interval with every step
+			s, err = searchOne(M[0], s, n, B2, B3, conforming)
+		}
+		if err != nil {
+			return res, err
+		}
+
+		// Step 3: narrow the set of solutions
+		M = narrow(M, s, n, B2, B3m1)
+		if len(M) == 0 {
+			// only an oracle accepting non-conforming blocks gets here
+			return res, ErrOracle
+		}
+
+		// Step 4: a single value left
+		if len(M) == 1 && M[0].a.Cmp(M[0].b) == 0 {
+			inv, err := Invmod(s0, n)
+			if err != nil {
+				return res, err
+			}
+			m := inv.Mul(inv, M[0].a)
+			m.Mod(m, n)
+			res.Plaintext = m.FillBytes(make([]byte, k))
+			return res, nil
+		}
+	}
+}
+
+// searchFrom returns the smallest s >= start for which conforming holds.
+func searchFrom(start *big.Int, conforming func(*big.Int) (bool, error)) (*big.Int, error) {
+	s := new(big.Int).Set(start)
+	for {
+		ok, err := conforming(s)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return s, nil
+		}
+		s.Add(s, one)
+	}
+}
+
+// searchOne is step 2c for the single interval [a, b]: for r starting at
+// 2(b s - 2B)/n it tries every s in [(2B + r n)/b, (3B + r n)/a).
+func searchOne(m interval, prev, n, B2, B3 *big.Int, conforming func(*big.Int) (bool, error)) (*big.Int, error) {
+	r := new(big.Int).Mul(m.b, prev)
+	r.Sub(r, B2)
+	r.Lsh(r, 1)
+	r = ceilDiv(r, n)
+	rn, lo, hi := new(big.Int), new(big.Int), new(big.Int)
+	for ; ; r.Add(r, one) {
+		rn.Mul(r, n)
+		lo = ceilDiv(lo.Add(B2, rn), m.b)
+		hi = ceilDiv(hi.Add(B3, rn), m.a)
+		for s := lo; s.Cmp(hi) < 0; s.Add(s, one) {
+			ok, err := conforming(s)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				return new(big.Int).Set(s), nil
+			}
+		}
+	}
+}
+
+// narrow is step 3: for every interval [a, b] and every r with
+// (a s - 3B + 1)/n <= r <= (b s - 2B)/n it keeps
+// [max(a, (2B + r n)/s), min(b, (3B - 1 + r n)/s)]
+// and returns the union of the results.
+func narrow(M []interval, s, n, B2, B3m1 *big.Int) []interval {
+	var res []interval
+	rn := new(big.Int)
+	for _, m := range M {
+		rlo := new(big.Int).Mul(m.a, s)
+		rlo.Sub(rlo, B3m1)
+		rlo = ceilDiv(rlo, n)
+		rhi := new(big.Int).Mul(m.b, s)
+		rhi.Sub(rhi, B2)
+		rhi.Div(rhi, n)
+		for r := rlo; r.Cmp(rhi) <= 0; r.Add(r, one) {
+			rn.Mul(r, n)
+			a := ceilDiv(new(big.Int).Add(B2, rn), s)
+			if a.Cmp(m.a) < 0 {
+				a.Set(m.a)
+			}
+			b := new(big.Int).Add(B3m1, rn)
+			b.Div(b, s)
+			if b.Cmp(m.b) > 0 {
+				b.Set(m.b)
+			}
+			if a.Cmp(b) <= 0 {
+				res = union(res, interval{a, b})
+			}
+		}
+	}
+	return res
+}
+
+// union adds iv to the disjoint intervals M, merging overlapping ones.
+func union(M []interval, iv interval) []interval {
+	res := M[:0]
+	for _, m := range M {
+		if m.b.Cmp(iv.a) < 0 || iv.b.Cmp(m.a) < 0 {
+			res = append(res, m)
+			continue
+		}
+		if m.a.Cmp(iv.a) < 0 {
+			iv.a = m.a
+		}
+		if m.b.Cmp(iv.b) > 0 {
+			iv.b = m.b
+		}
+	}
+	return append(res, iv)
+}
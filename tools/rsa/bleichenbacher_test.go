@@ -0,0 +1,199 @@
+package rsa
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestBleichenbacher(t *testing.T) {
+	for _, tc := range []struct {
+		bits     int
+		name     string
+		conforms Conformance
+	}{
+		{256, "prefix", ConformsPrefix},
+		{512, "prefix", ConformsPrefix},
+		// the full padding check needs about 15 times more queries
+		{256, "padding", ConformsPadding},
+	} {
+		if testing.Short() && tc.name != "prefix" {
+			continue
+		}
+		t.Run(fmt.Sprintf("%d/%s", tc.bits, tc.name), func(t *testing.T) {
+			testBleichenbacher(t, tc.bits, tc.conforms)
+		})
+	}
+}
+
+func testBleichenbacher(t *testing.T, bits int, conforms Conformance) {
+	priv, err := GenerateKey(nil, bits, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("kick it, CC")
+	ct, err := EncryptPKCS1v15(nil, &priv.PublicKey, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oracle := NewPaddingOracle(priv, conforms)
+	calls := 0
+	res, err := Bleichenbacher(context.Background(), &priv.PublicKey, ct, func(ct []byte) bool {
+		calls++
+		return oracle(ct)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Queries != calls {
+		t.Errorf("Queries = %d; the oracle was called %d times", res.Queries, calls)
+	}
+	if res.Blinding != 1 {
+		t.Errorf("a conforming ciphertext took %d blinding queries", res.Blinding)
+	}
+	pt, err := DecodePKCS1v15Encryption(res.Plaintext)
+	if err != nil || !bytes.Equal(pt, msg) {
+		t.Fatalf("recovered %x", res.Plaintext)
+	}
+	t.Logf("%d queries, %d iterations", res.Queries, res.Iterations)
+}
+
+func TestConformance(t *testing.T) {
+	em, err := EncodePKCS1v15Encryption(nil, 32, []byte("kick"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	noSep := append([]byte(nil), em...)
+	noSep[27] = 0xff
+	shortPS := append([]byte(nil), em...)
+	shortPS[5] = 0
+	for _, tc := range []struct {
+		name           string
+		em             []byte
+		prefix, padded bool
+		length         [2]bool // ConformsLength(4) and (5)
+	}{
+		{"valid", em, true, true, [2]bool{true, false}},
+		{"no separator", noSep, true, false, [2]bool{false, false}},
+		{"short padding", shortPS, true, false, [2]bool{false, false}},
+		{"block type 1", append([]byte{0, 1}, em[2:]...), false, false, [2]bool{false, false}},
+	} {
+		if got := ConformsPrefix(tc.em); got != tc.prefix {
+			t.Errorf("%s: ConformsPrefix = %v", tc.name, got)
+		}
+		if got := ConformsPadding(tc.em); got != tc.padded {
+			t.Errorf("%s: ConformsPadding = %v", tc.name, got)
+		}
+		for i, n := range []int{4, 5} {
+			if got := ConformsLength(n)(tc.em); got != tc.length[i] {
+				t.Errorf("%s: ConformsLength(%d) = %v", tc.name, n, got)
+			}
+		}
+	}
+}
+
+func TestBleichenbacherBlinding(t *testing.T) {
+	priv, err := GenerateKey(nil, 256, 65537)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a random block, which is not PKCS conforming
+	m, err := rand.Int(rand.Reader, new(big.Int).Lsh(one, 248))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct := priv.EncryptInt(m).FillBytes(make([]byte, priv.Size()))
+	res, err := Bleichenbacher(context.Background(), &priv.PublicKey, ct, NewPaddingOracle(priv, ConformsPrefix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := new(big.Int).SetBytes(res.Plaintext); got.Cmp(m) != 0 {
+		t.Errorf("recovered %v; want %v", got, m)
+	}
+	if res.Blinding < 2 {
+		t.Errorf("Blinding = %d; want more than one query", res.Blinding)
+	}
+}
+
+func TestBleichenbacherCancel(t *testing.T) {
+	priv, err := GenerateKey(nil, 512, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct, err := EncryptPKCS1v15(nil, &priv.PublicKey, []byte("cancel me"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	oracle := NewPaddingOracle(priv, ConformsPrefix)
+	calls := 0
+	res, err := Bleichenbacher(ctx, &priv.PublicKey, ct, func(ct []byte) bool {
+		calls++
+		if calls == 100 {
+			cancel()
+		}
+		return oracle(ct)
+	})
+	if err != context.Canceled {
+		t.Fatalf("Bleichenbacher after cancel = %v; want %v", err, context.Canceled)
+	}
+	if res == nil || res.Queries != 100 || res.Plaintext != nil {
+		t.Errorf("result after cancel = %+v; want 100 queries and no plaintext", res)
+	}
+}
+
+func TestUnion(t *testing.T) {
+	iv := func(a, b int64) interval { return interval{big.NewInt(a), big.NewInt(b)} }
+	var M []interval
+	for _, x := range []interval{iv(1, 3), iv(10, 12), iv(2, 5), iv(20, 30), iv(4, 11)} {
+		M = union(M, x)
+	}
+	got := ""
+	for _, m := range M {
+		got += fmt.Sprintf("[%v, %v]", m.a, m.b)
+	}
+	if got != "[20, 30][1, 12]" {
+		t.Errorf("union = %s; want [20, 30][1, 12]", got)
+	}
+}
+
+func BenchmarkBleichenbacher(b *testing.B) {
+	for _, tc := range []struct {
+		bits     int
+		name     string
+		conforms Conformance
+	}{
+		{256, "prefix", ConformsPrefix},
+		{768, "prefix", ConformsPrefix},
+		{1024, "prefix", ConformsPrefix},
+		{256, "padding", ConformsPadding},
+	} {
+		b.Run(fmt.Sprintf("%d/%s", tc.bits, tc.name), func(b *testing.B) {
+			priv, err := GenerateKey(nil, tc.bits, 65537)
+			if err != nil {
+				b.Fatal(err)
+			}
+			oracle := NewPaddingOracle(priv, tc.conforms)
+			queries := 0
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				ct, err := EncryptPKCS1v15(nil, &priv.PublicKey, []byte("kick it, CC"))
+				if err != nil {
+					b.Fatal(err)
+				}
+				b.StartTimer()
+				res, err := Bleichenbacher(context.Background(), &priv.PublicKey, ct, oracle)
+				if err != nil {
+					b.Fatal(err)
+				}
+				queries += res.Queries
+			}
+			b.ReportMetric(float64(queries)/float64(b.N), "queries/op")
+		})
+	}
+}
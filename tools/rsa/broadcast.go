@@ -0,0 +1,51 @@
+package rsa
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ysmolsky/cryptopals/tools/dlog"
+)
+
+// ErrBroadcast is returned by Broadcast when the ciphertexts do not combine
+// to an exact e-th power.
+var ErrBroadcast = errors.New("rsa: ciphertexts are not a broadcast of one message")
+
+// Broadcast recovers a message sent unpadded to several recipients with the
+// same small public exponent e, Håstad's broadcast attack. It needs at least
+// e pairs of keys and ciphertexts: the Chinese Remainder Theorem gives m^e
+// modulo the product of the moduli, which exceeds m^e, so the integer e-th
+// root is m. The message is returned as Size bytes of the smallest modulus.
+func Broadcast(pubs []*PublicKey, cts [][]byte) ([]byte, error) {
+	if len(pubs) == 0 || len(pubs) != len(cts) {
+		return nil, ErrBroadcast
+	}
+	e := pubs[0].E
+	if !e.IsInt64() || e.Int64() < 2 || int64(len(pubs)) < e.Int64() {
+		return nil, ErrBroadcast
+	}
+	smallest := pubs[0]
+	cs := make([]dlog.Congruence, len(pubs))
+	for i, pub := range pubs {
+		if pub.E.Cmp(e) != 0 {
+			return nil, ErrBroadcast
+		}
+		if pub.N.Cmp(smallest.N) < 0 {
+			smallest = pub
+		}
+		c := new(big.Int).SetBytes(cts[i])
+		if c.Cmp(pub.N) >= 0 {
+			return nil, ErrMessageTooLong
+		}
+		cs[i] = dlog.Congruence{A: c, M: pub.N}
+	}
+	x, err := dlog.CRT(cs)
+	if err != nil {
+		return nil, ErrBroadcast
+	}
+	m, exact := Root(x.A, int(e.Int64()))
+	if !exact || m.Cmp(smallest.N) >= 0 {
+		return nil, ErrBroadcast
+	}
+	return m.FillBytes(make([]byte, smallest.Size())), nil
+}
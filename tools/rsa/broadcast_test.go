@@ -0,0 +1,65 @@
+package rsa
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBroadcast(t *testing.T) {
+	msg := []byte("equal to 20 bytes123")
+	for _, tc := range []struct {
+		e     int64
+		count int
+	}{{3, 3}, {3, 5}, {5, 5}, {7, 9}, {17, 17}} {
+		pubs := make([]*PublicKey, tc.count)
+		cts := make([][]byte, tc.count)
+		for i := range pubs {
+			priv, err := GenerateKey(nil, 256+64*(i%3), tc.e)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pubs[i] = &priv.PublicKey
+			if cts[i], err = pubs[i].Encrypt(msg); err != nil {
+				t.Fatal(err)
+			}
+		}
+		got, err := Broadcast(pubs, cts)
+		if err != nil {
+			t.Fatalf("e = %d, %d keys: %v", tc.e, tc.count, err)
+		}
+		if !bytes.Equal(bytes.TrimLeft(got, "\x00"), msg) || len(got) != 32 {
+			t.Errorf("e = %d, %d keys: Broadcast = %x", tc.e, tc.count, got)
+		}
+	}
+}
+
+func TestBroadcastErrors(t *testing.T) {
+	var pubs []*PublicKey
+	var cts [][]byte
+	for _, msg := range []string{"hi", "hi", "ho"} {
+		priv, err := GenerateKey(nil, 256, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ct, err := priv.Encrypt([]byte(msg))
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubs = append(pubs, &priv.PublicKey)
+		cts = append(cts, ct)
+	}
+	if _, err := Broadcast(pubs[:2], cts[:2]); err != ErrBroadcast {
+		t.Errorf("Broadcast with 2 keys for e = 3 = %v; want %v", err, ErrBroadcast)
+	}
+	if _, err := Broadcast(pubs, cts); err != ErrBroadcast {
+		t.Errorf("Broadcast of different messages = %v; want %v", err, ErrBroadcast)
+	}
+	priv, err := GenerateKey(nil, 256, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubs[2] = &priv.PublicKey
+	if _, err := Broadcast(pubs, cts); err != ErrBroadcast {
+		t.Errorf("Broadcast with mixed exponents = %v; want %v", err, ErrBroadcast)
+	}
+}
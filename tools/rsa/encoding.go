@@ -0,0 +1,229 @@
+package rsa
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"math/big"
+)
+
+var (
+	// ErrEncoding is returned for malformed DER or PEM key encodings.
+	ErrEncoding = errors.New("rsa: malformed key encoding")
+	// ErrAlgorithm is returned for PKCS #8 and SubjectPublicKeyInfo
+	// encodings of keys other than RSA.
+	ErrAlgorithm = errors.New("rsa: not an RSA key")
+)
+
+// oidRSA is rsaEncryption from PKCS #1.
+var oidRSA = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+
+// The ASN.1 structures of RFC 8017 and RFC 5208. Unlike crypto/x509 the
+// public exponent is an arbitrary INTEGER, so keys from Wiener's attack or
+// with e = 2^64+1 survive the round trip.
+type pkcs1PrivateKey struct {
+	Version int
+	N, E, D *big.Int
+	P, Q    *big.Int
+	Dp, Dq  *big.Int
+	Qinv    *big.Int
+	// multi-prime keys are not supported
+	AdditionalPrimes []asn1.RawValue `asn1:"optional"`
+}
+
+type pkcs1PublicKey struct {
+	N, E *big.Int
+}
+
+type pkcs8 struct {
+	Version    int
+	Algo       pkix.AlgorithmIdentifier
+	PrivateKey []byte
+	// attributes are ignored
+	Attributes []asn1.RawValue `asn1:"optional,tag:0"`
+}
+
+type spki struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// unmarshal parses der into v and rejects trailing data.
+func unmarshal(der []byte, v interface{}) error {
+	rest, err := asn1.Unmarshal(der, v)
+	if err != nil || len(rest) != 0 {
+		return ErrEncoding
+	}
+	return nil
+}
+
+// MarshalPKCS1PublicKey returns the PKCS #1 RSAPublicKey DER encoding of pub.
+func MarshalPKCS1PublicKey(pub *PublicKey) []byte {
+	der, _ := asn1.Marshal(pkcs1PublicKey{pub.N, pub.E})
+	return der
+}
+
+// ParsePKCS1PublicKey parses a PKCS #1 RSAPublicKey.
+func ParsePKCS1PublicKey(der []byte) (*PublicKey, error) {
+	var k pkcs1PublicKey
+	if err := unmarshal(der, &k); err != nil {
+		return nil, err
+	}
+	if k.N.Sign() <= 0 || k.E.Sign() <= 0 {
+		return nil, ErrEncoding
+	}
+	return &PublicKey{N: k.N, E: k.E}, nil
+}
+
+// MarshalPKCS1PrivateKey returns the PKCS #1 RSAPrivateKey DER encoding of
+// priv. Missing CRT values are computed.
+func MarshalPKCS1PrivateKey(priv *PrivateKey) []byte {
+	k := pkcs1PrivateKey{
+		N: priv.N, E: priv.E, D: priv.D, P: priv.P, Q: priv.Q,
+		Dp: priv.Dp, Dq: priv.Dq, Qinv: priv.Qinv,
+	}
+	if k.Dp == nil || k.Dq == nil || k.Qinv == nil {
+		k.Dp = new(big.Int).Mod(priv.D, new(big.Int).Sub(priv.P, one))
+		k.Dq = new(big.Int).Mod(priv.D, new(big.Int).Sub(priv.Q, one))
+		k.Qinv = new(big.Int).ModInverse(priv.Q, priv.P)
+	}
+	der, _ := asn1.Marshal(k)
+	return der
+}
+
+// ParsePKCS1PrivateKey parses a two-prime PKCS #1 RSAPrivateKey. It returns
+// ErrEncoding if N is not the product of P and Q, both above 1, or if the
+// CRT values do not match D, P and Q, since decryption and signing use them.
+func ParsePKCS1PrivateKey(der []byte) (*PrivateKey, error) {
+	var k pkcs1PrivateKey
+	if err := unmarshal(der, &k); err != nil {
+		return nil, err
+	}
+	if k.Version != 0 || len(k.AdditionalPrimes) != 0 {
+		return nil, ErrEncoding
+	}
+	for _, v := range []*big.Int{k.N, k.E, k.D, k.P, k.Q} {
+		if v.Sign() <= 0 {
+			return nil, ErrEncoding
+		}
+	}
+	if k.P.Cmp(one) <= 0 || k.Q.Cmp(one) <= 0 || new(big.Int).Mul(k.P, k.Q).Cmp(k.N) != 0 {
+		return nil, ErrEncoding
+	}
+	dp := new(big.Int).Mod(k.D, new(big.Int).Sub(k.P, one))
+	dq := new(big.Int).Mod(k.D, new(big.Int).Sub(k.Q, one))
+	qinv := new(big.Int).Mul(k.Qinv, k.Q)
+	if k.Dp.Cmp(dp) != 0 || k.Dq.Cmp(dq) != 0 || k.Qinv.Sign() <= 0 || k.Qinv.Cmp(k.P) >= 0 || qinv.Mod(qinv, k.P).Cmp(one) != 0 {
+		return nil, ErrEncoding
+	}
+	return &PrivateKey{
+		PublicKey: PublicKey{N: k.N, E: k.E},
+		D:         k.D,
+		P:         k.P,
+		Q:         k.Q,
+		Dp:        k.Dp,
+		Dq:        k.Dq,
+		Qinv:      k.Qinv,
+	}, nil
+}
+
+// rsaAlgorithm is the AlgorithmIdentifier of RSA keys, which has NULL
+// parameters.
+var rsaAlgorithm = pkix.AlgorithmIdentifier{Algorithm: oidRSA, Parameters: asn1.NullRawValue}
+
+// MarshalPKCS8PrivateKey returns the PKCS #8 PrivateKeyInfo DER encoding of
+// priv.
+func MarshalPKCS8PrivateKey(priv *PrivateKey) []byte {
+	der, _ := asn1.Marshal(pkcs8{Algo: rsaAlgorithm, PrivateKey: MarshalPKCS1PrivateKey(priv)})
+	return der
+}
+
+// ParsePKCS8PrivateKey parses an unencrypted PKCS #8 PrivateKeyInfo holding
+// an RSA key. It returns ErrAlgorithm for other kinds of keys.
+func ParsePKCS8PrivateKey(der []byte) (*PrivateKey, error) {
+	var k pkcs8
+	if err := unmarshal(der, &k); err != nil {
+		return nil, err
+	}
+	if !k.Algo.Algorithm.Equal(oidRSA) {
+		return nil, ErrAlgorithm
+	}
+	return ParsePKCS1PrivateKey(k.PrivateKey)
+}
+
+// MarshalPKIXPublicKey returns the SubjectPublicKeyInfo DER encoding of pub,
+// the format of "PUBLIC KEY" PEM blocks and of certificates.
+func MarshalPKIXPublicKey(pub *PublicKey) []byte {
+	key := MarshalPKCS1PublicKey(pub)
+	der, _ := asn1.Marshal(spki{
+		Algorithm: rsaAlgorithm,
+		PublicKey: asn1.BitString{Bytes: key, BitLength: 8 * len(key)},
+	})
+	return der
+}
+
+// ParsePKIXPublicKey parses a SubjectPublicKeyInfo holding an RSA key. It
+// returns ErrAlgorithm for other kinds of keys.
+func ParsePKIXPublicKey(der []byte) (*PublicKey, error) {
+	var k spki
+	if err := unmarshal(der, &k); err != nil {
+		return nil, err
+	}
+	if !k.Algorithm.Algorithm.Equal(oidRSA) {
+		return nil, ErrAlgorithm
+	}
+	return ParsePKCS1PublicKey(k.PublicKey.RightAlign())
+}
+
+// PEM block types.
+const (
+	pemPKCS1Private = "RSA PRIVATE KEY"
+	pemPKCS1Public  = "RSA PUBLIC KEY"
+	pemPKCS8        = "PRIVATE KEY"
+	pemPKIX         = "PUBLIC KEY"
+)
+
+// EncodePrivateKeyPEM returns priv as a PKCS #1 "RSA PRIVATE KEY" PEM block,
+// the traditional OpenSSL format.
+func EncodePrivateKeyPEM(priv *PrivateKey) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: pemPKCS1Private, Bytes: MarshalPKCS1PrivateKey(priv)})
+}
+
+// EncodePublicKeyPEM returns pub as a SubjectPublicKeyInfo "PUBLIC KEY" PEM
+// block.
+func EncodePublicKeyPEM(pub *PublicKey) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: pemPKIX, Bytes: MarshalPKIXPublicKey(pub)})
+}
+
+// ParsePrivateKeyPEM parses the first PEM block of data, which is either a
+// PKCS #1 "RSA PRIVATE KEY" or a PKCS #8 "PRIVATE KEY".
+func ParsePrivateKeyPEM(data []byte) (*PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrEncoding
+	}
+	switch block.Type {
+	case pemPKCS1Private:
+		return ParsePKCS1PrivateKey(block.Bytes)
+	case pemPKCS8:
+		return ParsePKCS8PrivateKey(block.Bytes)
+	}
+	return nil, ErrEncoding
+}
+
+// ParsePublicKeyPEM parses the first PEM block of data, which is either a
+// SubjectPublicKeyInfo "PUBLIC KEY" or a PKCS #1 "RSA PUBLIC KEY".
+func ParsePublicKeyPEM(data []byte) (*PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrEncoding
+	}
+	switch block.Type {
+	case pemPKIX:
+		return ParsePKIXPublicKey(block.Bytes)
+	case pemPKCS1Public:
+		return ParsePKCS1PublicKey(block.Bytes)
+	}
+	return nil, ErrEncoding
+}
@@ -0,0 +1,187 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	stdrsa "crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"testing"
+)
+
+func sameKey(a, b *PrivateKey) bool {
+	return a.N.Cmp(b.N) == 0 && a.E.Cmp(b.E) == 0 && a.D.Cmp(b.D) == 0 &&
+		a.P.Cmp(b.P) == 0 && a.Q.Cmp(b.Q) == 0
+}
+
+func pemBlock(typ string, der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der})
+}
+
+func TestEncodingFromStdlib(t *testing.T) {
+	std, err := stdrsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &PrivateKey{
+		PublicKey: PublicKey{N: std.N, E: big.NewInt(int64(std.E))},
+		D:         std.D,
+		P:         std.Primes[0],
+		Q:         std.Primes[1],
+	}
+
+	priv, err := ParsePKCS1PrivateKey(x509.MarshalPKCS1PrivateKey(std))
+	if err != nil || !sameKey(priv, want) {
+		t.Errorf("ParsePKCS1PrivateKey = %v, %v", priv, err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(std)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if priv, err = ParsePKCS8PrivateKey(der); err != nil || !sameKey(priv, want) {
+		t.Errorf("ParsePKCS8PrivateKey = %v, %v", priv, err)
+	}
+	pub, err := ParsePKCS1PublicKey(x509.MarshalPKCS1PublicKey(&std.PublicKey))
+	if err != nil || pub.N.Cmp(want.N) != 0 || pub.E.Cmp(want.E) != 0 {
+		t.Errorf("ParsePKCS1PublicKey = %v, %v", pub, err)
+	}
+	if der, err = x509.MarshalPKIXPublicKey(&std.PublicKey); err != nil {
+		t.Fatal(err)
+	}
+	if pub, err = ParsePKIXPublicKey(der); err != nil || pub.N.Cmp(want.N) != 0 || pub.E.Cmp(want.E) != 0 {
+		t.Errorf("ParsePKIXPublicKey = %v, %v", pub, err)
+	}
+}
+
+func TestEncodingToStdlib(t *testing.T) {
+	for _, e := range []int64{3, 65537} {
+		priv, err := GenerateKey(nil, 1024, e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check := func(name string, std *stdrsa.PrivateKey, err error) {
+			t.Helper()
+			if err != nil {
+				t.Fatalf("e = %d: %s: %v", e, name, err)
+			}
+			if std.N.Cmp(priv.N) != 0 || int64(std.E) != e || std.D.Cmp(priv.D) != 0 ||
+				std.Precomputed.Qinv.Cmp(priv.Qinv) != 0 {
+				t.Errorf("e = %d: %s gave a different key", e, name)
+			}
+		}
+		std, err := x509.ParsePKCS1PrivateKey(MarshalPKCS1PrivateKey(priv))
+		check("x509.ParsePKCS1PrivateKey", std, err)
+		key, err := x509.ParsePKCS8PrivateKey(MarshalPKCS8PrivateKey(priv))
+		std, _ = key.(*stdrsa.PrivateKey)
+		check("x509.ParsePKCS8PrivateKey", std, err)
+		// the encodings are the same byte for byte
+		if der := x509.MarshalPKCS1PrivateKey(std); !bytes.Equal(der, MarshalPKCS1PrivateKey(priv)) {
+			t.Errorf("e = %d: PKCS #1 encoding differs from crypto/x509", e)
+		}
+		if der, _ := x509.MarshalPKCS8PrivateKey(std); !bytes.Equal(der, MarshalPKCS8PrivateKey(priv)) {
+			t.Errorf("e = %d: PKCS #8 encoding differs from crypto/x509", e)
+		}
+
+		stdPub, err := x509.ParsePKCS1PublicKey(MarshalPKCS1PublicKey(&priv.PublicKey))
+		if err != nil || !stdPub.Equal(&std.PublicKey) {
+			t.Errorf("e = %d: x509.ParsePKCS1PublicKey = %v, %v", e, stdPub, err)
+		}
+		key, err = x509.ParsePKIXPublicKey(MarshalPKIXPublicKey(&priv.PublicKey))
+		if err != nil || !std.PublicKey.Equal(key) {
+			t.Errorf("e = %d: x509.ParsePKIXPublicKey = %v, %v", e, key, err)
+		}
+		if der, _ := x509.MarshalPKIXPublicKey(&std.PublicKey); !bytes.Equal(der, MarshalPKIXPublicKey(&priv.PublicKey)) {
+			t.Errorf("e = %d: SubjectPublicKeyInfo differs from crypto/x509", e)
+		}
+	}
+}
+
+func TestEncodingPEM(t *testing.T) {
+	priv, err := GenerateKey(nil, 512, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// an exponent too large for crypto/x509
+	e := new(big.Int).Lsh(one, 64)
+	priv, err = NewPrivateKey(priv.P, priv.Q, e.Add(e, one))
+	if err != nil {
+		t.Skip("2^64+1 is not invertible for this key")
+	}
+	got, err := ParsePrivateKeyPEM(EncodePrivateKeyPEM(priv))
+	if err != nil || !sameKey(got, priv) || got.Dp.Cmp(priv.Dp) != 0 {
+		t.Errorf("PKCS #1 PEM round trip = %v, %v", got, err)
+	}
+	pkcs8 := pemBlock(pemPKCS8, MarshalPKCS8PrivateKey(priv))
+	if got, err = ParsePrivateKeyPEM(pkcs8); err != nil || !sameKey(got, priv) {
+		t.Errorf("PKCS #8 PEM round trip = %v, %v", got, err)
+	}
+	pub, err := ParsePublicKeyPEM(EncodePublicKeyPEM(&priv.PublicKey))
+	if err != nil || pub.N.Cmp(priv.N) != 0 || pub.E.Cmp(priv.E) != 0 {
+		t.Errorf("SubjectPublicKeyInfo PEM round trip = %v, %v", pub, err)
+	}
+	pkcs1 := pemBlock(pemPKCS1Public, MarshalPKCS1PublicKey(&priv.PublicKey))
+	if pub, err = ParsePublicKeyPEM(pkcs1); err != nil || pub.E.Cmp(priv.E) != 0 {
+		t.Errorf("PKCS #1 public key PEM round trip = %v, %v", pub, err)
+	}
+	// CRT values are filled in when missing
+	bare := &PrivateKey{PublicKey: priv.PublicKey, D: priv.D, P: priv.P, Q: priv.Q}
+	if !bytes.Equal(MarshalPKCS1PrivateKey(bare), MarshalPKCS1PrivateKey(priv)) {
+		t.Errorf("MarshalPKCS1PrivateKey without CRT values differs")
+	}
+}
+
+func TestEncodingErrors(t *testing.T) {
+	priv, err := GenerateKey(nil, 512, 65537)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der := MarshalPKCS1PrivateKey(priv)
+	if _, err := ParsePKCS1PrivateKey(append(der, 0)); err != ErrEncoding {
+		t.Errorf("trailing data: %v; want %v", err, ErrEncoding)
+	}
+	if _, err := ParsePKCS1PrivateKey(der[:len(der)-1]); err != ErrEncoding {
+		t.Errorf("truncated key: %v; want %v", err, ErrEncoding)
+	}
+	wrong := *priv
+	wrong.P = new(big.Int).Add(priv.P, big.NewInt(2))
+	if _, err := ParsePKCS1PrivateKey(MarshalPKCS1PrivateKey(&wrong)); err != ErrEncoding {
+		t.Errorf("N != P Q: %v; want %v", err, ErrEncoding)
+	}
+	for _, corrupt := range []func(k *PrivateKey){
+		func(k *PrivateKey) { k.Dp = new(big.Int).Add(priv.Dp, big.NewInt(1)) },
+		func(k *PrivateKey) { k.Dp = new(big.Int) },
+		func(k *PrivateKey) { k.Dq = new(big.Int).Neg(priv.Dq) },
+		func(k *PrivateKey) { k.Qinv = new(big.Int).Add(priv.Qinv, priv.P) },
+		func(k *PrivateKey) { k.Qinv = big.NewInt(1) },
+		func(k *PrivateKey) { k.P, k.Q = big.NewInt(1), priv.N },
+		func(k *PrivateKey) { k.P, k.Q = priv.N, big.NewInt(1) },
+	} {
+		bad := *priv
+		corrupt(&bad)
+		if _, err := ParsePKCS1PrivateKey(MarshalPKCS1PrivateKey(&bad)); err != ErrEncoding {
+			t.Errorf("malformed key P = %v, Q = %v, Dp = %v, Dq = %v, Qinv = %v: %v; want %v", bad.P, bad.Q, bad.Dp, bad.Dq, bad.Qinv, err, ErrEncoding)
+		}
+	}
+	if _, err := ParsePrivateKeyPEM(EncodePublicKeyPEM(&priv.PublicKey)); err != ErrEncoding {
+		t.Errorf("ParsePrivateKeyPEM of a public key: %v; want %v", err, ErrEncoding)
+	}
+	if _, err := ParsePublicKeyPEM([]byte("not PEM")); err != ErrEncoding {
+		t.Errorf("ParsePublicKeyPEM of garbage: %v; want %v", err, ErrEncoding)
+	}
+
+	ec, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spki, _ := x509.MarshalPKIXPublicKey(&ec.PublicKey)
+	if _, err := ParsePKIXPublicKey(spki); err != ErrAlgorithm {
+		t.Errorf("ParsePKIXPublicKey of an ECDSA key: %v; want %v", err, ErrAlgorithm)
+	}
+	pkcs8, _ := x509.MarshalPKCS8PrivateKey(ec)
+	if _, err := ParsePKCS8PrivateKey(pkcs8); err != ErrAlgorithm {
+		t.Errorf("ParsePKCS8PrivateKey of an ECDSA key: %v; want %v", err, ErrAlgorithm)
+	}
+}
@@ -0,0 +1,111 @@
+package rsa
+
+import (
+	"crypto"
+	"errors"
+	"math/big"
+)
+
+// ErrOracle is returned by Manger when the oracle answers do not fit the
+// attack, for example because it does not leak the leading byte at all.
+var ErrOracle = errors.New("rsa: oracle answers are inconsistent")
+
+// MangerOracle reports whether the RSA decryption of ct is smaller than
+// B = 2^(8(k-1)), which is whether the first byte of the block is zero.
+type MangerOracle func(ct []byte) bool
+
+// NewMangerOracle returns the oracle leaked by DecryptOAEPLeaky: any error
+// other than ErrOAEPLeadingByte means that the leading byte was zero.
+func NewMangerOracle(priv *PrivateKey, hash crypto.Hash, label []byte) MangerOracle {
+	return func(ct []byte) bool {
+		_, err := DecryptOAEPLeaky(priv, hash, ct, label)
+		return err != ErrOAEPLeadingByte
+	}
+}
+
+// Manger recovers the RSA plaintext block of ct with Manger's chosen
+// ciphertext attack on OAEP ("A Chosen Ciphertext Attack on RSA Optimal
+// Asymmetric Encryption Padding", CRYPTO 2001). ct must decrypt to a block
+// starting with a zero byte, as every valid OAEP ciphertext does. It takes
+// about log2(N) oracle queries and returns the block as Size bytes, ready for
+// DecodeOAEP.
+func Manger(pub *PublicKey, ct []byte, oracle MangerOracle) ([]byte, error) {
+	n := pub.N
+	k := pub.Size()
+	B := new(big.Int).Lsh(one, uint(8*(k-1)))
+	if new(big.Int).Lsh(B, 1).Cmp(n) > 0 {
+		return nil, ErrKeySize
+	}
+	c0 := new(big.Int).SetBytes(ct)
+	// lt reports whether f * m < B
+	lt := func(f *big.Int) bool {
+		c := pub.EncryptInt(f)
+		c.Mul(c, c0)
+		c.Mod(c, n)
+		return oracle(c.FillBytes(make([]byte, k)))
+	}
+	// every loop below is bounded by the bit length of N with some slack,
+	// an oracle which does not leak runs into the limits
+	limit := 2*n.BitLen() + 64
+
+	// Step 1: double f1 until f1 * m >= B, then f1/2 * m is in [B/2, B)
+	f1 := big.NewInt(2)
+	for i := 0; lt(f1); i++ {
+		if i == limit {
+			return nil, ErrOracle
+		}
+		f1.Lsh(f1, 1)
+	}
+	half := new(big.Int).Rsh(f1, 1)
+
+	// Step 2: f2 * m is in [n/2, n+B) initially, increase it by f1/2 * m < B
+	// until it wraps around to [n, n+B)
+	f2 := new(big.Int).Add(n, B)
+	f2.Quo(f2, B)
+	f2.Mul(f2, half)
+	for i := 0; !lt(f2); i++ {
+		if i == limit {
+			return nil, ErrOracle
+		}
+		f2.Add(f2, half)
+	}
+
+	// Step 3: narrow m down to [mmin, mmax]
+	mmin := ceilDiv(n, f2)
+	mmax := new(big.Int).Add(n, B)
+	mmax.Quo(mmax, f2)
+	tmp, i := new(big.Int), new(big.Int)
+	for j := 0; mmin.Cmp(mmax) < 0; j++ {
+		if j == limit {
+			return nil, ErrOracle
+		}
+		// ftmp = 2B / (mmax - mmin), i = ftmp * mmin / n
+		tmp.Sub(mmax, mmin)
+		ftmp := new(big.Int).Lsh(B, 1)
+		ftmp.Quo(ftmp, tmp)
+		i.Mul(ftmp, mmin)
+		i.Quo(i, n)
+		// f3 = ceil(i n / mmin), f3 * m is in [i n, i n + 2B)
+		in := new(big.Int).Mul(i, n)
+		f3 := ceilDiv(in, mmin)
+		in.Add(in, B)
+		if lt(f3) {
+			mmax.Quo(in, f3)
+		} else {
+			mmin = ceilDiv(in, f3)
+		}
+	}
+	if mmin.Cmp(mmax) != 0 || pub.EncryptInt(mmin).Cmp(c0) != 0 {
+		return nil, ErrOracle
+	}
+	return mmin.FillBytes(make([]byte, k)), nil
+}
+
+// ceilDiv returns ceil(a / b) for positive b.
+func ceilDiv(a, b *big.Int) *big.Int {
+	q, r := new(big.Int).DivMod(a, b, new(big.Int))
+	if r.Sign() != 0 {
+		q.Add(q, one)
+	}
+	return q
+}
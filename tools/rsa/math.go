@@ -0,0 +1,58 @@
+package rsa
+
+import "math/big"
+
+// ExtendedGCD returns x, y and gcd(a, b) that satisfy
+// ax + by = gcd(a, b).
+func ExtendedGCD(a, b *big.Int) (x, y, gcd *big.Int) {
+	oldr, r := new(big.Int).Set(a), new(big.Int).Set(b)
+	olds, s := big.NewInt(1), big.NewInt(0)
+	oldt, t := big.NewInt(0), big.NewInt(1)
+	quot := new(big.Int)
+	for r.Sign() != 0 {
+		quot.Div(oldr, r)
+		oldr, r = r, oldr.Sub(oldr, new(big.Int).Mul(quot, r))
+		olds, s = s, olds.Sub(olds, new(big.Int).Mul(quot, s))
+		oldt, t = t, oldt.Sub(oldt, new(big.Int).Mul(quot, t))
+	}
+	return olds, oldt, oldr
+}
+
+// Invmod returns the inverse of a modulo m in the range [0, m). It returns
+// ErrNotInvertible if a and m are not coprime.
+func Invmod(a, m *big.Int) (*big.Int, error) {
+	x, _, g := ExtendedGCD(new(big.Int).Mod(a, m), m)
+	if g.Cmp(one) != 0 {
+		return nil, ErrNotInvertible
+	}
+	return x.Mod(x, m), nil
+}
+
+// Root returns the integer n-th root of x, the largest r with r^n <= x, and
+// reports whether the root is exact. x must not be negative.
+func Root(x *big.Int, n int) (r *big.Int, exact bool) {
+	if x.Sign() < 0 || n < 1 {
+		panic("rsa: Root of a negative number or with n < 1")
+	}
+	if x.Sign() == 0 || n == 1 {
+		return new(big.Int).Set(x), true
+	}
+	// Newton's iteration from a power of two above the root decreases
+	// monotonically to the floor of the root.
+	N := big.NewInt(int64(n))
+	N1 := big.NewInt(int64(n - 1))
+	r = new(big.Int).Lsh(one, uint(x.BitLen()/n+1))
+	next, pow := new(big.Int), new(big.Int)
+	for {
+		// next = ((n-1) r + x / r^(n-1)) / n
+		pow.Exp(r, N1, nil)
+		next.Quo(x, pow)
+		next.Add(next, pow.Mul(r, N1))
+		next.Quo(next, N)
+		if next.Cmp(r) >= 0 {
+			break
+		}
+		r.Set(next)
+	}
+	return r, pow.Exp(r, N, nil).Cmp(x) == 0
+}
@@ -0,0 +1,69 @@
+package rsa
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestExtendedGCD(t *testing.T) {
+	for _, tc := range [][2]int64{{240, 46}, {67, 12}, {17, 3120}, {12, 18}, {0, 5}, {5, 0}} {
+		a, b := big.NewInt(tc[0]), big.NewInt(tc[1])
+		x, y, g := ExtendedGCD(a, b)
+		if exp := new(big.Int).GCD(nil, nil, a, b); g.Cmp(exp) != 0 {
+			t.Errorf("ExtendedGCD(%d, %d): gcd = %v; want %v", tc[0], tc[1], g, exp)
+		}
+		sum := new(big.Int).Mul(a, x)
+		sum.Add(sum, new(big.Int).Mul(b, y))
+		if sum.Cmp(g) != 0 {
+			t.Errorf("ExtendedGCD(%d, %d): %d*%v + %d*%v != %v", tc[0], tc[1], tc[0], x, tc[1], y, g)
+		}
+	}
+}
+
+func TestInvmod(t *testing.T) {
+	if inv, err := Invmod(big.NewInt(17), big.NewInt(3120)); err != nil || inv.Int64() != 2753 {
+		t.Errorf("Invmod(17, 3120) = %v, %v; want 2753", inv, err)
+	}
+	if _, err := Invmod(big.NewInt(6), big.NewInt(3120)); err != ErrNotInvertible {
+		t.Errorf("Invmod(6, 3120) = %v; want %v", err, ErrNotInvertible)
+	}
+	m, _ := rand.Prime(rand.Reader, 256)
+	for i := 0; i < 50; i++ {
+		a, _ := rand.Int(rand.Reader, new(big.Int).Lsh(m, 2))
+		if new(big.Int).Mod(a, m).Sign() == 0 {
+			continue
+		}
+		inv, err := Invmod(a, m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exp := new(big.Int).ModInverse(a, m); inv.Cmp(exp) != 0 {
+			t.Fatalf("Invmod(%v, %v) = %v; want %v", a, m, inv, exp)
+		}
+	}
+}
+
+func TestRoot(t *testing.T) {
+	for n := 1; n <= 17; n++ {
+		for i := 0; i < 20; i++ {
+			r, _ := rand.Int(rand.Reader, new(big.Int).Lsh(one, uint(8*i+1)))
+			x := new(big.Int).Exp(r, big.NewInt(int64(n)), nil)
+			if got, exact := Root(x, n); got.Cmp(r) != 0 || !exact {
+				t.Fatalf("Root(%v^%d) = %v, %v", r, n, got, exact)
+			}
+			if r.Cmp(one) <= 0 || n == 1 {
+				continue
+			}
+			// one below a perfect power rounds down
+			x.Sub(x, one)
+			got, exact := Root(x, n)
+			if exact || got.Cmp(new(big.Int).Sub(r, one)) != 0 {
+				t.Fatalf("Root(%v^%d - 1) = %v, %v; want %v", r, n, got, exact, new(big.Int).Sub(r, one))
+			}
+		}
+	}
+	if r, exact := Root(big.NewInt(0), 3); r.Sign() != 0 || !exact {
+		t.Errorf("Root(0, 3) = %v, %v", r, exact)
+	}
+}
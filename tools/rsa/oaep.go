@@ -0,0 +1,186 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+var (
+	// ErrOAEPLeadingByte is returned by the leaky OAEP decoder when the first
+	// byte of the block is not zero.
+	ErrOAEPLeadingByte = errors.New("rsa: oaep: leading byte is not zero")
+	// ErrOAEPLabel is returned by the leaky OAEP decoder when the label hash
+	// does not match.
+	ErrOAEPLabel = errors.New("rsa: oaep: label hash mismatch")
+	// ErrOAEPSeparator is returned by the leaky OAEP decoder when the 01 byte
+	// which ends the padding is missing.
+	ErrOAEPSeparator = errors.New("rsa: oaep: missing separator")
+)
+
+// MGF1 returns length bytes of the mask generation function from RFC 8017,
+// appendix B.2.1:
+// hash(seed || 00000000) || hash(seed || 00000001) || ...
+func MGF1(hash crypto.Hash, seed []byte, length int) []byte {
+	h := hash.New()
+	out := make([]byte, 0, length+h.Size())
+	var ctr [4]byte
+	for i := uint32(0); len(out) < length; i++ {
+		binary.BigEndian.PutUint32(ctr[:], i)
+		h.Reset()
+		h.Write(seed)
+		h.Write(ctr[:])
+		out = h.Sum(out)
+	}
+	return out[:length]
+}
+
+// mgf1XOR xors out with the MGF1 mask of seed.
+func mgf1XOR(out []byte, hash crypto.Hash, seed []byte) {
+	for i, b := range MGF1(hash, seed, len(out)) {
+		out[i] ^= b
+	}
+}
+
+func hashLabel(hash crypto.Hash, label []byte) []byte {
+	h := hash.New()
+	h.Write(label)
+	return h.Sum(nil)
+}
+
+// EncodeOAEP returns the k byte block of RFC 8017, section 7.1.1:
+// 00 || maskedSeed || maskedDB
+// where DB = hash(label) || PS || 01 || msg. Both masks use MGF1 with hash.
+// rnd defaults to crypto/rand.Reader when nil.
+func EncodeOAEP(rnd io.Reader, k int, hash crypto.Hash, msg, label []byte) ([]byte, error) {
+	if !hash.Available() {
+		return nil, ErrUnsupportedHash
+	}
+	if rnd == nil {
+		rnd = rand.Reader
+	}
+	hLen := hash.Size()
+	if len(msg) > k-2*hLen-2 {
+		return nil, ErrMessageTooLong
+	}
+	em := make([]byte, k)
+	seed := em[1 : 1+hLen]
+	db := em[1+hLen:]
+	copy(db, hashLabel(hash, label))
+	db[len(db)-len(msg)-1] = 1
+	copy(db[len(db)-len(msg):], msg)
+	if _, err := io.ReadFull(rnd, seed); err != nil {
+		return nil, err
+	}
+	mgf1XOR(db, hash, seed)
+	mgf1XOR(seed, hash, db)
+	return em, nil
+}
+
+// unmaskOAEP removes both masks of em in place and returns the leading byte
+// and DB.
+func unmaskOAEP(em []byte, hash crypto.Hash) (y byte, db []byte) {
+	hLen := hash.Size()
+	seed := em[1 : 1+hLen]
+	db = em[1+hLen:]
+	mgf1XOR(seed, hash, db)
+	mgf1XOR(db, hash, seed)
+	return em[0], db
+}
+
+// DecodeOAEP checks the OAEP padding of em in constant time and returns the
+// message. Every kind of bad padding results in the same ErrDecryption, so
+// the caller learns nothing about which check failed. em is modified.
+func DecodeOAEP(em []byte, hash crypto.Hash, label []byte) ([]byte, error) {
+	if !hash.Available() {
+		return nil, ErrUnsupportedHash
+	}
+	hLen := hash.Size()
+	if len(em) < 2*hLen+2 {
+		return nil, ErrDecryption
+	}
+	y, db := unmaskOAEP(em, hash)
+	good := subtle.ConstantTimeByteEq(y, 0)
+	good &= subtle.ConstantTimeCompare(db[:hLen], hashLabel(hash, label))
+
+	// find the first 01 after the zero padding without branching on the
+	// content
+	rest := db[hLen:]
+	lookingForIndex, index, invalid := 1, 0, 0
+	for i := range rest {
+		equals0 := subtle.ConstantTimeByteEq(rest[i], 0)
+		equals1 := subtle.ConstantTimeByteEq(rest[i], 1)
+		index = subtle.ConstantTimeSelect(lookingForIndex&equals1, i, index)
+		lookingForIndex = subtle.ConstantTimeSelect(equals1, 0, lookingForIndex)
+		invalid = subtle.ConstantTimeSelect(lookingForIndex&^equals0, 1, invalid)
+	}
+	if good&^invalid&^lookingForIndex != 1 {
+		return nil, ErrDecryption
+	}
+	return rest[index+1:], nil
+}
+
+// DecodeOAEPLeaky decodes em like DecodeOAEP, but checks the padding step
+// by step and reports which check failed first: ErrOAEPLeadingByte,
+// ErrOAEPLabel or ErrOAEPSeparator. Telling the first error apart from the
+// others is all Manger's attack needs. em is modified.
+func DecodeOAEPLeaky(em []byte, hash crypto.Hash, label []byte) ([]byte, error) {
+	if !hash.Available() {
+		return nil, ErrUnsupportedHash
+	}
+	hLen := hash.Size()
+	if len(em) < 2*hLen+2 {
+		return nil, ErrDecryption
+	}
+	if em[0] != 0 {
+		return nil, ErrOAEPLeadingByte
+	}
+	_, db := unmaskOAEP(em, hash)
+	if !bytes.Equal(db[:hLen], hashLabel(hash, label)) {
+		return nil, ErrOAEPLabel
+	}
+	rest := bytes.TrimLeft(db[hLen:], "\x00")
+	if len(rest) == 0 || rest[0] != 1 {
+		return nil, ErrOAEPSeparator
+	}
+	return rest[1:], nil
+}
+
+// EncryptOAEP encrypts msg with RSA-OAEP.
+func EncryptOAEP(rnd io.Reader, pub *PublicKey, hash crypto.Hash, msg, label []byte) ([]byte, error) {
+	em, err := EncodeOAEP(rnd, pub.Size(), hash, msg, label)
+	if err != nil {
+		return nil, err
+	}
+	return pub.Encrypt(em)
+}
+
+// DecryptOAEP decrypts an RSA-OAEP ciphertext. All padding errors are
+// reported as ErrDecryption.
+func DecryptOAEP(priv *PrivateKey, hash crypto.Hash, ct, label []byte) ([]byte, error) {
+	if len(ct) != priv.Size() {
+		return nil, ErrDecryption
+	}
+	em, err := priv.Decrypt(ct)
+	if err != nil {
+		return nil, ErrDecryption
+	}
+	return DecodeOAEP(em, hash, label)
+}
+
+// DecryptOAEPLeaky decrypts an RSA-OAEP ciphertext with DecodeOAEPLeaky. It
+// is the broken implementation used to build a Manger oracle.
+func DecryptOAEPLeaky(priv *PrivateKey, hash crypto.Hash, ct, label []byte) ([]byte, error) {
+	if len(ct) != priv.Size() {
+		return nil, ErrDecryption
+	}
+	em, err := priv.Decrypt(ct)
+	if err != nil {
+		return nil, ErrDecryption
+	}
+	return DecodeOAEPLeaky(em, hash, label)
+}
@@ -0,0 +1,160 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	stdrsa "crypto/rsa"
+	"testing"
+)
+
+var oaepHashes = []crypto.Hash{crypto.SHA1, crypto.SHA256, crypto.SHA512}
+
+func TestMGF1(t *testing.T) {
+	// MGF1-SHA1("foo", 3) and ("bar", 50), the examples of the Wikipedia
+	// article on mask generation functions
+	if got := MGF1(crypto.SHA1, []byte("foo"), 3); !bytes.Equal(got, []byte{0x1a, 0xc9, 0x07}) {
+		t.Errorf("MGF1(foo, 3) = %x; want 1ac907", got)
+	}
+	got := MGF1(crypto.SHA1, []byte("bar"), 50)
+	exp := unhex("bc0c655e016bc2931d85a2e675181adcef7f581f76df2739da74faac41627be2f7f415c89e983fd0ce80ced9878641cb4876")
+	if !bytes.Equal(got, exp) {
+		t.Errorf("MGF1(bar, 50) = %x; want %x", got, exp)
+	}
+}
+
+func TestOAEPMatchesStdlib(t *testing.T) {
+	priv, err := GenerateKey(nil, 2048, 65537)
+	if err != nil {
+		t.Fatal(err)
+	}
+	std := toStd(t, priv)
+	for _, hash := range oaepHashes {
+		for _, label := range [][]byte{nil, []byte("label")} {
+			for _, l := range []int{0, 1, 32, priv.Size() - 2*hash.Size() - 2} {
+				msg := make([]byte, l)
+				rand.Read(msg)
+				ct, err := EncryptOAEP(nil, &priv.PublicKey, hash, msg, label)
+				if err != nil {
+					t.Fatal(err)
+				}
+				pt, err := stdrsa.DecryptOAEP(hash.New(), nil, std, ct, label)
+				if err != nil || !bytes.Equal(pt, msg) {
+					t.Errorf("%v, len %d: crypto/rsa failed to decrypt: %v", hash, l, err)
+				}
+				ct, err = stdrsa.EncryptOAEP(hash.New(), rand.Reader, &std.PublicKey, msg, label)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, dec := range []func(*PrivateKey, crypto.Hash, []byte, []byte) ([]byte, error){DecryptOAEP, DecryptOAEPLeaky} {
+					pt, err := dec(priv, hash, ct, label)
+					if err != nil || !bytes.Equal(pt, msg) {
+						t.Errorf("%v, len %d: decryption of a crypto/rsa ciphertext = %x, %v", hash, l, pt, err)
+					}
+				}
+				if _, err := DecryptOAEP(priv, hash, ct, []byte("other")); err != ErrDecryption {
+					t.Errorf("%v: DecryptOAEP with a wrong label = %v; want %v", hash, err, ErrDecryption)
+				}
+			}
+		}
+		long := make([]byte, priv.Size()-2*hash.Size()-1)
+		if _, err := EncryptOAEP(nil, &priv.PublicKey, hash, long, nil); err != ErrMessageTooLong {
+			t.Errorf("%v: EncryptOAEP of a long message = %v; want %v", hash, err, ErrMessageTooLong)
+		}
+	}
+}
+
+func TestOAEPErrors(t *testing.T) {
+	k := 128
+	hash := crypto.SHA256
+	good, err := EncodeOAEP(nil, k, hash, []byte("msg"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the masks are recomputed from the modified block, so only the
+	// leading byte and the seed can be changed in a controlled way
+	cases := []struct {
+		name  string
+		mod   func(em []byte)
+		label []byte
+		leaky error
+	}{
+		{"leading byte", func(em []byte) { em[0] = 1 }, nil, ErrOAEPLeadingByte},
+		{"label", func(em []byte) {}, []byte("x"), ErrOAEPLabel},
+		{"seed", func(em []byte) { em[1] ^= 1 }, nil, ErrOAEPLabel},
+	}
+	for _, tc := range cases {
+		em := append([]byte(nil), good...)
+		tc.mod(em)
+		if _, err := DecodeOAEP(append([]byte(nil), em...), hash, tc.label); err != ErrDecryption {
+			t.Errorf("%s: DecodeOAEP = %v; want %v", tc.name, err, ErrDecryption)
+		}
+		if _, err := DecodeOAEPLeaky(em, hash, tc.label); err != tc.leaky {
+			t.Errorf("%s: DecodeOAEPLeaky = %v; want %v", tc.name, err, tc.leaky)
+		}
+	}
+
+	// a block of zeros after the label hash has no 01 separator
+	em := make([]byte, k)
+	seed := em[1 : 1+hash.Size()]
+	db := em[1+hash.Size():]
+	copy(db, hashLabel(hash, nil))
+	mgf1XOR(db, hash, seed)
+	mgf1XOR(seed, hash, db)
+	if _, err := DecodeOAEP(append([]byte(nil), em...), hash, nil); err != ErrDecryption {
+		t.Errorf("no separator: DecodeOAEP = %v; want %v", err, ErrDecryption)
+	}
+	if _, err := DecodeOAEPLeaky(em, hash, nil); err != ErrOAEPSeparator {
+		t.Errorf("no separator: DecodeOAEPLeaky = %v; want %v", err, ErrOAEPSeparator)
+	}
+}
+
+func TestManger(t *testing.T) {
+	for _, bits := range []int{1024, 2048} {
+		if testing.Short() && bits > 1024 {
+			continue
+		}
+		priv, err := GenerateKey(nil, bits, 65537)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub := &priv.PublicKey
+		label := []byte("manger")
+		msg := []byte("OAEP only helps if the errors look alike")
+		ct, err := EncryptOAEP(nil, pub, crypto.SHA1, msg, label)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		leaky := NewMangerOracle(priv, crypto.SHA1, label)
+		queries := 0
+		em, err := Manger(pub, ct, func(ct []byte) bool {
+			queries++
+			return leaky(ct)
+		})
+		if err != nil {
+			t.Fatalf("%d bits: %v", bits, err)
+		}
+		pt, err := DecodeOAEP(em, crypto.SHA1, label)
+		if err != nil || !bytes.Equal(pt, msg) {
+			t.Fatalf("%d bits: Manger recovered %q, %v", bits, pt, err)
+		}
+		t.Logf("%d bits: %d oracle queries", bits, queries)
+		if queries > 2*bits+300 {
+			t.Errorf("%d bits: %d oracle queries", bits, queries)
+		}
+
+		if bits > 1024 {
+			continue
+		}
+		// the constant time decoder only tells valid from invalid
+		// ciphertexts, which is useless for the attack
+		sealed := func(ct []byte) bool {
+			_, err := DecryptOAEP(priv, crypto.SHA1, ct, label)
+			return err != nil
+		}
+		if _, err := Manger(pub, ct, sealed); err != ErrOracle {
+			t.Errorf("%d bits: Manger with the constant time decoder = %v; want %v", bits, err, ErrOracle)
+		}
+	}
+}
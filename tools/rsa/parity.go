@@ -0,0 +1,119 @@
+package rsa
+
+import "math/big"
+
+// ParityOracle reports whether the RSA decryption of ct is even.
+type ParityOracle func(ct []byte) bool
+
+// HalfOracle reports whether the RSA decryption of ct is larger than N/2,
+// which for a modulus of a whole number of bytes is whether its most
+// significant bit is set.
+type HalfOracle func(ct []byte) bool
+
+// NewParityOracle returns the parity oracle of challenge 46.
+func NewParityOracle(priv *PrivateKey) ParityOracle {
+	return func(ct []byte) bool {
+		return priv.DecryptInt(new(big.Int).SetBytes(ct)).Bit(0) == 0
+	}
+}
+
+// NewHalfOracle returns an oracle telling whether the plaintext is in the
+// upper half of [0, N).
+func NewHalfOracle(priv *PrivateKey) HalfOracle {
+	return func(ct []byte) bool {
+		m := priv.DecryptInt(new(big.Int).SetBytes(ct))
+		return m.Lsh(m, 1).Cmp(priv.N) > 0
+	}
+}
+
+// ParityAttack recovers an RSA plaintext bit by bit from an oracle leaking
+// either the parity or the upper half of decryptions. Exactly one of Parity
+// and Half has to be set.
+//
+// After i queries the plaintext is known to be in
+// [j N / 2^i, (j+1) N / 2^i)
+// for an integer j which gains one bit per query. The bounds are kept as the
+// exact fraction j / 2^i instead of a rounded midpoint, so the last bits of
+// the plaintext are not lost to rounding.
+type ParityAttack struct {
+	Pub    *PublicKey
+	Parity ParityOracle
+	Half   HalfOracle
+	// Progress, if set, is called after every query with the number of
+	// queries so far and the current bounds lo <= m <= hi. The bounds must
+	// not be modified.
+	Progress func(step int, lo, hi *big.Int)
+}
+
+// Run decrypts ct with at most N.BitLen() oracle queries and returns the
+// plaintext as Size bytes. It returns ErrOracle if the result does not
+// encrypt to ct.
+func (a *ParityAttack) Run(ct []byte) ([]byte, error) {
+	pub := a.Pub
+	n := pub.N
+	c := new(big.Int).SetBytes(ct)
+	if c.Cmp(n) >= 0 {
+		return nil, ErrMessageTooLong
+	}
+	if (a.Parity == nil) == (a.Half == nil) {
+		panic("rsa: ParityAttack needs exactly one oracle")
+	}
+	// multiplying the ciphertext by 2^e doubles the plaintext
+	double := pub.EncryptInt(big.NewInt(2))
+	query := new(big.Int).Set(c)
+	if a.Parity != nil {
+		query.Mul(query, double)
+		query.Mod(query, n)
+	}
+
+	j, den := new(big.Int), big.NewInt(1)
+	lo, hi := new(big.Int), new(big.Int).Sub(n, one)
+	buf := make([]byte, pub.Size())
+	for step := 1; lo.Cmp(hi) < 0; step++ {
+		// the parity of 2^i m mod N and whether 2^(i-1) m mod N is larger
+		// than N/2 are both the lowest bit of j = floor(2^i m / N)
+		var odd bool
+		if a.Parity != nil {
+			odd = !a.Parity(query.FillBytes(buf))
+		} else {
+			odd = a.Half(query.FillBytes(buf))
+		}
+		j.Lsh(j, 1)
+		if odd {
+			j.Add(j, one)
+		}
+		den.Lsh(den, 1)
+		query.Mul(query, double)
+		query.Mod(query, n)
+
+		// lo = ceil(j N / 2^i), hi = ceil((j+1) N / 2^i) - 1
+		lo = ceilDiv(new(big.Int).Mul(j, n), den)
+		hi = new(big.Int).Add(j, one)
+		hi = ceilDiv(hi.Mul(hi, n), den)
+		hi.Sub(hi, one)
+		if lo.Cmp(hi) > 0 {
+			return nil, ErrOracle
+		}
+		if a.Progress != nil {
+			a.Progress(step, lo, hi)
+		}
+	}
+	if pub.EncryptInt(lo).Cmp(c) != 0 {
+		return nil, ErrOracle
+	}
+	return lo.FillBytes(make([]byte, pub.Size())), nil
+}
+
+// ParityOracleDecrypt decrypts ct with a parity oracle, the attack of
+// challenge 46.
+func ParityOracleDecrypt(pub *PublicKey, ct []byte, oracle ParityOracle) ([]byte, error) {
+	a := &ParityAttack{Pub: pub, Parity: oracle}
+	return a.Run(ct)
+}
+
+// HalfOracleDecrypt decrypts ct with an oracle which tells whether the
+// plaintext is larger than N/2.
+func HalfOracleDecrypt(pub *PublicKey, ct []byte, oracle HalfOracle) ([]byte, error) {
+	a := &ParityAttack{Pub: pub, Half: oracle}
+	return a.Run(ct)
+}
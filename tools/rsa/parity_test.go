@@ -0,0 +1,107 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestParityOracleDecrypt(t *testing.T) {
+	priv, err := GenerateKey(nil, 768, 65537)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := &priv.PublicKey
+	msgs := [][]byte{
+		[]byte("That's why I found you don't play around with the Funky Cold Medina"),
+		{0},
+		{1},
+		{0xff},
+		new(big.Int).Sub(priv.N, one).Bytes(),
+		new(big.Int).Rsh(priv.N, 1).Bytes(),
+	}
+	for i := 0; i < 4; i++ {
+		m, _ := rand.Int(rand.Reader, priv.N)
+		msgs = append(msgs, m.Bytes())
+	}
+	parity, half := NewParityOracle(priv), NewHalfOracle(priv)
+	for _, msg := range msgs {
+		ct, err := pub.Encrypt(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		exp := make([]byte, pub.Size())
+		copy(exp[len(exp)-len(msg):], msg)
+
+		queries := 0
+		got, err := ParityOracleDecrypt(pub, ct, func(ct []byte) bool {
+			queries++
+			return parity(ct)
+		})
+		if err != nil || !bytes.Equal(got, exp) {
+			t.Fatalf("ParityOracleDecrypt(%.16q) = %.16q, %v", msg, got, err)
+		}
+		if queries > priv.N.BitLen() {
+			t.Errorf("ParityOracleDecrypt(%.16q) took %d queries", msg, queries)
+		}
+		got, err = HalfOracleDecrypt(pub, ct, half)
+		if err != nil || !bytes.Equal(got, exp) {
+			t.Fatalf("HalfOracleDecrypt(%.16q) = %.16q, %v", msg, got, err)
+		}
+	}
+}
+
+func TestParityAttackProgress(t *testing.T) {
+	priv, err := GenerateKey(nil, 512, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := new(big.Int).SetBytes([]byte("streamed bit by bit"))
+	ct := priv.EncryptInt(m).FillBytes(make([]byte, priv.Size()))
+	last := 0
+	prevLo, prevHi := big.NewInt(0), new(big.Int).Set(priv.N)
+	a := &ParityAttack{
+		Pub:    &priv.PublicKey,
+		Parity: NewParityOracle(priv),
+		Progress: func(step int, lo, hi *big.Int) {
+			if step != last+1 {
+				t.Fatalf("Progress step %d after %d", step, last)
+			}
+			last = step
+			// the bounds only shrink and always contain m
+			if lo.Cmp(prevLo) < 0 || hi.Cmp(prevHi) > 0 || lo.Cmp(m) > 0 || hi.Cmp(m) < 0 {
+				t.Fatalf("step %d: bounds [%v, %v] do not narrow down to %v", step, lo, hi, m)
+			}
+			prevLo.Set(lo)
+			prevHi.Set(hi)
+		},
+	}
+	got, err := a.Run(ct)
+	if err != nil || new(big.Int).SetBytes(got).Cmp(m) != 0 {
+		t.Fatalf("Run = %x, %v", got, err)
+	}
+	if last == 0 || prevLo.Cmp(prevHi) != 0 {
+		t.Errorf("Progress was not called down to a single value")
+	}
+}
+
+func TestParityAttackLyingOracle(t *testing.T) {
+	priv, err := GenerateKey(nil, 256, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct, err := priv.Encrypt([]byte("lie"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parity := NewParityOracle(priv)
+	n := 0
+	_, err = ParityOracleDecrypt(&priv.PublicKey, ct, func(ct []byte) bool {
+		n++
+		return parity(ct) != (n == 100)
+	})
+	if err != ErrOracle {
+		t.Errorf("ParityOracleDecrypt with a lying oracle = %v; want %v", err, ErrOracle)
+	}
+}
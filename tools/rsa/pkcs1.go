@@ -0,0 +1,238 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"math/big"
+)
+
+var (
+	// ErrDecryption is returned when a PKCS#1 v1.5 encryption block is
+	// malformed.
+	ErrDecryption = errors.New("rsa: decryption error")
+	// ErrVerification is returned when a signature does not verify.
+	ErrVerification = errors.New("rsa: verification error")
+	// ErrUnsupportedHash is returned for hashes without a DigestInfo prefix.
+	ErrUnsupportedHash = errors.New("rsa: unsupported hash function")
+	// ErrForgery is returned by ForgePKCS1v15 when the modulus is too short
+	// for the forged block to survive the cube root.
+	ErrForgery = errors.New("rsa: modulus too short for a forgery")
+)
+
+// digestInfoPrefixes are the DER encodings of the DigestInfo structure up to
+// the hash value, as listed in RFC 8017, section 9.2, note 1.
+var digestInfoPrefixes = map[crypto.Hash][]byte{
+	crypto.MD5:    {0x30, 0x20, 0x30, 0x0c, 0x06, 0x08, 0x2a, 0x86, 0x48, 0x86, 0xf7, 0x0d, 0x02, 0x05, 0x05, 0x00, 0x04, 0x10},
+	crypto.SHA1:   {0x30, 0x21, 0x30, 0x09, 0x06, 0x05, 0x2b, 0x0e, 0x03, 0x02, 0x1a, 0x05, 0x00, 0x04, 0x14},
+	crypto.SHA256: {0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20},
+	crypto.SHA512: {0x30, 0x51, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x03, 0x05, 0x00, 0x04, 0x40},
+}
+
+// DigestInfoPrefix returns the DER prefix which precedes a hash value of
+// type hash in a PKCS#1 v1.5 signature.
+func DigestInfoPrefix(hash crypto.Hash) ([]byte, error) {
+	prefix, ok := digestInfoPrefixes[hash]
+	if !ok {
+		return nil, ErrUnsupportedHash
+	}
+	return append([]byte(nil), prefix...), nil
+}
+
+// EncodePKCS1v15Encryption returns the k byte block
+// 00 02 PS 00 msg
+// where PS are at least 8 random non-zero bytes. rnd defaults to
+// crypto/rand.Reader when nil.
+func EncodePKCS1v15Encryption(rnd io.Reader, k int, msg []byte) ([]byte, error) {
+	if rnd == nil {
+		rnd = rand.Reader
+	}
+	if len(msg) > k-11 {
+		return nil, ErrMessageTooLong
+	}
+	em := make([]byte, k)
+	em[1] = 2
+	ps := em[2 : k-len(msg)-1]
+	if _, err := io.ReadFull(rnd, ps); err != nil {
+		return nil, err
+	}
+	for i := range ps {
+		for ps[i] == 0 {
+			if _, err := io.ReadFull(rnd, ps[i:i+1]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	copy(em[k-len(msg):], msg)
+	return em, nil
+}
+
+// DecodePKCS1v15Encryption checks the block type 2 padding of em and returns
+// the message. It returns ErrDecryption if em does not start with 00 02, the
+// padding string is shorter than 8 bytes or there is no zero separator.
+func DecodePKCS1v15Encryption(em []byte) ([]byte, error) {
+	if len(em) < 11 || em[0] != 0 || em[1] != 2 {
+		return nil, ErrDecryption
+	}
+	i := bytes.IndexByte(em[2:], 0)
+	if i < 8 {
+		return nil, ErrDecryption
+	}
+	return em[2+i+1:], nil
+}
+
+// EncryptPKCS1v15 pads msg with PKCS#1 v1.5 block type 2 and encrypts it.
+func EncryptPKCS1v15(rnd io.Reader, pub *PublicKey, msg []byte) ([]byte, error) {
+	em, err := EncodePKCS1v15Encryption(rnd, pub.Size(), msg)
+	if err != nil {
+		return nil, err
+	}
+	return pub.Encrypt(em)
+}
+
+// DecryptPKCS1v15 decrypts ct and removes the PKCS#1 v1.5 padding.
+func DecryptPKCS1v15(priv *PrivateKey, ct []byte) ([]byte, error) {
+	if len(ct) != priv.Size() {
+		return nil, ErrDecryption
+	}
+	em, err := priv.Decrypt(ct)
+	if err != nil {
+		return nil, ErrDecryption
+	}
+	return DecodePKCS1v15Encryption(em)
+}
+
+// EncodePKCS1v15Signature returns the k byte block
+// 00 01 FF ... FF 00 DigestInfo hashed
+// with at least 8 FF bytes.
+func EncodePKCS1v15Signature(k int, hash crypto.Hash, hashed []byte) ([]byte, error) {
+	prefix, ok := digestInfoPrefixes[hash]
+	if !ok {
+		return nil, ErrUnsupportedHash
+	}
+	if len(hashed) != hash.Size() {
+		return nil, ErrVerification
+	}
+	tLen := len(prefix) + len(hashed)
+	if k < tLen+11 {
+		return nil, ErrMessageTooLong
+	}
+	em := make([]byte, k)
+	em[1] = 1
+	for i := 2; i < k-tLen-1; i++ {
+		em[i] = 0xff
+	}
+	copy(em[k-tLen:], prefix)
+	copy(em[k-len(hashed):], hashed)
+	return em, nil
+}
+
+// SignPKCS1v15 signs hashed, the output of hash, with PKCS#1 v1.5 padding.
+func SignPKCS1v15(priv *PrivateKey, hash crypto.Hash, hashed []byte) ([]byte, error) {
+	em, err := EncodePKCS1v15Signature(priv.Size(), hash, hashed)
+	if err != nil {
+		return nil, err
+	}
+	return priv.Decrypt(em)
+}
+
+// openSignature returns sig^E mod N as a Size byte block.
+func openSignature(pub *PublicKey, sig []byte) ([]byte, error) {
+	if len(sig) != pub.Size() {
+		return nil, ErrVerification
+	}
+	em, err := pub.Encrypt(sig)
+	if err != nil {
+		return nil, ErrVerification
+	}
+	return em, nil
+}
+
+// VerifyPKCS1v15 verifies a PKCS#1 v1.5 signature the correct way: it builds
+// the expected block and compares the whole of it in constant time, so no
+// byte of the opened signature is left unchecked.
+func VerifyPKCS1v15(pub *PublicKey, hash crypto.Hash, hashed, sig []byte) error {
+	em, err := openSignature(pub, sig)
+	if err != nil {
+		return err
+	}
+	exp, err := EncodePKCS1v15Signature(pub.Size(), hash, hashed)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(em, exp) != 1 {
+		return ErrVerification
+	}
+	return nil
+}
+
+// VerifyPKCS1v15Sloppy verifies a PKCS#1 v1.5 signature the way challenge 42
+// describes a broken implementation: it skips any number of FF bytes up to
+// the zero separator, checks the DigestInfo and the hash which follow and
+// ignores whatever comes after them. It must not be used for anything but
+// demonstrating the e=3 forgery.
+func VerifyPKCS1v15Sloppy(pub *PublicKey, hash crypto.Hash, hashed, sig []byte) error {
+	prefix, ok := digestInfoPrefixes[hash]
+	if !ok {
+		return ErrUnsupportedHash
+	}
+	if len(hashed) != hash.Size() {
+		return ErrVerification
+	}
+	em, err := openSignature(pub, sig)
+	if err != nil {
+		return err
+	}
+	if em[0] != 0 || em[1] != 1 || em[2] != 0xff {
+		return ErrVerification
+	}
+	i := 2
+	for i < len(em) && em[i] == 0xff {
+		i++
+	}
+	if i == len(em) || em[i] != 0 {
+		return ErrVerification
+	}
+	rest := em[i+1:]
+	if !bytes.HasPrefix(rest, prefix) || !bytes.HasPrefix(rest[len(prefix):], hashed) {
+		return ErrVerification
+	}
+	return nil
+}
+
+// ForgePKCS1v15 forges a signature of hashed that VerifyPKCS1v15Sloppy
+// accepts for any key with a small public exponent, Bleichenbacher's attack
+// from 2006. The block 00 01 FF 00 DigestInfo hashed is followed by zero
+// bytes and the signature is its E-th root rounded up; the error of the
+// rounding only changes the trailing garbage. It returns ErrForgery if the
+// garbage is too short to absorb the error.
+func ForgePKCS1v15(pub *PublicKey, hash crypto.Hash, hashed []byte) ([]byte, error) {
+	prefix, ok := digestInfoPrefixes[hash]
+	if !ok {
+		return nil, ErrUnsupportedHash
+	}
+	if !pub.E.IsInt64() || pub.E.Int64() > 1<<16 {
+		return nil, ErrForgery
+	}
+	k := pub.Size()
+	head := append([]byte{0, 1, 0xff, 0}, prefix...)
+	head = append(head, hashed...)
+	if len(head) >= k {
+		return nil, ErrForgery
+	}
+	em := make([]byte, k)
+	copy(em, head)
+	root, exact := Root(new(big.Int).SetBytes(em), int(pub.E.Int64()))
+	if !exact {
+		root.Add(root, one)
+	}
+	sig := root.FillBytes(make([]byte, k))
+	got, err := pub.Encrypt(sig)
+	if err != nil || !bytes.HasPrefix(got, head) {
+		return nil, ErrForgery
+	}
+	return sig, nil
+}
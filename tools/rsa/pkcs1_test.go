@@ -0,0 +1,167 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto"
+	_ "crypto/md5"
+	"crypto/rand"
+	stdrsa "crypto/rsa"
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"math/big"
+	"testing"
+)
+
+var pkcs1Hashes = []crypto.Hash{crypto.MD5, crypto.SHA1, crypto.SHA256, crypto.SHA512}
+
+// toStd converts priv into a crypto/rsa key.
+func toStd(t *testing.T, priv *PrivateKey) *stdrsa.PrivateKey {
+	t.Helper()
+	k := &stdrsa.PrivateKey{
+		PublicKey: stdrsa.PublicKey{N: priv.N, E: int(priv.E.Int64())},
+		D:         priv.D,
+		Primes:    []*big.Int{priv.P, priv.Q},
+	}
+	if err := k.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	k.Precompute()
+	return k
+}
+
+func digest(hash crypto.Hash, msg string) []byte {
+	h := hash.New()
+	h.Write([]byte(msg))
+	return h.Sum(nil)
+}
+
+func TestPKCS1v15Signatures(t *testing.T) {
+	priv, err := GenerateKey(nil, 2048, 65537)
+	if err != nil {
+		t.Fatal(err)
+	}
+	std := toStd(t, priv)
+	for _, hash := range pkcs1Hashes {
+		hashed := digest(hash, "hi mom")
+		sig, err := SignPKCS1v15(priv, hash, hashed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		exp, err := stdrsa.SignPKCS1v15(nil, std, hash, hashed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(sig, exp) {
+			t.Errorf("%v: SignPKCS1v15 differs from crypto/rsa", hash)
+		}
+		if err := stdrsa.VerifyPKCS1v15(&std.PublicKey, hash, hashed, sig); err != nil {
+			t.Errorf("%v: crypto/rsa rejected our signature: %v", hash, err)
+		}
+		if err := VerifyPKCS1v15(&priv.PublicKey, hash, hashed, exp); err != nil {
+			t.Errorf("%v: VerifyPKCS1v15 rejected a crypto/rsa signature: %v", hash, err)
+		}
+		if err := VerifyPKCS1v15Sloppy(&priv.PublicKey, hash, hashed, exp); err != nil {
+			t.Errorf("%v: VerifyPKCS1v15Sloppy rejected a crypto/rsa signature: %v", hash, err)
+		}
+		other := digest(hash, "hi dad")
+		if err := VerifyPKCS1v15(&priv.PublicKey, hash, other, sig); err != ErrVerification {
+			t.Errorf("%v: VerifyPKCS1v15 of another message = %v", hash, err)
+		}
+		if err := VerifyPKCS1v15Sloppy(&priv.PublicKey, hash, other, sig); err != ErrVerification {
+			t.Errorf("%v: VerifyPKCS1v15Sloppy of another message = %v", hash, err)
+		}
+	}
+	if _, err := SignPKCS1v15(priv, crypto.SHA224, make([]byte, 28)); err != ErrUnsupportedHash {
+		t.Errorf("SignPKCS1v15(SHA224) = %v; want %v", err, ErrUnsupportedHash)
+	}
+}
+
+func TestPKCS1v15Encryption(t *testing.T) {
+	priv, err := GenerateKey(nil, 1024, 65537)
+	if err != nil {
+		t.Fatal(err)
+	}
+	std := toStd(t, priv)
+	for _, l := range []int{0, 1, 20, priv.Size() - 11} {
+		msg := make([]byte, l)
+		rand.Read(msg)
+		ct, err := EncryptPKCS1v15(nil, &priv.PublicKey, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pt, err := stdrsa.DecryptPKCS1v15(nil, std, ct); err != nil || !bytes.Equal(pt, msg) {
+			t.Errorf("len %d: crypto/rsa failed to decrypt: %v", l, err)
+		}
+		ct, err = stdrsa.EncryptPKCS1v15(rand.Reader, &std.PublicKey, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pt, err := DecryptPKCS1v15(priv, ct); err != nil || !bytes.Equal(pt, msg) {
+			t.Errorf("len %d: DecryptPKCS1v15 of a crypto/rsa ciphertext = %x, %v", l, pt, err)
+		}
+	}
+	if _, err := EncryptPKCS1v15(nil, &priv.PublicKey, make([]byte, priv.Size()-10)); err != ErrMessageTooLong {
+		t.Errorf("EncryptPKCS1v15 of a long message = %v; want %v", err, ErrMessageTooLong)
+	}
+
+	k := priv.Size()
+	good, err := EncodePKCS1v15Encryption(nil, k, []byte("msg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.IndexByte(good[2:k-4], 0) != -1 {
+		t.Errorf("padding string contains a zero byte: %x", good)
+	}
+	for name, mod := range map[string]func([]byte){
+		"first byte":    func(em []byte) { em[0] = 1 },
+		"block type":    func(em []byte) { em[1] = 1 },
+		"short padding": func(em []byte) { em[9] = 0 },
+		"no separator":  func(em []byte) { em[k-4] = 0xff },
+	} {
+		em := append([]byte(nil), good...)
+		mod(em)
+		if _, err := DecodePKCS1v15Encryption(em); err != ErrDecryption {
+			t.Errorf("%s: DecodePKCS1v15Encryption = %v; want %v", name, err, ErrDecryption)
+		}
+	}
+}
+
+func TestForgePKCS1v15(t *testing.T) {
+	for _, tc := range []struct {
+		bits int
+		hash crypto.Hash
+	}{{1024, crypto.SHA1}, {1024, crypto.MD5}, {2048, crypto.SHA256}, {3072, crypto.SHA512}} {
+		if testing.Short() && tc.bits > 2048 {
+			continue
+		}
+		priv, err := GenerateKey(nil, tc.bits, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub := &priv.PublicKey
+		hashed := digest(tc.hash, "hi mom")
+		sig, err := ForgePKCS1v15(pub, tc.hash, hashed)
+		if err != nil {
+			t.Fatalf("%d bits, %v: %v", tc.bits, tc.hash, err)
+		}
+		if err := VerifyPKCS1v15Sloppy(pub, tc.hash, hashed, sig); err != nil {
+			t.Errorf("%d bits, %v: the sloppy verifier rejected the forgery", tc.bits, tc.hash)
+		}
+		if err := VerifyPKCS1v15(pub, tc.hash, hashed, sig); err != ErrVerification {
+			t.Errorf("%d bits, %v: VerifyPKCS1v15 of the forgery = %v", tc.bits, tc.hash, err)
+		}
+		stdPub := &stdrsa.PublicKey{N: pub.N, E: 3}
+		if err := stdrsa.VerifyPKCS1v15(stdPub, tc.hash, hashed, sig); err == nil {
+			t.Errorf("%d bits, %v: crypto/rsa accepted the forgery", tc.bits, tc.hash)
+		}
+	}
+	// 1024 bits leave too little room for the garbage of a SHA-512 forgery
+	priv, err := GenerateKey(nil, 1024, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ForgePKCS1v15(&priv.PublicKey, crypto.SHA512, digest(crypto.SHA512, "")); err != ErrForgery {
+		t.Errorf("ForgePKCS1v15(1024 bits, SHA512) = %v; want %v", err, ErrForgery)
+	}
+}
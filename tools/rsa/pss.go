@@ -0,0 +1,143 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"io"
+	"math/big"
+)
+
+const (
+	// PSSSaltLengthAuto makes SignPSS use the longest salt which fits and
+	// VerifyPSS accept any salt length.
+	PSSSaltLengthAuto = 0
+	// PSSSaltLengthEqualsHash uses a salt as long as the hash.
+	PSSSaltLengthEqualsHash = -1
+)
+
+// pssSaltLength resolves the special salt lengths for a block of emLen
+// bytes.
+func pssSaltLength(saltLen, emLen int, hash crypto.Hash) int {
+	switch saltLen {
+	case PSSSaltLengthAuto:
+		return emLen - hash.Size() - 2
+	case PSSSaltLengthEqualsHash:
+		return hash.Size()
+	}
+	return saltLen
+}
+
+// pssHash returns hash(00 00 00 00 00 00 00 00 || mHash || salt).
+func pssHash(hash crypto.Hash, mHash, salt []byte) []byte {
+	h := hash.New()
+	h.Write(make([]byte, 8))
+	h.Write(mHash)
+	h.Write(salt)
+	return h.Sum(nil)
+}
+
+// EncodePSS returns the EMSA-PSS encoding of RFC 8017, section 9.1.1, of
+// the message hash mHash with emBits bits:
+// maskedDB || H || BC
+// where DB = PS || 01 || salt and H = hash(8 zero bytes || mHash || salt).
+func EncodePSS(rnd io.Reader, mHash []byte, emBits int, hash crypto.Hash, saltLen int) ([]byte, error) {
+	if !hash.Available() {
+		return nil, ErrUnsupportedHash
+	}
+	if rnd == nil {
+		rnd = rand.Reader
+	}
+	hLen := hash.Size()
+	if len(mHash) != hLen {
+		return nil, ErrVerification
+	}
+	emLen := (emBits + 7) / 8
+	saltLen = pssSaltLength(saltLen, emLen, hash)
+	if saltLen < 0 || emLen < hLen+saltLen+2 {
+		return nil, ErrMessageTooLong
+	}
+	em := make([]byte, emLen)
+	db := em[:emLen-hLen-1]
+	salt := db[len(db)-saltLen:]
+	if _, err := io.ReadFull(rnd, salt); err != nil {
+		return nil, err
+	}
+	db[len(db)-saltLen-1] = 1
+	h := pssHash(hash, mHash, salt)
+	copy(em[len(db):], h)
+	em[emLen-1] = 0xbc
+	mgf1XOR(db, hash, h)
+	em[0] &= 0xff >> uint(8*emLen-emBits)
+	return em, nil
+}
+
+// VerifyPSSEncoding checks that em is the EMSA-PSS encoding of mHash with
+// emBits bits. saltLen may be PSSSaltLengthAuto to accept any salt.
+func VerifyPSSEncoding(mHash, em []byte, emBits int, hash crypto.Hash, saltLen int) error {
+	if !hash.Available() {
+		return ErrUnsupportedHash
+	}
+	hLen := hash.Size()
+	emLen := (emBits + 7) / 8
+	if len(mHash) != hLen || len(em) != emLen || emLen < hLen+2 || em[emLen-1] != 0xbc {
+		return ErrVerification
+	}
+	mask := byte(0xff >> uint(8*emLen-emBits))
+	if em[0]&^mask != 0 {
+		return ErrVerification
+	}
+	db := append([]byte(nil), em[:emLen-hLen-1]...)
+	h := em[len(db) : emLen-1]
+	mgf1XOR(db, hash, h)
+	db[0] &= mask
+
+	// DB = 00 ... 00 01 salt
+	i := bytes.IndexByte(db, 1)
+	if i < 0 || !allZero(db[:i]) {
+		return ErrVerification
+	}
+	salt := db[i+1:]
+	if saltLen != PSSSaltLengthAuto && len(salt) != pssSaltLength(saltLen, emLen, hash) {
+		return ErrVerification
+	}
+	if !bytes.Equal(h, pssHash(hash, mHash, salt)) {
+		return ErrVerification
+	}
+	return nil
+}
+
+func allZero(b []byte) bool {
+	for _, v := range b {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// SignPSS signs hashed, the output of hash, with RSASSA-PSS. rnd defaults to
+// crypto/rand.Reader when nil.
+func SignPSS(rnd io.Reader, priv *PrivateKey, hash crypto.Hash, hashed []byte, saltLen int) ([]byte, error) {
+	em, err := EncodePSS(rnd, hashed, priv.N.BitLen()-1, hash, saltLen)
+	if err != nil {
+		return nil, err
+	}
+	s := priv.DecryptInt(new(big.Int).SetBytes(em))
+	return s.FillBytes(make([]byte, priv.Size())), nil
+}
+
+// VerifyPSS verifies an RSASSA-PSS signature of hashed.
+func VerifyPSS(pub *PublicKey, hash crypto.Hash, hashed, sig []byte, saltLen int) error {
+	em, err := openSignature(pub, sig)
+	if err != nil {
+		return err
+	}
+	emBits := pub.N.BitLen() - 1
+	emLen := (emBits + 7) / 8
+	// the block is one byte shorter than N if emBits is a multiple of 8
+	if !allZero(em[:len(em)-emLen]) {
+		return ErrVerification
+	}
+	return VerifyPSSEncoding(hashed, em[len(em)-emLen:], emBits, hash, saltLen)
+}
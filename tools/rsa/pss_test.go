@@ -0,0 +1,81 @@
+package rsa
+
+import (
+	"crypto"
+	"crypto/rand"
+	stdrsa "crypto/rsa"
+	"encoding/hex"
+	"testing"
+)
+
+func unhex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestPSSMatchesStdlib(t *testing.T) {
+	// 1025 bits make emBits a multiple of 8 and the block one byte shorter
+	// than the modulus
+	for _, bits := range []int{1024, 1025, 2048} {
+		priv, err := GenerateKey(nil, bits, 65537)
+		if err != nil {
+			t.Fatal(err)
+		}
+		std := toStd(t, priv)
+		for _, hash := range []crypto.Hash{crypto.SHA1, crypto.SHA256, crypto.SHA512} {
+			hashed := digest(hash, "hi mom")
+			for _, saltLen := range []int{PSSSaltLengthAuto, PSSSaltLengthEqualsHash, 7} {
+				if saltLen == PSSSaltLengthEqualsHash && (bits+6)/8 < 2*hash.Size()+2 {
+					continue // the key is too short for such a salt
+				}
+				opts := &stdrsa.PSSOptions{SaltLength: saltLen, Hash: hash}
+				sig, err := SignPSS(nil, priv, hash, hashed, saltLen)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := stdrsa.VerifyPSS(&std.PublicKey, hash, hashed, sig, opts); err != nil {
+					t.Errorf("%d bits, %v, salt %d: crypto/rsa rejected our signature: %v", bits, hash, saltLen, err)
+				}
+				exp, err := stdrsa.SignPSS(rand.Reader, std, hash, hashed, opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := VerifyPSS(&priv.PublicKey, hash, hashed, exp, saltLen); err != nil {
+					t.Errorf("%d bits, %v, salt %d: VerifyPSS rejected a crypto/rsa signature: %v", bits, hash, saltLen, err)
+				}
+				if err := VerifyPSS(&priv.PublicKey, hash, hashed, exp, PSSSaltLengthAuto); err != nil {
+					t.Errorf("%d bits, %v, salt %d: VerifyPSS with any salt length: %v", bits, hash, saltLen, err)
+				}
+				other := digest(hash, "hi dad")
+				if err := VerifyPSS(&priv.PublicKey, hash, other, sig, saltLen); err != ErrVerification {
+					t.Errorf("%d bits, %v: VerifyPSS of another message = %v", bits, hash, err)
+				}
+				sig[len(sig)/2] ^= 1
+				if err := VerifyPSS(&priv.PublicKey, hash, hashed, sig, saltLen); err != ErrVerification {
+					t.Errorf("%d bits, %v: VerifyPSS of a modified signature = %v", bits, hash, err)
+				}
+			}
+		}
+	}
+}
+
+func TestPSSSaltLength(t *testing.T) {
+	priv, err := GenerateKey(nil, 1024, 65537)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashed := digest(crypto.SHA256, "salt")
+	sig, err := SignPSS(nil, priv, crypto.SHA256, hashed, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyPSS(&priv.PublicKey, crypto.SHA256, hashed, sig, 11); err != ErrVerification {
+		t.Errorf("VerifyPSS with the wrong salt length = %v; want %v", err, ErrVerification)
+	}
+	if _, err := SignPSS(nil, priv, crypto.SHA256, hashed, priv.Size()); err != ErrMessageTooLong {
+		t.Errorf("SignPSS with a salt longer than the key = %v; want %v", err, ErrMessageTooLong)
+	}
+}
@@ -0,0 +1,148 @@
+package rsa
+
+import (
+	"errors"
+	"math/big"
+)
+
+// ErrRelated is returned by FranklinReiter when the polynomial GCD is not
+// linear, which happens if the messages are not related as claimed.
+var ErrRelated = errors.New("rsa: messages are not related")
+
+// FactorError reports a factor of the modulus found by accident while
+// computing with polynomials mod n.
+type FactorError struct {
+	Factor *big.Int
+}
+
+func (e *FactorError) Error() string {
+	return "rsa: found a factor of the modulus: " + e.Factor.String()
+}
+
+// zpoly is a polynomial over the integers mod n, low degree first. It is
+// kept trimmed, the zero polynomial is empty.
+type zpoly []*big.Int
+
+func (p zpoly) deg() int {
+	return len(p) - 1
+}
+
+func (p zpoly) trim() zpoly {
+	for len(p) > 0 && p[len(p)-1].Sign() == 0 {
+		p = p[:len(p)-1]
+	}
+	return p
+}
+
+func (p zpoly) mul(q zpoly, n *big.Int) zpoly {
+	if len(p) == 0 || len(q) == 0 {
+		return nil
+	}
+	res := make(zpoly, len(p)+len(q)-1)
+	for i := range res {
+		res[i] = new(big.Int)
+	}
+	t := new(big.Int)
+	for i, a := range p {
+		for j, b := range q {
+			res[i+j].Add(res[i+j], t.Mul(a, b))
+		}
+	}
+	for _, c := range res {
+		c.Mod(c, n)
+	}
+	return res.trim()
+}
+
+// mod returns p mod q. The leading coefficient of q has to be invertible,
+// otherwise a factor of n is returned as error.
+func (p zpoly) mod(q zpoly, n *big.Int) (zpoly, error) {
+	inv, err := Invmod(q[len(q)-1], n)
+	if err != nil {
+		return nil, &FactorError{new(big.Int).GCD(nil, nil, q[len(q)-1], n)}
+	}
+	r := make(zpoly, len(p))
+	for i, c := range p {
+		r[i] = new(big.Int).Set(c)
+	}
+	t := new(big.Int)
+	for r = r.trim(); r.deg() >= q.deg(); r = r.trim() {
+		// subtract coef * x^shift * q to cancel the leading term
+		coef := new(big.Int).Mul(r[len(r)-1], inv)
+		coef.Mod(coef, n)
+		shift := r.deg() - q.deg()
+		for i, c := range q {
+			r[i+shift].Sub(r[i+shift], t.Mul(coef, c))
+			r[i+shift].Mod(r[i+shift], n)
+		}
+	}
+	return r, nil
+}
+
+// gcd returns the greatest common divisor of p and q with Euclid's
+// algorithm.
+func (p zpoly) gcd(q zpoly, n *big.Int) (zpoly, error) {
+	for len(q) > 0 {
+		r, err := p.mod(q, n)
+		if err != nil {
+			return nil, err
+		}
+		p, q = q, r
+	}
+	return p, nil
+}
+
+// FranklinReiter recovers m1 from c1 = m1^e and c2 = m2^e when the messages
+// are related by m2 = a m1 + b mod N, the related message attack of Franklin
+// and Reiter. m1 is a common root of
+// g1(x) = x^e - c1 and g2(x) = (a x + b)^e - c2,
+// so their GCD is x - m1. The polynomials have degree e, which makes the
+// attack practical for small exponents such as 3. The message is returned as
+// Size bytes. If the computation runs into a factor of N, a *FactorError is
+// returned.
+func FranklinReiter(pub *PublicKey, a, b *big.Int, c1, c2 []byte) ([]byte, error) {
+	n := pub.N
+	if !pub.E.IsInt64() || pub.E.Int64() < 2 {
+		return nil, ErrExponent
+	}
+	e := int(pub.E.Int64())
+
+	// g1 = x^e - c1
+	g1 := make(zpoly, e+1)
+	for i := range g1 {
+		g1[i] = new(big.Int)
+	}
+	g1[0].Sub(n, new(big.Int).SetBytes(c1))
+	g1[0].Mod(g1[0], n)
+	g1[e].SetInt64(1)
+
+	// g2 = (a x + b)^e - c2
+	lin := zpoly{new(big.Int).Mod(b, n), new(big.Int).Mod(a, n)}.trim()
+	g2 := zpoly{big.NewInt(1)}
+	for i := 0; i < e; i++ {
+		g2 = g2.mul(lin, n)
+	}
+	if len(g2) == 0 {
+		return nil, ErrRelated
+	}
+	g2[0].Sub(g2[0], new(big.Int).SetBytes(c2))
+	g2[0].Mod(g2[0], n)
+	g2 = g2.trim()
+
+	g, err := g1.gcd(g2, n)
+	if err != nil {
+		return nil, err
+	}
+	if g.deg() != 1 {
+		return nil, ErrRelated
+	}
+	// g = g1 x + g0, m1 = -g0 / g1
+	inv, err := Invmod(g[1], n)
+	if err != nil {
+		return nil, &FactorError{new(big.Int).GCD(nil, nil, g[1], n)}
+	}
+	m := inv.Mul(inv, g[0])
+	m.Neg(m)
+	m.Mod(m, n)
+	return m.FillBytes(make([]byte, pub.Size())), nil
+}
@@ -0,0 +1,80 @@
+package rsa
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestFranklinReiter(t *testing.T) {
+	for _, e := range []int64{3, 5, 7, 11} {
+		priv, err := GenerateKey(nil, 512, e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub := &priv.PublicKey
+		m1, _ := rand.Int(rand.Reader, pub.N)
+		a, _ := rand.Int(rand.Reader, pub.N)
+		b, _ := rand.Int(rand.Reader, pub.N)
+		if e == 3 {
+			a.SetInt64(1) // m2 = m1 + b, the padding example of the paper
+		}
+		m2 := new(big.Int).Mul(a, m1)
+		m2.Add(m2, b)
+		m2.Mod(m2, pub.N)
+		c1 := pub.EncryptInt(m1).Bytes()
+		c2 := pub.EncryptInt(m2).Bytes()
+
+		got, err := FranklinReiter(pub, a, b, c1, c2)
+		if err != nil {
+			t.Fatalf("e = %d: %v", e, err)
+		}
+		if new(big.Int).SetBytes(got).Cmp(m1) != 0 {
+			t.Errorf("e = %d: FranklinReiter = %x; want %x", e, got, m1)
+		}
+		// with the wrong relation the GCD is constant
+		b.Add(b, one)
+		if _, err := FranklinReiter(pub, a, b, c1, c2); err != ErrRelated {
+			var fe *FactorError
+			if !errors.As(err, &fe) {
+				t.Errorf("e = %d: FranklinReiter with a wrong relation = %v; want %v", e, err, ErrRelated)
+			}
+		}
+	}
+}
+
+func TestZpolyGCD(t *testing.T) {
+	n := big.NewInt(101)
+	poly := func(cs ...int64) zpoly {
+		p := make(zpoly, len(cs))
+		for i, c := range cs {
+			p[i] = big.NewInt(c)
+		}
+		return p.trim()
+	}
+	// (x + 1)(x + 2) and (x + 1)(x + 3) share x + 1
+	p := poly(1, 1).mul(poly(2, 1), n)
+	q := poly(1, 1).mul(poly(3, 1), n)
+	g, err := p.gcd(q, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.deg() != 1 || new(big.Int).Mul(g[0], mustInvmod(g[1], n)).Int64()%101 != 1 {
+		t.Errorf("gcd = %v; want a multiple of x + 1", g)
+	}
+	// a leading coefficient sharing a factor with n reveals it
+	_, err = poly(1, 2, 3).mod(poly(1, 5), big.NewInt(35))
+	var fe *FactorError
+	if !errors.As(err, &fe) || fe.Factor.Int64() != 5 {
+		t.Errorf("mod by 5x + 1 mod 35 = %v; want factor 5", err)
+	}
+}
+
+func mustInvmod(a, m *big.Int) *big.Int {
+	inv, err := Invmod(a, m)
+	if err != nil {
+		panic(err)
+	}
+	return inv
+}
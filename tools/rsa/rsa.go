@@ -0,0 +1,160 @@
+// Package rsa is the textbook RSA used by the challenges of sets 5 and 6:
+// key generation for an arbitrary public exponent, unpadded encryption and
+// CRT decryption of integers and of byte strings as wide as the modulus.
+package rsa
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+)
+
+var (
+	// ErrExponent is returned when e is not invertible modulo (p-1)(q-1).
+	ErrExponent = errors.New("rsa: public exponent is not coprime with phi")
+	// ErrKeySize is returned for moduli too small to hold two distinct primes.
+	ErrKeySize = errors.New("rsa: key size too small")
+	// ErrMessageTooLong is returned when a message is not smaller than N.
+	ErrMessageTooLong = errors.New("rsa: message is not smaller than the modulus")
+	// ErrNotInvertible is returned by Invmod when gcd(a, m) != 1.
+	ErrNotInvertible = errors.New("rsa: value is not invertible")
+)
+
+var one = big.NewInt(1)
+
+// PublicKey is an RSA public key.
+type PublicKey struct {
+	N *big.Int // modulus
+	E *big.Int // public exponent
+}
+
+// PrivateKey is an RSA private key together with the values used for CRT
+// decryption.
+type PrivateKey struct {
+	PublicKey          // public part
+	D         *big.Int // private exponent
+	P, Q      *big.Int // prime factors of N
+
+	Dp   *big.Int // D mod (P-1)
+	Dq   *big.Int // D mod (Q-1)
+	Qinv *big.Int // Q^-1 mod P
+}
+
+// Size returns the length of the modulus in bytes. Ciphertexts and
+// decrypted plaintexts are always this long.
+func (pub *PublicKey) Size() int {
+	return (pub.N.BitLen() + 7) / 8
+}
+
+// GenerateKey generates a key with a modulus of exactly bits bits and the
+// public exponent e. Primes are drawn until gcd(e, (p-1)(q-1)) = 1, so e = 3
+// works as well as 65537. rnd defaults to crypto/rand.Reader when nil.
+func GenerateKey(rnd io.Reader, bits int, e int64) (*PrivateKey, error) {
+	if rnd == nil {
+		rnd = rand.Reader
+	}
+	if bits < 16 {
+		return nil, ErrKeySize
+	}
+	if e < 3 || e%2 == 0 {
+		return nil, ErrExponent
+	}
+	E := big.NewInt(e)
+	for {
+		p, err := rand.Prime(rnd, bits/2)
+		if err != nil {
+			return nil, err
+		}
+		q, err := rand.Prime(rnd, bits-bits/2)
+		if err != nil {
+			return nil, err
+		}
+		if p.Cmp(q) == 0 {
+			continue
+		}
+		priv, err := NewPrivateKey(p, q, E)
+		if err == ErrExponent {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if priv.N.BitLen() != bits {
+			continue
+		}
+		return priv, nil
+	}
+}
+
+// NewPrivateKey builds a key from the primes p and q and the public exponent
+// e. It returns ErrExponent if e is not invertible modulo (p-1)(q-1).
+func NewPrivateKey(p, q, e *big.Int) (*PrivateKey, error) {
+	if p.Cmp(q) == 0 || p.Cmp(one) <= 0 || q.Cmp(one) <= 0 {
+		return nil, ErrKeySize
+	}
+	p1 := new(big.Int).Sub(p, one)
+	q1 := new(big.Int).Sub(q, one)
+	phi := new(big.Int).Mul(p1, q1)
+	d, err := Invmod(e, phi)
+	if err != nil {
+		return nil, ErrExponent
+	}
+	qinv, err := Invmod(q, p)
+	if err != nil {
+		return nil, err
+	}
+	priv := &PrivateKey{
+		PublicKey: PublicKey{N: new(big.Int).Mul(p, q), E: new(big.Int).Set(e)},
+		D:         d,
+		P:         new(big.Int).Set(p),
+		Q:         new(big.Int).Set(q),
+		Dp:        new(big.Int).Mod(d, p1),
+		Dq:        new(big.Int).Mod(d, q1),
+		Qinv:      qinv,
+	}
+	return priv, nil
+}
+
+// EncryptInt returns m^E mod N.
+func (pub *PublicKey) EncryptInt(m *big.Int) *big.Int {
+	return new(big.Int).Exp(m, pub.E, pub.N)
+}
+
+// DecryptInt returns c^D mod N computed with the Chinese Remainder Theorem.
+// Keys without the CRT values fall back to a plain exponentiation.
+func (priv *PrivateKey) DecryptInt(c *big.Int) *big.Int {
+	if priv.Dp == nil || priv.Dq == nil || priv.Qinv == nil {
+		return new(big.Int).Exp(c, priv.D, priv.N)
+	}
+	// m1 = c^dP mod p, m2 = c^dQ mod q, h = qInv (m1 - m2) mod p
+	m1 := new(big.Int).Exp(c, priv.Dp, priv.P)
+	m2 := new(big.Int).Exp(c, priv.Dq, priv.Q)
+	h := m1.Sub(m1, m2)
+	h.Mul(h, priv.Qinv)
+	h.Mod(h, priv.P)
+	// m = m2 + h q
+	h.Mul(h, priv.Q)
+	return h.Add(h, m2)
+}
+
+// Encrypt encrypts msg without any padding. The result is always Size bytes
+// long. It returns ErrMessageTooLong if msg as a number is not smaller than
+// N.
+func (pub *PublicKey) Encrypt(msg []byte) ([]byte, error) {
+	m := new(big.Int).SetBytes(msg)
+	if m.Cmp(pub.N) >= 0 {
+		return nil, ErrMessageTooLong
+	}
+	return pub.EncryptInt(m).FillBytes(make([]byte, pub.Size())), nil
+}
+
+// Decrypt decrypts c without removing any padding. The result is always Size
+// bytes long, so leading zero bytes of the plaintext are kept.
+func (priv *PrivateKey) Decrypt(c []byte) ([]byte, error) {
+	cnum := new(big.Int).SetBytes(c)
+	if cnum.Cmp(priv.N) >= 0 {
+		return nil, ErrMessageTooLong
+	}
+	return priv.DecryptInt(cnum).FillBytes(make([]byte, priv.Size())), nil
+}
@@ -0,0 +1,157 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto/rand"
+	stdrsa "crypto/rsa"
+	"math/big"
+	"testing"
+)
+
+// fromStd converts a crypto/rsa key with two primes.
+func fromStd(t *testing.T, k *stdrsa.PrivateKey) *PrivateKey {
+	t.Helper()
+	priv, err := NewPrivateKey(k.Primes[0], k.Primes[1], big.NewInt(int64(k.E)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return priv
+}
+
+func TestMatchesStdlibKeys(t *testing.T) {
+	for _, bits := range []int{512, 1024, 2048} {
+		std, err := stdrsa.GenerateKey(rand.Reader, bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		std.Precompute()
+		priv := fromStd(t, std)
+		if priv.N.Cmp(std.N) != 0 {
+			t.Fatalf("%d bits: N differs from crypto/rsa", bits)
+		}
+		if priv.Dp.Cmp(std.Precomputed.Dp) != 0 || priv.Dq.Cmp(std.Precomputed.Dq) != 0 ||
+			priv.Qinv.Cmp(std.Precomputed.Qinv) != 0 {
+			t.Fatalf("%d bits: CRT values differ from crypto/rsa", bits)
+		}
+		for i := 0; i < 8; i++ {
+			msg := make([]byte, 20+i)
+			rand.Read(msg)
+			ct, err := stdrsa.EncryptPKCS1v15(rand.Reader, &std.PublicKey, msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// the decrypted block starts with 00 02, which must be kept
+			em, err := priv.Decrypt(ct)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(em) != std.Size() || em[0] != 0 || em[1] != 2 {
+				t.Fatalf("%d bits: Decrypt lost the leading zero: %x", bits, em[:4])
+			}
+			if !bytes.Equal(em[len(em)-len(msg):], msg) {
+				t.Fatalf("%d bits: Decrypt = %x; want suffix %x", bits, em, msg)
+			}
+			// std's D may be reduced modulo lambda, both have to agree
+			exp := new(big.Int).Exp(new(big.Int).SetBytes(ct), std.D, std.N)
+			if new(big.Int).SetBytes(em).Cmp(exp) != 0 {
+				t.Fatalf("%d bits: CRT decryption differs from c^D mod N", bits)
+			}
+			ct2, err := priv.Encrypt(em)
+			if err != nil || !bytes.Equal(ct2, ct) {
+				t.Fatalf("%d bits: Encrypt(Decrypt(c)) != c: %v", bits, err)
+			}
+		}
+	}
+}
+
+func TestGenerateKey(t *testing.T) {
+	for _, tc := range []struct {
+		bits int
+		e    int64
+	}{{256, 3}, {511, 3}, {512, 65537}, {1024, 17}, {768, 3}} {
+		priv, err := GenerateKey(nil, tc.bits, tc.e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if priv.N.BitLen() != tc.bits {
+			t.Errorf("GenerateKey(%d): N has %d bits", tc.bits, priv.N.BitLen())
+		}
+		p1 := new(big.Int).Sub(priv.P, one)
+		q1 := new(big.Int).Sub(priv.Q, one)
+		phi := p1.Mul(p1, q1)
+		if g := new(big.Int).GCD(nil, nil, priv.E, phi); g.Cmp(one) != 0 {
+			t.Errorf("GenerateKey(%d, %d): gcd(e, phi) = %v", tc.bits, tc.e, g)
+		}
+		ed := new(big.Int).Mul(priv.E, priv.D)
+		if ed.Mod(ed, phi).Cmp(one) != 0 {
+			t.Errorf("GenerateKey(%d, %d): e*d != 1 mod phi", tc.bits, tc.e)
+		}
+		m, _ := rand.Int(rand.Reader, priv.N)
+		if got := priv.DecryptInt(priv.EncryptInt(m)); got.Cmp(m) != 0 {
+			t.Errorf("GenerateKey(%d, %d): decryption failed", tc.bits, tc.e)
+		}
+		// a plain exponentiation key gives the same result
+		plain := &PrivateKey{PublicKey: priv.PublicKey, D: priv.D}
+		c := priv.EncryptInt(m)
+		if plain.DecryptInt(c).Cmp(priv.DecryptInt(c)) != 0 {
+			t.Errorf("GenerateKey(%d, %d): CRT and plain decryption differ", tc.bits, tc.e)
+		}
+	}
+	if _, err := GenerateKey(nil, 256, 4); err != ErrExponent {
+		t.Errorf("GenerateKey with even e = %v; want %v", err, ErrExponent)
+	}
+}
+
+func TestNewPrivateKeyExponent(t *testing.T) {
+	// phi = 10 * 12 = 120 shares the factor 3 with e
+	if _, err := NewPrivateKey(big.NewInt(11), big.NewInt(13), big.NewInt(3)); err != ErrExponent {
+		t.Errorf("NewPrivateKey(11, 13, 3) = %v; want %v", err, ErrExponent)
+	}
+	priv, err := NewPrivateKey(big.NewInt(61), big.NewInt(53), big.NewInt(17))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the example from Wikipedia
+	if priv.D.Int64() != 2753 || priv.N.Int64() != 3233 {
+		t.Errorf("N, D = %v, %v; want 3233, 2753", priv.N, priv.D)
+	}
+	if c := priv.EncryptInt(big.NewInt(65)); c.Int64() != 2790 {
+		t.Errorf("65^17 mod 3233 = %v; want 2790", c)
+	}
+}
+
+func TestEncryptBytes(t *testing.T) {
+	priv, err := GenerateKey(nil, 512, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range [][]byte{
+		nil,
+		[]byte("short"),
+		{0, 0, 0, 'h', 'i'},
+		append(make([]byte, priv.Size()-1), 1),
+	} {
+		ct, err := priv.Encrypt(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ct) != priv.Size() {
+			t.Errorf("Encrypt(%x) returned %d bytes; want %d", msg, len(ct), priv.Size())
+		}
+		pt, err := priv.Decrypt(ct)
+		if err != nil {
+			t.Fatal(err)
+		}
+		exp := make([]byte, priv.Size())
+		copy(exp[len(exp)-len(msg):], msg)
+		if !bytes.Equal(pt, exp) {
+			t.Errorf("Decrypt(Encrypt(%x)) = %x", msg, pt)
+		}
+	}
+	if _, err := priv.Encrypt(priv.N.Bytes()); err != ErrMessageTooLong {
+		t.Errorf("Encrypt(N) = %v; want %v", err, ErrMessageTooLong)
+	}
+	if _, err := priv.Decrypt(bytes.Repeat([]byte{0xff}, priv.Size())); err != ErrMessageTooLong {
+		t.Errorf("Decrypt(ff..ff) = %v; want %v", err, ErrMessageTooLong)
+	}
+}
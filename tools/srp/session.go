@@ -0,0 +1,163 @@
+package srp
+
+import (
+	"crypto/hmac"
+	"math/big"
+)
+
+// Client is the client side of one SRP login:
+//
+//	C -> S: I, A = g^a
+//	S -> C: s, B = k v + g^b
+//	C -> S: M1
+//	S -> C: M2
+type Client struct {
+	params             *Params
+	username, password string
+	a, A               *big.Int
+	key, m1            []byte
+}
+
+// NewClient starts a login of username with a random secret a.
+func NewClient(p *Params, username, password string) (*Client, error) {
+	a, err := p.secret()
+	if err != nil {
+		return nil, err
+	}
+	return newClient(p, username, password, a), nil
+}
+
+func newClient(p *Params, username, password string, a *big.Int) *Client {
+	A := new(big.Int).Exp(p.Group.G, a, p.Group.N)
+	return &Client{params: p, username: username, password: password, a: a, A: A}
+}
+
+// Public returns A, which is sent with the username.
+func (c *Client) Public() *big.Int {
+	return new(big.Int).Set(c.A)
+}
+
+// Proof computes the session key from the salt and B sent by the server and
+// returns the proof M1 to send back. It returns ErrPublic if B is 0 mod N.
+func (c *Client) Proof(salt []byte, B *big.Int) ([]byte, error) {
+	p := c.params
+	if err := p.checkPublic(B); err != nil {
+		return nil, err
+	}
+	return c.proof(salt, B, p.U(c.A, B), p.K())
+}
+
+// proof computes the key with the scrambler u and the multiplier k of B.
+func (c *Client) proof(salt []byte, B, u, k *big.Int) ([]byte, error) {
+	p := c.params
+	if u.Sign() == 0 && !p.Insecure {
+		return nil, ErrPublic
+	}
+	N := p.Group.N
+	x := p.X(salt, c.username, c.password)
+	// S = (B - k g^x)^(a + u x) mod N
+	base := new(big.Int).Exp(p.Group.G, x, N)
+	base.Mul(base, k)
+	base.Sub(B, base)
+	base.Mod(base, N)
+	e := new(big.Int).Mul(u, x)
+	e.Add(e, c.a)
+	S := base.Exp(base, e, N)
+	c.key = p.SessionKey(S)
+	c.m1 = p.ClientProof(c.username, salt, c.A, B, c.key)
+	return append([]byte(nil), c.m1...), nil
+}
+
+// Verify checks the proof M2 of the server. It returns ErrProof if it does
+// not match, and ErrState before Proof.
+func (c *Client) Verify(m2 []byte) error {
+	if c.m1 == nil {
+		return ErrState
+	}
+	if !hmac.Equal(m2, c.params.ServerProof(c.A, c.m1, c.key)) {
+		return ErrProof
+	}
+	return nil
+}
+
+// Key returns the session key K, nil before Proof. It is only shared with
+// the server once Verify succeeds.
+func (c *Client) Key() []byte {
+	return append([]byte(nil), c.key...)
+}
+
+// Server is the server side of one SRP login.
+type Server struct {
+	params     *Params
+	username   string
+	ver        *Verifier
+	b, A, B, u *big.Int
+	key        []byte
+}
+
+// NewServer starts the login of username with the client's A, looking up
+// the verifier in store. It returns ErrPublic if A is 0 mod N and the
+// error of store for unknown users.
+func NewServer(p *Params, store Store, username string, A *big.Int) (*Server, error) {
+	b, err := p.secret()
+	if err != nil {
+		return nil, err
+	}
+	return newServer(p, store, username, A, b, p.K())
+}
+
+// newServer starts a login with the secret b and the multiplier k of B. The
+// scrambler u is H(A | B) unless the caller replaces it.
+func newServer(p *Params, store Store, username string, A, b, k *big.Int) (*Server, error) {
+	if err := p.checkPublic(A); err != nil {
+		return nil, err
+	}
+	ver, err := store.Lookup(username)
+	if err != nil {
+		return nil, err
+	}
+	N := p.Group.N
+	// B = k v + g^b mod N
+	B := new(big.Int).Mul(k, ver.V)
+	B.Add(B, new(big.Int).Exp(p.Group.G, b, N))
+	B.Mod(B, N)
+	A = new(big.Int).Set(A)
+	return &Server{params: p, username: username, ver: ver, b: b, A: A, B: B, u: p.U(A, B)}, nil
+}
+
+// Salt returns the salt of the user, which is sent with B.
+func (s *Server) Salt() []byte {
+	return append([]byte(nil), s.ver.Salt...)
+}
+
+// Public returns B.
+func (s *Server) Public() *big.Int {
+	return new(big.Int).Set(s.B)
+}
+
+// Verify checks the client's proof M1 and returns the proof M2 to send
+// back. It returns ErrProof if M1 does not match, in which case the login
+// failed.
+func (s *Server) Verify(m1 []byte) ([]byte, error) {
+	p := s.params
+	if s.u.Sign() == 0 && !p.Insecure {
+		return nil, ErrPublic
+	}
+	N := p.Group.N
+	// S = (A v^u)^b mod N
+	S := new(big.Int).Exp(s.ver.V, s.u, N)
+	S.Mul(S, s.A)
+	S.Mod(S, N)
+	S.Exp(S, s.b, N)
+	key := p.SessionKey(S)
+	if !hmac.Equal(m1, p.ClientProof(s.username, s.ver.Salt, s.A, s.B, key)) {
+		return nil, ErrProof
+	}
+	s.key = key
+	return p.ServerProof(s.A, m1, key), nil
+}
+
+// Key returns the session key K, nil until Verify succeeds.
+func (s *Server) Key() []byte {
+	return append([]byte(nil), s.key...)
+}
@@ -0,0 +1,75 @@
+package srp
+
+import (
+	"io"
+	"math/big"
+)
+
+// ScramblerSize is the size of the random scrambler u of SimpleServer.
+const ScramblerSize = 16
+
+// SimpleClient is the client of the simplified SRP of challenge 38, in
+// which B = g^b does not depend on the verifier and the server sends a
+// random scrambler u with it instead of both sides computing H(A | B):
+//
+//	C -> S: I, A = g^a
+//	S -> C: s, B = g^b, u
+//	C -> S: M1
+//	S -> C: M2
+//
+// Since the client's key then depends on the password only through x, a
+// server which picks b and u itself can check guesses of the password
+// against M1 offline, without knowing the verifier.
+type SimpleClient struct {
+	*Client
+}
+
+// NewSimpleClient starts a simplified login of username with a random
+// secret a.
+func NewSimpleClient(p *Params, username, password string) (*SimpleClient, error) {
+	c, err := NewClient(p, username, password)
+	if err != nil {
+		return nil, err
+	}
+	return &SimpleClient{c}, nil
+}
+
+// Proof computes the session key from the salt, B and u sent by the server
+// and returns the proof M1 to send back. It returns ErrPublic if B is 0 mod
+// N or u is 0.
+func (c *SimpleClient) Proof(salt []byte, B, u *big.Int) ([]byte, error) {
+	if err := c.params.checkPublic(B); err != nil {
+		return nil, err
+	}
+	return c.proof(salt, B, u, new(big.Int))
+}
+
+// SimpleServer is the server side of a simplified login, see SimpleClient.
+type SimpleServer struct {
+	*Server
+}
+
+// NewSimpleServer starts the simplified login of username with the client's
+// A, picking a random secret b and a random scrambler u of ScramblerSize
+// bytes. It returns the same errors as NewServer.
+func NewSimpleServer(p *Params, store Store, username string, A *big.Int) (*SimpleServer, error) {
+	b, err := p.secret()
+	if err != nil {
+		return nil, err
+	}
+	s, err := newServer(p, store, username, A, b, new(big.Int))
+	if err != nil {
+		return nil, err
+	}
+	u := make([]byte, ScramblerSize)
+	if _, err := io.ReadFull(p.rand(), u); err != nil {
+		return nil, err
+	}
+	s.u = new(big.Int).SetBytes(u)
+	return &SimpleServer{s}, nil
+}
+
+// U returns the scrambler u, which is sent with the salt and B.
+func (s *SimpleServer) U() *big.Int {
+	return new(big.Int).Set(s.u)
+}
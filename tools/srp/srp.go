@@ -0,0 +1,182 @@
+// Package srp implements the SRP-6a password-authenticated key exchange of
+// RFC 5054 which challenges 36 to 38 build by hand: the standard groups,
+// the multiplier k = H(N | PAD(g)), the scrambler u = H(PAD(A) | PAD(B)),
+// the x = H(s | H(I | ":" | P)) of the verifier and the M1 and M2 proofs of
+// RFC 2945, and the simplified protocol of challenge 38 with SimpleClient
+// and SimpleServer. The hash and the store of verifiers are pluggable.
+// Public values which are 0 mod N are rejected, which stops the zero key
+// login of challenge 37, unless Params.Insecure is set.
+package srp
+
+import (
+	"crypto"
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+
+	// hashes of RFC 5054 and the default
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+)
+
+var (
+	// ErrPublic is returned for a public value A or B which is 0 mod N, or
+	// a scrambler u which is 0.
+	ErrPublic = errors.New("srp: invalid public value")
+	// ErrProof is returned when the proof of the other side does not match.
+	ErrProof = errors.New("srp: proof does not match")
+	// ErrUnknownUser is returned by MapStore for a user without a verifier.
+	ErrUnknownUser = errors.New("srp: unknown user")
+	// ErrState is returned when the steps of a session are out of order.
+	ErrState = errors.New("srp: session step out of order")
+)
+
+var one = big.NewInt(1)
+
+// SaltSize is the size of the salts NewVerifier picks.
+const SaltSize = 16
+
+// Params are the parameters both sides agree on.
+type Params struct {
+	Group *Group
+	// Hash is H, crypto.SHA256 if zero. RFC 5054 uses crypto.SHA1.
+	Hash crypto.Hash
+	// Insecure accepts public values which are 0 mod N, as the server of
+	// challenge 37 does.
+	Insecure bool
+	// Rand is the source for salts and secret exponents,
+	// crypto/rand.Reader by default.
+	Rand io.Reader
+}
+
+func (p *Params) hash() crypto.Hash {
+	if p.Hash == 0 {
+		return crypto.SHA256
+	}
+	return p.Hash
+}
+
+func (p *Params) rand() io.Reader {
+	if p.Rand == nil {
+		return rand.Reader
+	}
+	return p.Rand
+}
+
+// H returns the hash of the concatenation of parts.
+func (p *Params) H(parts ...[]byte) []byte {
+	h := p.hash().New()
+	for _, b := range parts {
+		h.Write(b)
+	}
+	return h.Sum(nil)
+}
+
+// Pad returns x mod N as a big-endian integer of the byte length of N.
+func (p *Params) Pad(x *big.Int) []byte {
+	n := p.Group.N
+	return new(big.Int).Mod(x, n).FillBytes(make([]byte, (n.BitLen()+7)/8))
+}
+
+func (p *Params) hashInt(parts ...[]byte) *big.Int {
+	return new(big.Int).SetBytes(p.H(parts...))
+}
+
+// K returns the multiplier k = H(N | PAD(g)).
+func (p *Params) K() *big.Int {
+	return p.hashInt(p.Group.N.Bytes(), p.Pad(p.Group.G))
+}
+
+// X returns the private key x = H(s | H(I | ":" | P)) derived from the salt,
+// the username and the password.
+func (p *Params) X(salt []byte, username, password string) *big.Int {
+	return p.hashInt(salt, p.H([]byte(username+":"+password)))
+}
+
+// U returns the scrambler u = H(PAD(A) | PAD(B)).
+func (p *Params) U(A, B *big.Int) *big.Int {
+	return p.hashInt(p.Pad(A), p.Pad(B))
+}
+
+// checkPublic returns ErrPublic for y = 0 mod N unless p is insecure.
+func (p *Params) checkPublic(y *big.Int) error {
+	if p.Insecure {
+		return nil
+	}
+	if y.Sign() < 0 || new(big.Int).Mod(y, p.Group.N).Sign() == 0 {
+		return ErrPublic
+	}
+	return nil
+}
+
+// secret returns a random exponent in [1, N-1].
+func (p *Params) secret() (*big.Int, error) {
+	x, err := rand.Int(p.rand(), new(big.Int).Sub(p.Group.N, one))
+	if err != nil {
+		return nil, err
+	}
+	return x.Add(x, one), nil
+}
+
+// SessionKey returns K = H(PAD(S)) for the premaster secret S.
+func (p *Params) SessionKey(S *big.Int) []byte {
+	return p.H(p.Pad(S))
+}
+
+// ClientProof returns M1 = H(H(N) xor H(g) | H(I) | s | PAD(A) | PAD(B) | K)
+// as in RFC 2945.
+func (p *Params) ClientProof(username string, salt []byte, A, B *big.Int, key []byte) []byte {
+	hn, hg := p.H(p.Group.N.Bytes()), p.H(p.Group.G.Bytes())
+	for i := range hn {
+		hn[i] ^= hg[i]
+	}
+	return p.H(hn, p.H([]byte(username)), salt, p.Pad(A), p.Pad(B), key)
+}
+
+// ServerProof returns M2 = H(PAD(A) | M1 | K).
+func (p *Params) ServerProof(A *big.Int, m1, key []byte) []byte {
+	return p.H(p.Pad(A), m1, key)
+}
+
+// Verifier is what the server stores for a user instead of the password:
+// the salt and v = g^x mod N.
+type Verifier struct {
+	Salt []byte
+	V    *big.Int
+}
+
+// NewVerifier returns the verifier of a user with a random salt of SaltSize
+// bytes.
+func (p *Params) NewVerifier(username, password string) (*Verifier, error) {
+	salt := make([]byte, SaltSize)
+	if _, err := io.ReadFull(p.rand(), salt); err != nil {
+		return nil, err
+	}
+	return p.VerifierWithSalt(salt, username, password), nil
+}
+
+// VerifierWithSalt returns the verifier of a user with the given salt.
+func (p *Params) VerifierWithSalt(salt []byte, username, password string) *Verifier {
+	x := p.X(salt, username, password)
+	v := new(big.Int).Exp(p.Group.G, x, p.Group.N)
+	return &Verifier{append([]byte(nil), salt...), v}
+}
+
+// Store looks up the verifiers of users. Lookup returns an error for
+// unknown users, which the server passes on.
+type Store interface {
+	Lookup(username string) (*Verifier, error)
+}
+
+// MapStore is a Store in memory.
+type MapStore map[string]*Verifier
+
+// Lookup implements Store.
+func (m MapStore) Lookup(username string) (*Verifier, error) {
+	v, ok := m[username]
+	if !ok {
+		return nil, ErrUnknownUser
+	}
+	return v, nil
+}
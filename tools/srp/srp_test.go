@@ -0,0 +1,330 @@
+package srp
+
+import (
+	"bytes"
+	"crypto"
+	_ "crypto/sha512"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func mustHex(s string) *big.Int {
+	n, ok := new(big.Int).SetString(strings.Join(strings.Fields(s), ""), 16)
+	if !ok {
+		panic("bad hex " + s)
+	}
+	return n
+}
+
+// test vectors from RFC 5054, appendix B
+var (
+	vecSalt = mustHex("BEB25379 D1A8581E B5A72767 3A2441EE")
+	vecK    = mustHex("7556AA04 5AEF2CDD 07ABAF0F 665C3E81 8913186F")
+	vecX    = mustHex("94B7555A ABE9127C C58CCF49 93DB6CF8 4D16C124")
+	vecV    = mustHex(`
+		7E273DE8 696FFC4F 4E337D05 B4B375BE B0DDE156 9E8FA00A 9886D812
+		9BADA1F1 822223CA 1A605B53 0E379BA4 729FDC59 F105B478 7E5186F5
+		C671085A 1447B52A 48CF1970 B4FB6F84 00BBF4CE BFBB1681 52E08AB5
+		EA53D15C 1AFF87B2 B9DA6E04 E058AD51 CC72BFC9 033B564E 26480D78
+		E955A5E2 9E7AB245 DB2BE315 E2099AFB`)
+	vecA = mustHex(`
+		60975527 035CF2AD 1989806F 0407210B C81EDC04 E2762A56 AFD529DD
+		DA2D4393`)
+	vecB = mustHex(`
+		E487CB59 D31AC550 471E81F0 0F6928E0 1DDA08E9 74A004F4 9E61F5D1
+		05284D20`)
+	vecPubA = mustHex(`
+		61D5E490 F6F1B795 47B0704C 436F523D D0E560F0 C64115BB 72557EC4
+		4352E890 3211C046 92272D8B 2D1A5358 A2CF1B6E 0BFCF99F 921530EC
+		8E393561 79EAE45E 42BA92AE ACED8251 71E1E8B9 AF6D9C03 E1327F44
+		BE087EF0 6530E69F 66615261 EEF54073 CA11CF58 58F0EDFD FE15EFEA
+		B349EF5D 76988A36 72FAC47B 0769447B`)
+	vecPubB = mustHex(`
+		BD0C6151 2C692C0C B6D041FA 01BB152D 4916A1E7 7AF46AE1 05393011
+		BAF38964 DC46A067 0DD125B9 5A981652 236F99D9 B681CBF8 7837EC99
+		6C6DA044 53728610 D0C6DDB5 8B318885 D7D82C7F 8DEB75CE 7BD4FBAA
+		37089E6F 9C6059F3 88838E7A 00030B33 1EB76840 910440B1 B27AAEAE
+		EB4012B7 D7665238 A8E3FB00 4B117B58`)
+	vecU = mustHex("CE38B959 3487DA98 554ED47D 70A7AE5F 462EF019")
+	vecS = mustHex(`
+		B0DC82BA BCF30674 AE450C02 87745E79 90A3381F 63B387AA F271A10D
+		233861E3 59B48220 F7C4693C 9AE12B0A 6F67809F 0876E2D0 13800D6C
+		41BB59B6 D5979B5C 00A172B4 A2A5903A 0BDCAF8A 709585EB 2AFAFA8F
+		3499B200 210DCC1F 10EB3394 3CD67FC8 8A2F39A4 BE5BEC4E C0A3212D
+		C346D7E4 74B29EDE 8A469FFE CA686E5A`)
+)
+
+func TestRFC5054Vectors(t *testing.T) {
+	grp, err := RFC5054Group(1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Params{Group: grp, Hash: crypto.SHA1}
+	salt := vecSalt.Bytes()
+	check := func(name string, got, want *big.Int) {
+		t.Helper()
+		if got.Cmp(want) != 0 {
+			t.Errorf("%s = %X; want %X", name, got, want)
+		}
+	}
+	check("k", p.K(), vecK)
+	check("x", p.X(salt, "alice", "password123"), vecX)
+	ver := p.VerifierWithSalt(salt, "alice", "password123")
+	check("v", ver.V, vecV)
+
+	c := newClient(p, "alice", "password123", vecA)
+	check("A", c.Public(), vecPubA)
+	s, err := newServer(p, MapStore{"alice": ver}, "alice", c.Public(), vecB, p.K())
+	if err != nil {
+		t.Fatal(err)
+	}
+	check("B", s.Public(), vecPubB)
+	check("u", p.U(vecPubA, vecPubB), vecU)
+
+	m1, err := c.Proof(s.Salt(), s.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m2, err := s.Verify(m1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Verify(m2); err != nil {
+		t.Fatal(err)
+	}
+	key := p.SessionKey(vecS)
+	if !bytes.Equal(c.Key(), key) || !bytes.Equal(s.Key(), key) {
+		t.Errorf("session keys %x and %x; want H(S) = %x", c.Key(), s.Key(), key)
+	}
+}
+
+func TestGroups(t *testing.T) {
+	for _, bits := range []int{1024, 1536, 2048, 3072, 4096, 6144, 8192} {
+		grp, err := RFC5054Group(bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if grp.N.BitLen() != bits {
+			t.Errorf("group %d has N of %d bits", bits, grp.N.BitLen())
+		}
+		if testing.Short() && bits > 2048 {
+			continue
+		}
+		q := new(big.Int).Rsh(grp.N, 1)
+		if !grp.N.ProbablyPrime(1) || !q.ProbablyPrime(1) {
+			t.Errorf("group %d is not a safe prime", bits)
+		}
+	}
+	if _, err := RFC5054Group(512); err != ErrGroup {
+		t.Errorf("RFC5054Group(512) = %v; want %v", err, ErrGroup)
+	}
+}
+
+// login runs a login of username with password against store and returns
+// the first error.
+func login(t *testing.T, p *Params, store Store, username, password string) error {
+	t.Helper()
+	c, err := NewClient(p, username, password)
+	if err != nil {
+		return err
+	}
+	s, err := NewServer(p, store, username, c.Public())
+	if err != nil {
+		return err
+	}
+	m1, err := c.Proof(s.Salt(), s.Public())
+	if err != nil {
+		return err
+	}
+	m2, err := s.Verify(m1)
+	if err != nil {
+		return err
+	}
+	if err := c.Verify(m2); err != nil {
+		return err
+	}
+	if !bytes.Equal(c.Key(), s.Key()) {
+		t.Fatalf("verified sessions with different keys %x and %x", c.Key(), s.Key())
+	}
+	return nil
+}
+
+func TestLogin(t *testing.T) {
+	for _, bits := range []int{1024, 2048, 4096} {
+		grp, err := RFC5054Group(bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, h := range []crypto.Hash{0, crypto.SHA1, crypto.SHA512} {
+			p := &Params{Group: grp, Hash: h}
+			ver, err := p.NewVerifier("foo@bar.com", "hunter2")
+			if err != nil {
+				t.Fatal(err)
+			}
+			store := MapStore{"foo@bar.com": ver}
+			if err := login(t, p, store, "foo@bar.com", "hunter2"); err != nil {
+				t.Errorf("login with group %d and hash %v: %v", bits, h, err)
+			}
+			if err := login(t, p, store, "foo@bar.com", "hunter3"); err != ErrProof {
+				t.Errorf("login with a wrong password = %v; want %v", err, ErrProof)
+			}
+			if err := login(t, p, store, "bar@foo.com", "hunter2"); err != ErrUnknownUser {
+				t.Errorf("login of an unknown user = %v; want %v", err, ErrUnknownUser)
+			}
+		}
+	}
+}
+
+func TestServerProof(t *testing.T) {
+	grp, _ := RFC5054Group(1024)
+	p := &Params{Group: grp}
+	ver, err := p.NewVerifier("alice", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewClient(p, "alice", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Verify(nil); err != ErrState {
+		t.Errorf("Verify before Proof = %v; want %v", err, ErrState)
+	}
+	s, err := NewServer(p, MapStore{"alice": ver}, "alice", c.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m1, err := c.Proof(s.Salt(), s.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m2, err := s.Verify(m1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m2[0] ^= 1
+	if err := c.Verify(m2); err != ErrProof {
+		t.Errorf("Verify with a tampered M2 = %v; want %v", err, ErrProof)
+	}
+	if _, err := c.Proof(s.Salt(), new(big.Int).Set(grp.N)); err != ErrPublic {
+		t.Errorf("Proof with B = N = %v; want %v", err, ErrPublic)
+	}
+}
+
+// TestZeroKey is the attack of challenge 37: with A = 0 mod N the server's
+// S is 0, so the attacker logs in without the password.
+func TestZeroKey(t *testing.T) {
+	grp, _ := RFC5054Group(1024)
+	for _, insecure := range []bool{false, true} {
+		p := &Params{Group: grp, Insecure: insecure}
+		ver, err := p.NewVerifier("alice", "secret")
+		if err != nil {
+			t.Fatal(err)
+		}
+		store := MapStore{"alice": ver}
+		for _, m := range []int64{0, 1, 2} {
+			A := new(big.Int).Mul(grp.N, big.NewInt(m))
+			s, err := NewServer(p, store, "alice", A)
+			if !insecure {
+				if err != ErrPublic {
+					t.Errorf("NewServer with A = %d N = %v; want %v", m, err, ErrPublic)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			key := p.SessionKey(new(big.Int))
+			m1 := p.ClientProof("alice", s.Salt(), A, s.Public(), key)
+			if _, err := s.Verify(m1); err != nil {
+				t.Errorf("zero key login with A = %d N on an insecure server: %v", m, err)
+			}
+		}
+	}
+}
+
+func TestSimpleLogin(t *testing.T) {
+	grp, _ := RFC5054Group(1024)
+	p := &Params{Group: grp}
+	ver, err := p.NewVerifier("alice", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := MapStore{"alice": ver}
+	for _, tc := range []struct {
+		password string
+		err      error
+	}{
+		{"secret", nil},
+		{"public", ErrProof},
+	} {
+		c, err := NewSimpleClient(p, "alice", tc.password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := NewSimpleServer(p, store, "alice", c.Public())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Public().Cmp(new(big.Int).Exp(grp.G, s.b, grp.N)) != 0 {
+			t.Errorf("B = %v; want g^b", s.Public())
+		}
+		m1, err := c.Proof(s.Salt(), s.Public(), s.U())
+		if err != nil {
+			t.Fatal(err)
+		}
+		m2, err := s.Verify(m1)
+		if err != tc.err {
+			t.Errorf("login with password %q = %v; want %v", tc.password, err, tc.err)
+		}
+		if err != nil {
+			continue
+		}
+		if err := c.Verify(m2); err != nil {
+			t.Error(err)
+		}
+		if !bytes.Equal(c.Key(), s.Key()) {
+			t.Errorf("session keys %x and %x differ", c.Key(), s.Key())
+		}
+	}
+	c, err := NewSimpleClient(p, "alice", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Proof(ver.Salt, big.NewInt(2), new(big.Int)); err != ErrPublic {
+		t.Errorf("Proof with u = 0 = %v; want %v", err, ErrPublic)
+	}
+}
+
+// TestSimpleDictionary is the attack of challenge 38: a server which picks
+// b and u recovers the password from M1 with a dictionary.
+func TestSimpleDictionary(t *testing.T) {
+	grp, _ := RFC5054Group(1024)
+	p := &Params{Group: grp}
+	words := []string{"apple", "banana", "cherry", "damson", "elder"}
+	c, err := NewSimpleClient(p, "alice", "cherry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	salt := []byte("salt")
+	A, b, u := c.Public(), big.NewInt(12345), big.NewInt(1)
+	B := new(big.Int).Exp(grp.G, b, grp.N)
+	m1, err := c.Proof(salt, B, u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found string
+	for _, w := range words {
+		// S = (A v^u)^b for the verifier of the guess
+		S := p.VerifierWithSalt(salt, "alice", w).V
+		S.Exp(S, u, grp.N)
+		S.Mul(S, A)
+		S.Exp(S, b, grp.N)
+		if bytes.Equal(m1, p.ClientProof("alice", salt, A, B, p.SessionKey(S))) {
+			found = w
+		}
+	}
+	if found != "cherry" {
+		t.Errorf("cracked password %q; want %q", found, "cherry")
+	}
+}
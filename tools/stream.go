@@ -0,0 +1,168 @@
+package tools
+
+import (
+	"crypto/cipher"
+	"errors"
+	"io"
+)
+
+// streamChunk is the number of bytes processed by a single CryptBlocks call
+// of the streaming wrappers. It is a multiple of every sane block size.
+const streamChunk = 4096
+
+type encryptWriter struct {
+	mode cipher.BlockMode
+	w    io.Writer
+	buf  []byte // plaintext which does not fill a complete block yet
+	out  []byte
+	err  error
+}
+
+// NewEncryptWriter returns a writer which encrypts everything written to it
+// with mode and writes the ciphertext to w. Data is buffered until a complete
+// block is available. Close pads the remaining data with PKCS#7 and flushes
+// it, it does not close w.
+func NewEncryptWriter(mode cipher.BlockMode, w io.Writer) io.WriteCloser {
+	return &encryptWriter{
+		mode: mode,
+		w:    w,
+		buf:  make([]byte, 0, mode.BlockSize()),
+		out:  make([]byte, streamChunk),
+	}
+}
+
+// Write returns the number of bytes of p which were buffered or encrypted
+// and written out before an error.
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	n := 0
+	sz := e.mode.BlockSize()
+	if len(e.buf) > 0 {
+		k := copy(e.buf[len(e.buf):sz], p)
+		e.buf = e.buf[:len(e.buf)+k]
+		p = p[k:]
+		n += k
+		if len(e.buf) < sz {
+			return n, nil
+		}
+		if err := e.flush(e.buf); err != nil {
+			return n, err
+		}
+		e.buf = e.buf[:0]
+	}
+	for len(p) >= sz {
+		k := len(p) - len(p)%sz
+		if k > streamChunk {
+			k = streamChunk
+		}
+		if err := e.flush(p[:k]); err != nil {
+			return n, err
+		}
+		p = p[k:]
+		n += k
+	}
+	e.buf = append(e.buf, p...)
+	return n + len(p), nil
+}
+
+// flush encrypts block aligned src and writes it out.
+func (e *encryptWriter) flush(src []byte) error {
+	dst := e.out[:len(src)]
+	e.mode.CryptBlocks(dst, src)
+	_, e.err = e.w.Write(dst)
+	return e.err
+}
+
+func (e *encryptWriter) Close() error {
+	if e.err != nil {
+		return e.err
+	}
+	err := e.flush(PadPKCS7(e.buf, e.mode.BlockSize()))
+	if err == nil {
+		e.err = errors.New("write to closed encrypt writer")
+	}
+	return err
+}
+
+type decryptReader struct {
+	mode cipher.BlockMode
+	r    io.Reader
+	in   []byte // ciphertext which was read but not decrypted yet
+	out  []byte // plaintext ready to be returned
+	eof  bool
+	err  error
+}
+
+// NewDecryptReader returns a reader which decrypts ciphertext read from r with
+// mode. The last block is held back until r returns io.EOF so that PKCS#7
+// padding can be removed. A truncated ciphertext results in
+// io.ErrUnexpectedEOF, an invalid padding in ErrBadPadding.
+func NewDecryptReader(mode cipher.BlockMode, r io.Reader) io.Reader {
+	return &decryptReader{
+		mode: mode,
+		r:    r,
+		in:   make([]byte, 0, streamChunk+mode.BlockSize()),
+	}
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.out) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		d.fill()
+	}
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	return n, nil
+}
+
+// fill reads more ciphertext and decrypts everything but the last block.
+func (d *decryptReader) fill() {
+	sz := d.mode.BlockSize()
+	n, err := d.r.Read(d.in[len(d.in):cap(d.in)])
+	d.in = d.in[:len(d.in)+n]
+	switch {
+	case err == io.EOF:
+		d.eof = true
+	case err != nil:
+		d.err = err
+		return
+	}
+	if d.eof {
+		if len(d.in) == 0 || len(d.in)%sz != 0 {
+			d.err = io.ErrUnexpectedEOF
+			return
+		}
+		pt := make([]byte, len(d.in))
+		d.mode.CryptBlocks(pt, d.in)
+		d.in = d.in[:0]
+		d.out, d.err = UnpadPKCS7(pt)
+		if d.err == nil {
+			d.err = io.EOF
+		}
+		return
+	}
+	// keep at least one complete block for the final unpadding
+	k := len(d.in) - len(d.in)%sz - sz
+	if k <= 0 {
+		return
+	}
+	pt := make([]byte, k)
+	d.mode.CryptBlocks(pt, d.in[:k])
+	d.out = pt
+	d.in = d.in[:copy(d.in, d.in[k:])]
+}
+
+// NewCTRReader returns a reader which xors the data read from r with the CTR
+// keystream of block, using the same nonce and counter layout as CTREncrypt.
+// Encryption and decryption are the same operation.
+func NewCTRReader(block cipher.Block, nonce uint64, r io.Reader) io.Reader {
+	s, err := NewCTRStream(block, Challenge18CTR(nonce))
+	if err != nil {
+		panic(err)
+	}
+	return cipher.StreamReader{S: s, R: r}
+}
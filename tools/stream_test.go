@@ -0,0 +1,228 @@
+package tools
+
+import (
+	"bytes"
+	"crypto/aes"
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+// streamLengths returns every length up to a few blocks followed by random
+// lengths up to 1 MiB.
+func streamLengths(rng *rand.Rand) []int {
+	var res []int
+	for i := 0; i <= 1024; i++ {
+		res = append(res, i)
+	}
+	res = append(res, 1<<20-1, 1<<20)
+	for i := 0; i < 32; i++ {
+		res = append(res, rng.Intn(1<<20+1))
+	}
+	return res
+}
+
+// chunkedWrite writes src into w in randomly sized pieces.
+func chunkedWrite(rng *rand.Rand, w io.Writer, src []byte) error {
+	for len(src) > 0 {
+		n := rng.Intn(3*aes.BlockSize) + 1
+		if rng.Intn(8) == 0 {
+			n = rng.Intn(3*streamChunk) + 1
+		}
+		if n > len(src) {
+			n = len(src)
+		}
+		if _, err := w.Write(src[:n]); err != nil {
+			return err
+		}
+		src = src[n:]
+	}
+	return nil
+}
+
+// oneByteReader returns at most one byte per Read to exercise buffering.
+type oneByteReader struct{ r io.Reader }
+
+func (o oneByteReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	return o.r.Read(p[:1])
+}
+
+func TestEncryptWriterCBC(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	key := RandBytes(16)
+	IV := RandBytes(16)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := make([]byte, 1<<20)
+	rng.Read(data)
+	lengths := streamLengths(rng)
+	if testing.Short() {
+		lengths = lengths[:256]
+	}
+	for _, l := range lengths {
+		pt := data[:l]
+		exp := PadPKCS7(append([]byte(nil), pt...), aes.BlockSize)
+		CBCEncrypt(block, IV, exp, exp)
+
+		var ct bytes.Buffer
+		w := NewEncryptWriter(NewCBCEncrypter(block, IV), &ct)
+		if err := chunkedWrite(rng, w, pt); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(ct.Bytes(), exp) {
+			t.Fatalf("len %d: EncryptWriter output differs from CBCEncrypt", l)
+		}
+
+		var r io.Reader = NewDecryptReader(NewCBCDecrypter(block, IV), bytes.NewReader(exp))
+		if l < 64 {
+			r = NewDecryptReader(NewCBCDecrypter(block, IV), oneByteReader{bytes.NewReader(exp)})
+		}
+		got, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("len %d: DecryptReader: %v", l, err)
+		}
+		if !bytes.Equal(got, pt) {
+			t.Fatalf("len %d: DecryptReader did not restore the plaintext", l)
+		}
+	}
+}
+
+func TestEncryptWriterECB(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	block, err := aes.NewCipher(RandBytes(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := make([]byte, 1<<16)
+	rng.Read(data)
+	for l := 0; l <= len(data); l += rng.Intn(97) + 1 {
+		pt := data[:l]
+		exp := PadPKCS7(append([]byte(nil), pt...), aes.BlockSize)
+		ECBEncrypt(block, exp, exp)
+
+		var ct bytes.Buffer
+		w := NewEncryptWriter(NewECBEncrypter(block), &ct)
+		if err := chunkedWrite(rng, w, pt); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(ct.Bytes(), exp) {
+			t.Fatalf("len %d: EncryptWriter output differs from ECBEncrypt", l)
+		}
+		got, err := io.ReadAll(NewDecryptReader(NewECBDecrypter(block), &ct))
+		if err != nil || !bytes.Equal(got, pt) {
+			t.Fatalf("len %d: DecryptReader = %v; did not restore the plaintext", l, err)
+		}
+	}
+}
+
+// failingWriter accepts the first n writes and fails the others.
+type failingWriter struct {
+	n   int
+	err error
+}
+
+func (f *failingWriter) Write(p []byte) (int, error) {
+	if f.n == 0 {
+		return 0, f.err
+	}
+	f.n--
+	return len(p), nil
+}
+
+func TestEncryptWriterError(t *testing.T) {
+	block, err := aes.NewCipher(RandBytes(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fail := errors.New("write failed")
+	for _, tc := range []struct {
+		writes  int // successful writes to the underlying writer
+		partial int // bytes written before p
+		len     int
+		want    int
+	}{
+		// the block completed by p fails
+		{0, 5, 100, 11},
+		// the second chunk of p fails
+		{2, 5, 100 + 2*streamChunk, 11 + streamChunk},
+		{0, 0, 2 * streamChunk, 0},
+		{1, 0, 2*streamChunk + 7, streamChunk},
+	} {
+		w := NewEncryptWriter(NewECBEncrypter(block), &failingWriter{n: tc.writes, err: fail})
+		if n, err := w.Write(make([]byte, tc.partial)); n != tc.partial || err != nil {
+			t.Fatalf("Write(%d bytes) = %d, %v", tc.partial, n, err)
+		}
+		if n, err := w.Write(make([]byte, tc.len)); n != tc.want || err != fail {
+			t.Errorf("%+v: Write = %d, %v; want %d, %v", tc, n, err, tc.want, fail)
+		}
+		if n, err := w.Write([]byte{1}); n != 0 || err != fail {
+			t.Errorf("%+v: Write after an error = %d, %v; want 0, %v", tc, n, err, fail)
+		}
+	}
+}
+
+func TestDecryptReaderErrors(t *testing.T) {
+	block, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct := make([]byte, 3*aes.BlockSize)
+	ECBEncrypt(block, ct, ct) // all-zero plaintext has no valid padding
+
+	_, err = io.ReadAll(NewDecryptReader(NewECBDecrypter(block), bytes.NewReader(ct)))
+	if err == nil {
+		t.Errorf("DecryptReader accepted a bad padding")
+	}
+	_, err = io.ReadAll(NewDecryptReader(NewECBDecrypter(block), bytes.NewReader(ct[:20])))
+	if err != io.ErrUnexpectedEOF {
+		t.Errorf("DecryptReader on truncated input = %v; want %v", err, io.ErrUnexpectedEOF)
+	}
+	_, err = io.ReadAll(NewDecryptReader(NewECBDecrypter(block), bytes.NewReader(nil)))
+	if err != io.ErrUnexpectedEOF {
+		t.Errorf("DecryptReader on empty input = %v; want %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+func TestCTRReader(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	block, err := aes.NewCipher(RandBytes(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := make([]byte, 1<<20)
+	rng.Read(data)
+	lengths := streamLengths(rng)
+	if testing.Short() {
+		lengths = lengths[:256]
+	}
+	for _, l := range lengths {
+		pt := data[:l]
+		nonce := uint64(l)
+		exp := make([]byte, l)
+		CTREncrypt(block, nonce, exp, pt)
+
+		var r io.Reader = NewCTRReader(block, nonce, bytes.NewReader(pt))
+		if l < 64 {
+			r = NewCTRReader(block, nonce, oneByteReader{bytes.NewReader(pt)})
+		}
+		got, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, exp) {
+			t.Fatalf("len %d: CTRReader output differs from CTREncrypt", l)
+		}
+	}
+}
@@ -2,8 +2,6 @@ package tools
 
 import (
 	"crypto/rand"
-	"fmt"
-	"log"
 )
 
 func PadPKCS7(a []byte, n int) []byte {
@@ -16,37 +14,57 @@ func PadPKCS7(a []byte, n int) []byte {
 	return a
 }
 
+// UnpadPKCS7 removes PKCS#7 padding from a. It returns ErrBadPadding if a is
+// empty or the padding is malformed.
 func UnpadPKCS7(a []byte) ([]byte, error) {
 	if len(a) == 0 {
-		panic("unpadding empty array")
+		return nil, ErrBadPadding
 	}
 	last := int(a[len(a)-1])
-	if last == 0 {
-		return nil, fmt.Errorf("bad padding")
+	if last == 0 || last > len(a) {
+		return nil, ErrBadPadding
 	}
 	for i := 1; i < last; i++ {
 		pos := len(a) - 1 - i
 		if int(a[pos]) != last {
-			return nil, fmt.Errorf("bad padding")
+			return nil, ErrBadPadding
 		}
 	}
 	return a[:len(a)-last], nil
 }
 
-func RandBytes(n int) []byte {
+// RandBytesChecked returns n bytes from crypto/rand.
+func RandBytesChecked(n int) ([]byte, error) {
 	res := make([]byte, n)
-	_, err := rand.Read(res)
+	if _, err := rand.Read(res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// RandBytes is like RandBytesChecked but panics if crypto/rand fails.
+func RandBytes(n int) []byte {
+	res, err := RandBytesChecked(n)
 	if err != nil {
-		log.Fatal(err)
+		panic(err)
 	}
 	return res
 }
 
+// RandByteChecked returns a single byte from crypto/rand.
+func RandByteChecked() (byte, error) {
+	res, err := RandBytesChecked(1)
+	if err != nil {
+		return 0, err
+	}
+	return res[0], nil
+}
+
+// RandByte is like RandByteChecked but panics if crypto/rand fails.
 func RandByte() byte {
-	res := make([]byte, 1)
-	_, err := rand.Read(res)
+	res, err := RandByteChecked()
 	if err != nil {
-		log.Fatal(err)
+		panic(err)
 	}
-	return res[0]
+	return res
 }
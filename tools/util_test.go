@@ -2,7 +2,6 @@ package tools
 
 import (
 	"bytes"
-	"fmt"
 	"testing"
 )
 
@@ -19,28 +18,38 @@ func TestUnpadPKCS7(t *testing.T) {
 		{
 			in:  []byte("ICE ICE BABY\x05\x05\x05\x05"),
 			out: nil,
-			err: fmt.Errorf("bad padding"),
+			err: ErrBadPadding,
 		},
 		{
 			in:  []byte("ICE ICE BABY\x01\x02\x03\x04"),
 			out: nil,
-			err: fmt.Errorf("bad padding"),
+			err: ErrBadPadding,
 		},
 		{
 			in:  []byte("ICE ICE BABY\x04"),
 			out: nil,
-			err: fmt.Errorf("bad padding"),
+			err: ErrBadPadding,
 		},
 		{
 			in:  []byte("ICE ICE BABY\x00"),
 			out: nil,
-			err: fmt.Errorf("bad padding"),
+			err: ErrBadPadding,
+		},
+		{
+			in:  []byte("\x20"),
+			out: nil,
+			err: ErrBadPadding,
+		},
+		{
+			in:  []byte{},
+			out: nil,
+			err: ErrBadPadding,
 		},
 	}
 
 	for _, test := range tests {
 		got, err := UnpadPKCS7(test.in)
-		if !bytes.Equal(got, test.out) || (err == nil && test.err != nil) || (err != nil && test.err == nil) {
+		if !bytes.Equal(got, test.out) || err != test.err {
 			t.Errorf("UnpadPKCS7(%#v) = %#v, %#v; want %#v, %#v", string(test.in), string(got), err, string(test.out), test.err)
 		}
 	}
@@ -0,0 +1,153 @@
+package wire
+
+import (
+	"bufio"
+	"net"
+	"sync"
+	"time"
+)
+
+// Direction tells whether a transcript entry was sent or received.
+type Direction int
+
+const (
+	Sent Direction = iota
+	Received
+)
+
+func (d Direction) String() string {
+	if d == Sent {
+		return "->"
+	}
+	return "<-"
+}
+
+// Entry is one message in a Transcript.
+type Entry struct {
+	Conn string // Name of the Conn which saw the message
+	Dir  Direction
+	Msg  Message
+}
+
+// Transcript records the messages of one or more Conns in the order they
+// were sent or received. It is safe for concurrent use, so both ends of a
+// connection, or a man in the middle, can share one.
+type Transcript struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+func (t *Transcript) add(e Entry) {
+	t.mu.Lock()
+	t.entries = append(t.entries, e)
+	t.mu.Unlock()
+}
+
+// Entries returns a copy of the recorded entries.
+func (t *Transcript) Entries() []Entry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Entry(nil), t.entries...)
+}
+
+// Types returns the types of the recorded messages, which is what tests of
+// a protocol usually compare.
+func (t *Transcript) Types() []Type {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	types := make([]Type, len(t.entries))
+	for i, e := range t.entries {
+		types[i] = e.Msg.Type()
+	}
+	return types
+}
+
+// Conn sends and receives messages over a net.Conn. One goroutine may send
+// while another receives.
+type Conn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	// Name labels the entries of this Conn in Transcript.
+	Name string
+	// Timeout bounds every Send and Recv, no limit if zero. A timed out
+	// Conn should be closed, as a frame may be half written or read.
+	Timeout time.Duration
+	// Transcript records the messages if not nil.
+	Transcript *Transcript
+}
+
+// NewConn returns a Conn over c.
+func NewConn(c net.Conn) *Conn {
+	return &Conn{conn: c, r: bufio.NewReader(c)}
+}
+
+// Dial connects to addr over TCP.
+func Dial(addr string) (*Conn, error) {
+	c, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return NewConn(c), nil
+}
+
+// Pipe returns the two ends of an in-memory connection, see net.Pipe.
+func Pipe() (*Conn, *Conn) {
+	a, b := net.Pipe()
+	return NewConn(a), NewConn(b)
+}
+
+// Send writes m as one frame.
+func (c *Conn) Send(m Message) error {
+	if c.Timeout > 0 {
+		if err := c.conn.SetWriteDeadline(time.Now().Add(c.Timeout)); err != nil {
+			return err
+		}
+	}
+	if err := WriteMessage(c.conn, m); err != nil {
+		return err
+	}
+	if c.Transcript != nil {
+		c.Transcript.add(Entry{c.Name, Sent, m})
+	}
+	return nil
+}
+
+// Recv reads the next message.
+func (c *Conn) Recv() (Message, error) {
+	if c.Timeout > 0 {
+		if err := c.conn.SetReadDeadline(time.Now().Add(c.Timeout)); err != nil {
+			return nil, err
+		}
+	}
+	m, err := ReadMessage(c.r)
+	if err != nil {
+		return nil, err
+	}
+	if c.Transcript != nil {
+		c.Transcript.add(Entry{c.Name, Received, m})
+	}
+	return m, nil
+}
+
+// Close closes the underlying connection.
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+// Receive reads the next message from c and returns it as an M, such as
+// *PublicKey. An Error from the peer is returned as the error, and any other
+// type of message gives ErrUnexpected.
+func Receive[M Message](c *Conn) (M, error) {
+	var zero M
+	m, err := c.Recv()
+	if err != nil {
+		return zero, err
+	}
+	if want, ok := m.(M); ok {
+		return want, nil
+	}
+	if e, ok := m.(*Error); ok {
+		return zero, e
+	}
+	return zero, ErrUnexpected
+}
@@ -0,0 +1,402 @@
+package wire
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/sha1"
+	"errors"
+	"math/big"
+	"net"
+	"testing"
+
+	"github.com/ysmolsky/cryptopals/tools"
+	"github.com/ysmolsky/cryptopals/tools/srp"
+)
+
+// serve runs handle on every connection accepted on a new local TCP
+// listener and returns its address. The listener is closed at the end of
+// the test.
+func serve(t *testing.T, handle func(c *Conn) error) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				c := NewConn(conn)
+				defer c.Close()
+				handle(c)
+			}()
+		}
+	}()
+	return l.Addr().String()
+}
+
+func group(t *testing.T) (p, g *big.Int) {
+	grp, err := srp.RFC5054Group(1536)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return grp.N, big.NewInt(2)
+}
+
+// encrypt is the AES-CBC(SHA1(s)[0:16], iv, msg) of challenge 34 under the
+// shared secret s.
+func encrypt(s *big.Int, msg []byte) *Ciphertext {
+	block, err := aes.NewCipher(aesKey(s))
+	if err != nil {
+		panic(err)
+	}
+	iv := tools.RandBytes(block.BlockSize())
+	pt := tools.PadPKCS7(msg, block.BlockSize())
+	ct := make([]byte, len(pt))
+	tools.CBCEncrypt(block, iv, ct, pt)
+	return &Ciphertext{IV: iv, Data: ct}
+}
+
+func aesKey(s *big.Int) []byte {
+	h := sha1.Sum(s.Bytes())
+	return h[:16]
+}
+
+func decrypt(s *big.Int, m *Ciphertext) ([]byte, error) {
+	block, err := aes.NewCipher(aesKey(s))
+	if err != nil {
+		return nil, err
+	}
+	pt := make([]byte, len(m.Data))
+	if err := tools.CBCDecryptChecked(block, m.IV, pt, m.Data); err != nil {
+		return nil, err
+	}
+	return tools.UnpadPKCS7(pt)
+}
+
+// dhClient is A of challenges 34 and 35: it negotiates the group, exchanges
+// public keys, sends msg encrypted under the shared secret and returns the
+// echo of B.
+func dhClient(c *Conn, p, g *big.Int, msg []byte) ([]byte, error) {
+	if err := c.Send(&Negotiate{P: p, G: g}); err != nil {
+		return nil, err
+	}
+	if _, err := Receive[*Ack](c); err != nil {
+		return nil, err
+	}
+	pub, priv := new(big.Int), new(big.Int)
+	tools.DHKEGenKeys(pub, priv, p, g)
+	if err := c.Send(&PublicKey{Key: pub}); err != nil {
+		return nil, err
+	}
+	peer, err := Receive[*PublicKey](c)
+	if err != nil {
+		return nil, err
+	}
+	s := new(big.Int).Exp(peer.Key, priv, p)
+	if err := c.Send(encrypt(s, msg)); err != nil {
+		return nil, err
+	}
+	echo, err := Receive[*Ciphertext](c)
+	if err != nil {
+		return nil, err
+	}
+	return decrypt(s, echo)
+}
+
+// dhServer is B of challenges 34 and 35, which echoes A's message.
+func dhServer(c *Conn) error {
+	neg, err := Receive[*Negotiate](c)
+	if err != nil {
+		return err
+	}
+	if err := c.Send(&Ack{}); err != nil {
+		return err
+	}
+	peer, err := Receive[*PublicKey](c)
+	if err != nil {
+		return err
+	}
+	pub, priv := new(big.Int), new(big.Int)
+	tools.DHKEGenKeys(pub, priv, neg.P, neg.G)
+	if err := c.Send(&PublicKey{Key: pub}); err != nil {
+		return err
+	}
+	s := new(big.Int).Exp(peer.Key, priv, neg.P)
+	ct, err := Receive[*Ciphertext](c)
+	if err != nil {
+		return err
+	}
+	msg, err := decrypt(s, ct)
+	if err != nil {
+		c.Send(&Error{Reason: "bad ciphertext"})
+		return err
+	}
+	return c.Send(encrypt(s, msg))
+}
+
+// forgery is how M tampers with the key exchange.
+type forgery struct {
+	g   func(p *big.Int) *big.Int // replaces the g sent to B if not nil
+	key func(p *big.Int) *big.Int // replaces both public keys
+	s   int64                     // the shared secret of both sides then
+}
+
+// mitm is M of challenges 34 and 35. It relays everything between A and B
+// but forges the group and the public keys with f, and returns the messages
+// it decrypted on the way.
+func mitm(a, b *Conn, f forgery) ([][]byte, error) {
+	var p *big.Int
+	var seen [][]byte
+	relay := func(from, to *Conn) error {
+		m, err := from.Recv()
+		if err != nil {
+			return err
+		}
+		switch m := m.(type) {
+		case *Negotiate:
+			p = m.P
+			if f.g != nil {
+				m.G = f.g(p)
+			}
+		case *PublicKey:
+			m.Key = f.key(p)
+		case *Ciphertext:
+			pt, err := decrypt(big.NewInt(f.s), m)
+			if err != nil {
+				return err
+			}
+			seen = append(seen, pt)
+		}
+		return to.Send(m)
+	}
+	// A: Negotiate, B: Ack, A: PublicKey, B: PublicKey, A and B: Ciphertext
+	for i, from := range []*Conn{a, b, a, b, a, b} {
+		to := b
+		if i%2 == 1 {
+			to = a
+		}
+		if err := relay(from, to); err != nil {
+			return seen, err
+		}
+	}
+	return seen, nil
+}
+
+func TestDH(t *testing.T) {
+	addr := serve(t, dhServer)
+	c, err := Dial(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	c.Transcript = new(Transcript)
+	p, g := group(t)
+	msg := []byte("This message is from A to B")
+	echo, err := dhClient(c, p, g, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(echo, msg) {
+		t.Errorf("echo %q; want %q", echo, msg)
+	}
+	want := []Type{TypeNegotiate, TypeAck, TypePublicKey, TypePublicKey, TypeCiphertext, TypeCiphertext}
+	got := c.Transcript.Types()
+	if len(got) != len(want) {
+		t.Fatalf("transcript %v; want %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("transcript %v; want %v", got, want)
+		}
+	}
+}
+
+func TestMITM(t *testing.T) {
+	tests := []struct {
+		name string
+		f    forgery
+	}{
+		// challenge 34: both sides compute p^x mod p = 0
+		{"key = p", forgery{key: func(p *big.Int) *big.Int { return p }, s: 0}},
+		// challenge 35: B's public key is g^b with the forged g, and A's is
+		// replaced to give B the same secret
+		{"g = 1", forgery{
+			g:   func(*big.Int) *big.Int { return big.NewInt(1) },
+			key: func(*big.Int) *big.Int { return big.NewInt(1) },
+			s:   1,
+		}},
+		{"g = p", forgery{
+			g:   func(p *big.Int) *big.Int { return p },
+			key: func(p *big.Int) *big.Int { return p },
+			s:   0,
+		}},
+		// (p-1)^b is 1 or p-1, so M tells A it is 1
+		{"g = p-1", forgery{
+			g:   func(p *big.Int) *big.Int { return new(big.Int).Sub(p, big.NewInt(1)) },
+			key: func(*big.Int) *big.Int { return big.NewInt(1) },
+			s:   1,
+		}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			a, ma := Pipe()
+			mb, b := Pipe()
+			for _, c := range []*Conn{a, ma, mb, b} {
+				defer c.Close()
+			}
+			tr := new(Transcript)
+			b.Name, b.Transcript = "B", tr
+			go dhServer(b)
+			type result struct {
+				seen [][]byte
+				err  error
+			}
+			done := make(chan result, 1)
+			go func() {
+				seen, err := mitm(ma, mb, tc.f)
+				done <- result{seen, err}
+			}()
+
+			p, g := group(t)
+			msg := []byte("This message is from A to B")
+			echo, err := dhClient(a, p, g, msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(echo, msg) {
+				t.Errorf("echo through M %q; want %q", echo, msg)
+			}
+			res := <-done
+			if res.err != nil {
+				t.Fatal(res.err)
+			}
+			if len(res.seen) != 2 || !bytes.Equal(res.seen[0], msg) || !bytes.Equal(res.seen[1], msg) {
+				t.Errorf("M decrypted %q; want the message twice", res.seen)
+			}
+			if tc.f.g != nil {
+				neg := tr.Entries()[0].Msg.(*Negotiate)
+				if want := tc.f.g(p); neg.G.Cmp(want) != 0 {
+					t.Errorf("B negotiated g = %v; want %v", neg.G, want)
+				}
+			}
+		})
+	}
+}
+
+// srpServer runs one SRP login: Login, SaltedKey, Proof M1, Proof M2.
+func srpServer(c *Conn, p *srp.Params, store srp.Store) error {
+	login, err := Receive[*Login](c)
+	if err != nil {
+		return err
+	}
+	s, err := srp.NewServer(p, store, login.Username, login.Key)
+	if err != nil {
+		c.Send(&Error{Reason: err.Error()})
+		return err
+	}
+	if err := c.Send(&SaltedKey{Salt: s.Salt(), Key: s.Public()}); err != nil {
+		return err
+	}
+	m1, err := Receive[*Proof](c)
+	if err != nil {
+		return err
+	}
+	m2, err := s.Verify(m1.MAC)
+	if err != nil {
+		c.Send(&Error{Reason: "INVALID"})
+		return err
+	}
+	return c.Send(&Proof{MAC: m2})
+}
+
+func srpClient(c *Conn, p *srp.Params, username, password string) error {
+	client, err := srp.NewClient(p, username, password)
+	if err != nil {
+		return err
+	}
+	if err := c.Send(&Login{Username: username, Key: client.Public()}); err != nil {
+		return err
+	}
+	sk, err := Receive[*SaltedKey](c)
+	if err != nil {
+		return err
+	}
+	m1, err := client.Proof(sk.Salt, sk.Key)
+	if err != nil {
+		return err
+	}
+	if err := c.Send(&Proof{MAC: m1}); err != nil {
+		return err
+	}
+	m2, err := Receive[*Proof](c)
+	if err != nil {
+		return err
+	}
+	return client.Verify(m2.MAC)
+}
+
+// zeroKeyClient is the attacker of challenge 37: it sends A = k N, for which
+// the server's S is 0, and proves the key H(0) without the password.
+func zeroKeyClient(c *Conn, p *srp.Params, username string, k int64) error {
+	A := new(big.Int).Mul(p.Group.N, big.NewInt(k))
+	if err := c.Send(&Login{Username: username, Key: A}); err != nil {
+		return err
+	}
+	sk, err := Receive[*SaltedKey](c)
+	if err != nil {
+		return err
+	}
+	key := p.SessionKey(new(big.Int))
+	if err := c.Send(&Proof{MAC: p.ClientProof(username, sk.Salt, A, sk.Key, key)}); err != nil {
+		return err
+	}
+	_, err = Receive[*Proof](c)
+	return err
+}
+
+func TestSRP(t *testing.T) {
+	grp, err := srp.RFC5054Group(2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, insecure := range []bool{false, true} {
+		p := &srp.Params{Group: grp, Insecure: insecure}
+		ver, err := p.NewVerifier("foo@bar.com", "easy")
+		if err != nil {
+			t.Fatal(err)
+		}
+		store := srp.MapStore{"foo@bar.com": ver}
+		addr := serve(t, func(c *Conn) error { return srpServer(c, p, store) })
+
+		login := func(f func(c *Conn) error) error {
+			c, err := Dial(addr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer c.Close()
+			return f(c)
+		}
+		if err := login(func(c *Conn) error { return srpClient(c, p, "foo@bar.com", "easy") }); err != nil {
+			t.Errorf("login with the password: %v", err)
+		}
+		var perr *Error
+		err = login(func(c *Conn) error { return srpClient(c, p, "foo@bar.com", "hard") })
+		if !errors.As(err, &perr) {
+			t.Errorf("login with a wrong password = %v; want a peer error", err)
+		}
+		for k := int64(0); k <= 2; k++ {
+			err := login(func(c *Conn) error { return zeroKeyClient(c, p, "foo@bar.com", k) })
+			if insecure && err != nil {
+				t.Errorf("zero key login with A = %d N on an insecure server: %v", k, err)
+			}
+			if !insecure && !errors.As(err, &perr) {
+				t.Errorf("zero key login with A = %d N = %v; want a peer error", k, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,266 @@
+// Package wire is a small typed message protocol for the network challenges
+// 34 to 38: Diffie-Hellman with and without a man in the middle, and SRP.
+// Messages travel in length-prefixed frames over any io.ReadWriter, and
+// Conn adds timeouts and a transcript on top of a net.Conn, so both sides of
+// a protocol can run as real client/server pairs over TCP or net.Pipe.
+//
+// A frame is a type byte, the payload length as a 4-byte big-endian integer
+// and the payload. The payload is the fields of the message in order, each
+// one a 4-byte big-endian length and its bytes. Integers are big-endian and
+// non-negative.
+package wire
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+var (
+	// ErrFrameSize is returned for a frame longer than MaxFrameSize.
+	ErrFrameSize = errors.New("wire: frame too large")
+	// ErrUnknownType is returned for a frame of an unknown message type.
+	ErrUnknownType = errors.New("wire: unknown message type")
+	// ErrMalformed is returned for a payload which does not decode to its
+	// message, or a message which cannot be encoded.
+	ErrMalformed = errors.New("wire: malformed message")
+	// ErrUnexpected is returned by Receive for a message of another type.
+	ErrUnexpected = errors.New("wire: unexpected message type")
+)
+
+// MaxFrameSize bounds the payload of a frame, so that a peer cannot make
+// the reader allocate arbitrary amounts of memory.
+const MaxFrameSize = 1 << 20
+
+// Type identifies the kind of message in a frame.
+type Type uint8
+
+const (
+	TypeNegotiate Type = iota + 1
+	TypeAck
+	TypePublicKey
+	TypeLogin
+	TypeSaltedKey
+	TypeCiphertext
+	TypeProof
+	TypeError
+)
+
+var typeNames = map[Type]string{
+	TypeNegotiate:  "Negotiate",
+	TypeAck:        "Ack",
+	TypePublicKey:  "PublicKey",
+	TypeLogin:      "Login",
+	TypeSaltedKey:  "SaltedKey",
+	TypeCiphertext: "Ciphertext",
+	TypeProof:      "Proof",
+	TypeError:      "Error",
+}
+
+func (t Type) String() string {
+	if s, ok := typeNames[t]; ok {
+		return s
+	}
+	return fmt.Sprintf("Type(%d)", uint8(t))
+}
+
+// Message is one of the messages of this package.
+type Message interface {
+	Type() Type
+	encode(e *encoder)
+	decode(d *decoder)
+}
+
+// Negotiate proposes the Diffie-Hellman group (P, G), as in challenges 34
+// and 35.
+type Negotiate struct {
+	P, G *big.Int
+}
+
+// Ack acknowledges the group of a Negotiate in challenge 35.
+type Ack struct{}
+
+// PublicKey is a Diffie-Hellman public key.
+type PublicKey struct {
+	Key *big.Int
+}
+
+// Login starts an SRP login with the username I and the client's A.
+type Login struct {
+	Username string
+	Key      *big.Int
+}
+
+// SaltedKey is the SRP server's answer to a Login: the salt and B.
+type SaltedKey struct {
+	Salt []byte
+	Key  *big.Int
+}
+
+// Ciphertext is an encrypted message with its IV, like the
+// AES-CBC(SHA1(s)[0:16], iv, msg) + iv of challenge 34.
+type Ciphertext struct {
+	IV, Data []byte
+}
+
+// Proof is a MAC or an SRP proof M1 or M2.
+type Proof struct {
+	MAC []byte
+}
+
+// Error tells the peer that a step of the protocol failed. Receive returns
+// it as an error.
+type Error struct {
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return "wire: peer error: " + e.Reason
+}
+
+func (*Negotiate) Type() Type  { return TypeNegotiate }
+func (*Ack) Type() Type        { return TypeAck }
+func (*PublicKey) Type() Type  { return TypePublicKey }
+func (*Login) Type() Type      { return TypeLogin }
+func (*SaltedKey) Type() Type  { return TypeSaltedKey }
+func (*Ciphertext) Type() Type { return TypeCiphertext }
+func (*Proof) Type() Type      { return TypeProof }
+func (*Error) Type() Type      { return TypeError }
+
+func (m *Negotiate) encode(e *encoder)  { e.int(m.P); e.int(m.G) }
+func (m *Ack) encode(e *encoder)        {}
+func (m *PublicKey) encode(e *encoder)  { e.int(m.Key) }
+func (m *Login) encode(e *encoder)      { e.bytes([]byte(m.Username)); e.int(m.Key) }
+func (m *SaltedKey) encode(e *encoder)  { e.bytes(m.Salt); e.int(m.Key) }
+func (m *Ciphertext) encode(e *encoder) { e.bytes(m.IV); e.bytes(m.Data) }
+func (m *Proof) encode(e *encoder)      { e.bytes(m.MAC) }
+func (m *Error) encode(e *encoder)      { e.bytes([]byte(m.Reason)) }
+
+func (m *Negotiate) decode(d *decoder)  { m.P, m.G = d.int(), d.int() }
+func (m *Ack) decode(d *decoder)        {}
+func (m *PublicKey) decode(d *decoder)  { m.Key = d.int() }
+func (m *Login) decode(d *decoder)      { m.Username, m.Key = string(d.bytes()), d.int() }
+func (m *SaltedKey) decode(d *decoder)  { m.Salt, m.Key = d.bytes(), d.int() }
+func (m *Ciphertext) decode(d *decoder) { m.IV, m.Data = d.bytes(), d.bytes() }
+func (m *Proof) decode(d *decoder)      { m.MAC = d.bytes() }
+func (m *Error) decode(d *decoder)      { m.Reason = string(d.bytes()) }
+
+// newMessage returns an empty message of type t.
+func newMessage(t Type) (Message, error) {
+	switch t {
+	case TypeNegotiate:
+		return new(Negotiate), nil
+	case TypeAck:
+		return new(Ack), nil
+	case TypePublicKey:
+		return new(PublicKey), nil
+	case TypeLogin:
+		return new(Login), nil
+	case TypeSaltedKey:
+		return new(SaltedKey), nil
+	case TypeCiphertext:
+		return new(Ciphertext), nil
+	case TypeProof:
+		return new(Proof), nil
+	case TypeError:
+		return new(Error), nil
+	}
+	return nil, ErrUnknownType
+}
+
+type encoder struct {
+	buf []byte
+	err error
+}
+
+func (e *encoder) bytes(b []byte) {
+	var n [4]byte
+	binary.BigEndian.PutUint32(n[:], uint32(len(b)))
+	e.buf = append(append(e.buf, n[:]...), b...)
+}
+
+func (e *encoder) int(x *big.Int) {
+	if x == nil || x.Sign() < 0 {
+		e.err = ErrMalformed
+		return
+	}
+	e.bytes(x.Bytes())
+}
+
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) bytes() []byte {
+	if d.err != nil {
+		return nil
+	}
+	if len(d.buf) < 4 {
+		d.err = ErrMalformed
+		return nil
+	}
+	n := binary.BigEndian.Uint32(d.buf)
+	if uint64(n) > uint64(len(d.buf)-4) {
+		d.err = ErrMalformed
+		return nil
+	}
+	b := append([]byte{}, d.buf[4:4+n]...)
+	d.buf = d.buf[4+n:]
+	return b
+}
+
+func (d *decoder) int() *big.Int {
+	b := d.bytes()
+	if d.err != nil {
+		return nil
+	}
+	return new(big.Int).SetBytes(b)
+}
+
+// WriteMessage writes m to w as one frame with a single Write.
+func WriteMessage(w io.Writer, m Message) error {
+	e := &encoder{buf: make([]byte, 5)}
+	m.encode(e)
+	if e.err != nil {
+		return e.err
+	}
+	if len(e.buf)-5 > MaxFrameSize {
+		return ErrFrameSize
+	}
+	e.buf[0] = byte(m.Type())
+	binary.BigEndian.PutUint32(e.buf[1:5], uint32(len(e.buf)-5))
+	_, err := w.Write(e.buf)
+	return err
+}
+
+// ReadMessage reads one frame from r and decodes its message.
+func ReadMessage(r io.Reader) (Message, error) {
+	var hdr [5]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(hdr[1:])
+	if n > MaxFrameSize {
+		return nil, ErrFrameSize
+	}
+	m, err := newMessage(Type(hdr[0]))
+	if err != nil {
+		return nil, err
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	d := &decoder{buf: payload}
+	m.decode(d)
+	if d.err != nil || len(d.buf) != 0 {
+		return nil, ErrMalformed
+	}
+	return m, nil
+}
@@ -0,0 +1,140 @@
+package wire
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestRoundTrip(t *testing.T) {
+	msgs := []Message{
+		&Negotiate{P: big.NewInt(37), G: big.NewInt(5)},
+		&Ack{},
+		&PublicKey{Key: new(big.Int).Lsh(big.NewInt(1), 1000)},
+		&PublicKey{Key: new(big.Int)},
+		&Login{Username: "foo@bar.com", Key: big.NewInt(12345)},
+		&SaltedKey{Salt: []byte{1, 2, 3}, Key: big.NewInt(678)},
+		&Ciphertext{IV: bytes.Repeat([]byte{7}, 16), Data: []byte("yellow submarine")},
+		&Proof{MAC: []byte{0xde, 0xad}},
+		&Proof{},
+		&Error{Reason: "INVALID"},
+	}
+	var buf bytes.Buffer
+	for _, m := range msgs {
+		if err := WriteMessage(&buf, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, want := range msgs {
+		got, err := ReadMessage(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Type() != want.Type() || fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("read %v %v; want %v %v", got.Type(), got, want.Type(), want)
+		}
+	}
+	if _, err := ReadMessage(&buf); err != io.EOF {
+		t.Errorf("ReadMessage at the end = %v; want %v", err, io.EOF)
+	}
+}
+
+func TestMalformed(t *testing.T) {
+	tests := []struct {
+		name  string
+		frame []byte
+		err   error
+	}{
+		{"unknown type", []byte{0xff, 0, 0, 0, 0}, ErrUnknownType},
+		{"huge frame", []byte{byte(TypeProof), 0xff, 0xff, 0xff, 0xff}, ErrFrameSize},
+		{"short payload", []byte{byte(TypeProof), 0, 0, 0, 8, 0, 0}, io.ErrUnexpectedEOF},
+		{"short header", []byte{byte(TypeProof), 0}, io.ErrUnexpectedEOF},
+		{"missing field", []byte{byte(TypeProof), 0, 0, 0, 0}, ErrMalformed},
+		{"long field", []byte{byte(TypeProof), 0, 0, 0, 5, 0, 0, 0, 2, 1}, ErrMalformed},
+		{"trailing bytes", []byte{byte(TypeAck), 0, 0, 0, 1, 0}, ErrMalformed},
+	}
+	for _, tc := range tests {
+		if _, err := ReadMessage(bytes.NewReader(tc.frame)); err != tc.err {
+			t.Errorf("%s: ReadMessage = %v; want %v", tc.name, err, tc.err)
+		}
+	}
+	var buf bytes.Buffer
+	if err := WriteMessage(&buf, &PublicKey{Key: big.NewInt(-1)}); err != ErrMalformed {
+		t.Errorf("WriteMessage of a negative key = %v; want %v", err, ErrMalformed)
+	}
+	if err := WriteMessage(&buf, &PublicKey{}); err != ErrMalformed {
+		t.Errorf("WriteMessage of a nil key = %v; want %v", err, ErrMalformed)
+	}
+	if err := WriteMessage(&buf, &Proof{MAC: make([]byte, MaxFrameSize)}); err != ErrFrameSize {
+		t.Errorf("WriteMessage of a huge proof = %v; want %v", err, ErrFrameSize)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("failed writes left %d bytes", buf.Len())
+	}
+}
+
+func TestConn(t *testing.T) {
+	a, b := Pipe()
+	defer a.Close()
+	defer b.Close()
+	tr := new(Transcript)
+	a.Name, a.Transcript = "A", tr
+	b.Name, b.Transcript = "B", tr
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		b.Send(&PublicKey{Key: big.NewInt(2)})
+		b.Send(&Error{Reason: "no"})
+		b.Send(&Ack{})
+	}()
+	pub, err := Receive[*PublicKey](a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pub.Key.Int64() != 2 {
+		t.Errorf("received key %v; want 2", pub.Key)
+	}
+	var perr *Error
+	if _, err := Receive[*Proof](a); !errors.As(err, &perr) || perr.Reason != "no" {
+		t.Errorf("Receive of an Error = %v; want the peer error", err)
+	}
+	if _, err := Receive[*Proof](a); err != ErrUnexpected {
+		t.Errorf("Receive of an Ack = %v; want %v", err, ErrUnexpected)
+	}
+
+	<-done
+	entries := tr.Entries()
+	if len(entries) != 6 {
+		t.Fatalf("transcript has %d entries; want 6", len(entries))
+	}
+	// each message is recorded by B when sent and by A when received, in
+	// either order
+	for i := 0; i < len(entries); i += 2 {
+		x, y := entries[i], entries[i+1]
+		if x.Dir == Received {
+			x, y = y, x
+		}
+		if x.Conn != "B" || x.Dir != Sent || y.Conn != "A" || y.Dir != Received || x.Msg.Type() != y.Msg.Type() {
+			t.Errorf("transcript entries %d and %d: %v %v %v, %v %v %v", i, i+1, x.Conn, x.Dir, x.Msg.Type(), y.Conn, y.Dir, y.Msg.Type())
+		}
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	a, b := Pipe()
+	defer a.Close()
+	defer b.Close()
+	a.Timeout = 20 * time.Millisecond
+	if _, err := a.Recv(); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("Recv from a silent peer = %v; want a timeout", err)
+	}
+	if err := a.Send(&Ack{}); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("Send to a peer which does not read = %v; want a timeout", err)
+	}
+}